@@ -0,0 +1,61 @@
+package credentials
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 日志与输出脱敏 -----------------------------------------------
+
+const redacted = "******"
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// i18n.T、i18n.Printf 输出的消息（包括 fatal 和 slog 的消息文本）同样脱敏
+func init() {
+	i18n.SetRedactor(Redact)
+}
+
+// RegisterSecret 登记需要脱敏的值，过短的值（少于 4 个字符）容易误伤普通文本，不做登记
+func RegisterSecret(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	for _, value := range values {
+		if len(value) < 4 {
+			continue
+		}
+		exists := false
+		for _, s := range secrets {
+			if s == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			secrets = append(secrets, value)
+		}
+	}
+
+	// 先替换较长的值，避免一个密钥是另一个的子串时只替换一半
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// Redact 把文本中已登记的密钥替换为 ******
+func Redact(text string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for _, s := range secrets {
+		if strings.Contains(text, s) {
+			text = strings.ReplaceAll(text, s, redacted)
+		}
+	}
+	return text
+}
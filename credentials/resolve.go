@@ -0,0 +1,170 @@
+package credentials
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"program-collection/i18n"
+	"program-collection/models"
+
+	"golang.org/x/term"
+)
+
+// ------------------------------------------------ 配置中的凭据解析 -----------------------------------------------
+// 优先级从高到低: 环境变量 > 加密凭据库 > password_file / dsn_file > config.yaml
+
+// PassphraseEnv 凭据库口令的环境变量，未设置时从终端读取
+const PassphraseEnv = "WQB_CREDENTIALS_PASSPHRASE"
+
+// Overrides 已被环境变量覆盖的字段（按凭据库键记录）
+type Overrides map[string]bool
+
+// ApplyEnv 用 WQB_<路径> 环境变量覆盖配置中的每个字段，如 WQB_LOGIN_PASSWORD、WQB_DATABASE_DSN、
+// WQB_DATABASE_MAX_OPEN_CONNS、WQB_PROFILES_ALICE_LOGIN_PASSWORD（profiles 只覆盖 config.yaml 中已有的账户）；
+// 列表按逗号分隔，如 WQB_FIELD_CHECK_TYPES=REGULAR,SUPER
+func ApplyEnv(config *models.Config) (Overrides, error) {
+	overrides := Overrides{}
+	err := walkFields(reflect.ValueOf(config).Elem(), nil, func(f field) error {
+		raw, ok := os.LookupEnv(f.EnvName())
+		if !ok {
+			return nil
+		}
+		overrides[f.Key()] = true
+		return f.set(raw)
+	})
+	return overrides, err
+}
+
+// Resolve 读取 password_file / dsn_file 和加密凭据库，并登记所有密钥用于脱敏。
+// passphrase 只在配置了 credentials.store 且文件存在时才会被调用
+func Resolve(config *models.Config, overrides Overrides, passphrase func() (string, error)) error {
+	if err := applySecretFiles(config, overrides); err != nil {
+		return err
+	}
+
+	if path := config.Credentials.Store; path != "" {
+		if _, err := os.Stat(path); err == nil {
+			pass, err := passphrase()
+			if err != nil {
+				return err
+			}
+			store, err := OpenStore(path, pass)
+			if err != nil {
+				return err
+			}
+			if err := applyStore(config, store, overrides); err != nil {
+				return err
+			}
+		}
+	}
+
+	RegisterConfigSecrets(*config)
+	return nil
+}
+
+// 读取 login.password_file 与 database.dsn_file（全局和每个 profile）
+func applySecretFiles(config *models.Config, overrides Overrides) error {
+	if err := readSecretFile(&config.Login.Password, config.Login.PasswordFile, overrides["login.password"]); err != nil {
+		return err
+	}
+	if err := readSecretFile(&config.Database.DSN, config.Database.DSNFile, overrides["database.dsn"]); err != nil {
+		return err
+	}
+
+	for name, profile := range config.Profiles {
+		prefix := "profiles." + name + "."
+		if err := readSecretFile(&profile.Login.Password, profile.Login.PasswordFile, overrides[prefix+"login.password"]); err != nil {
+			return err
+		}
+		if err := readSecretFile(&profile.Database.DSN, profile.Database.DSNFile, overrides[prefix+"database.dsn"]); err != nil {
+			return err
+		}
+		config.Profiles[name] = profile
+	}
+	return nil
+}
+
+func readSecretFile(target *string, path string, overridden bool) error {
+	if path == "" || overridden {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return i18n.Errorf("credentials.secret_file_failed", path, err)
+	}
+	*target = strings.TrimRight(string(data), "\r\n")
+	return nil
+}
+
+// 用凭据库中的值填充配置，已被环境变量覆盖的字段保持不变
+func applyStore(config *models.Config, store *Store, overrides Overrides) error {
+	return walkFields(reflect.ValueOf(config).Elem(), nil, func(f field) error {
+		value, ok := store.Get(f.Key())
+		if !ok || overrides[f.Key()] {
+			return nil
+		}
+		return f.set(value)
+	})
+}
+
+// RegisterConfigSecrets 登记配置中所有标记为 secret 的字段；DSN 中的密码也单独登记
+func RegisterConfigSecrets(config models.Config) {
+	walkFields(reflect.ValueOf(&config).Elem(), nil, func(f field) error {
+		if f.secret && f.value.Kind() == reflect.String {
+			RegisterSecret(f.value.String())
+		}
+		return nil
+	})
+
+	RegisterSecret(config.Database.DSNPassword())
+	for _, profile := range config.Profiles {
+		database := profile.Database
+		if database.Driver == "" {
+			database.Driver = config.Database.Driver
+		}
+		RegisterSecret(database.DSNPassword())
+	}
+}
+
+// ValidKey 判断键是否对应配置中的某个字段
+func ValidKey(config models.Config, key string) bool {
+	found := false
+	walkFields(reflect.ValueOf(&config).Elem(), nil, func(f field) error {
+		if f.Key() == key {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// ReadPassphrase 从环境变量或终端读取凭据库口令
+func ReadPassphrase() (string, error) {
+	if pass := os.Getenv(PassphraseEnv); pass != "" {
+		return pass, nil
+	}
+	return ReadSecret(i18n.T("credentials.passphrase_prompt"))
+}
+
+// ReadSecret 从终端读取一行输入且不回显；标准输入不是终端时按普通行读取
+func ReadSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", i18n.Errorf("credentials.read_secret_failed", err)
+		}
+		return string(data), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", i18n.Errorf("credentials.read_secret_failed", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
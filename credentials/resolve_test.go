@@ -0,0 +1,154 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("WQB_LOGIN_PASSWORD", "env-password")
+	t.Setenv("WQB_DATABASE_MAX_OPEN_CONNS", "12")
+	t.Setenv("WQB_FIELD_CHECK_TYPES", "REGULAR, SUPER,")
+	t.Setenv("WQB_FIELD_CHECK_DELAY", "0")
+	t.Setenv("WQB_PROFILES_ALICE_LOGIN_PASSWORD", "alice-password")
+	t.Setenv("WQB_PROFILES_BOB_LOGIN_PASSWORD", "bob-password")
+
+	config := models.Config{
+		Login:      models.Login{Username: "me", Password: "yaml-password"},
+		FieldCheck: models.FieldCheck{Regions: []string{"USA"}},
+		Profiles:   map[string]models.Profile{"alice": {Login: models.Login{Username: "alice"}}},
+	}
+	overrides, err := ApplyEnv(&config)
+	if err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+
+	if config.Login.Password != "env-password" || config.Login.Username != "me" || config.Database.MaxOpenConns != 12 {
+		t.Errorf("login = %+v, maxOpenConns = %d", config.Login, config.Database.MaxOpenConns)
+	}
+	// 切片按逗号分隔，指针字段设置为新值；没有对应环境变量的字段保持不变
+	if !slices.Equal(config.FieldCheck.Types, []string{"REGULAR", "SUPER"}) || !slices.Equal(config.FieldCheck.Regions, []string{"USA"}) {
+		t.Errorf("fieldCheck types = %q, regions = %q", config.FieldCheck.Types, config.FieldCheck.Regions)
+	}
+	if config.FieldCheck.Delay == nil || *config.FieldCheck.Delay != 0 {
+		t.Errorf("fieldCheck.delay = %v, want 0", config.FieldCheck.Delay)
+	}
+	// profiles 只覆盖已有的账户
+	if config.Profiles["alice"].Login.Password != "alice-password" || len(config.Profiles) != 1 {
+		t.Errorf("profiles = %+v", config.Profiles)
+	}
+	for _, key := range []string{"login.password", "database.maxOpenConns", "fieldCheck.types", "fieldCheck.delay", "profiles.alice.login.password"} {
+		if !overrides[key] {
+			t.Errorf("overrides[%q] not set", key)
+		}
+	}
+
+	t.Setenv("WQB_FIELD_CHECK_DELAY", "one")
+	if _, err := ApplyEnv(&config); err == nil {
+		t.Error("ApplyEnv accepted a non-integer delay")
+	}
+}
+
+func TestResolvePasswordFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	if err := os.WriteFile(path, []byte("file-password\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	noPassphrase := func() (string, error) {
+		t.Fatal("passphrase requested without a credential store")
+		return "", nil
+	}
+
+	// 1. 去掉结尾换行后写入 password，并登记为需要脱敏的密钥
+	config := models.Config{Login: models.Login{Username: "me", PasswordFile: path}}
+	if err := Resolve(&config, Overrides{}, noPassphrase); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if config.Login.Password != "file-password" {
+		t.Errorf("password = %q, want file-password", config.Login.Password)
+	}
+	if got := Redact("login failed for file-password"); got != "login failed for ******" {
+		t.Errorf("Redact = %q", got)
+	}
+	if got := i18n.T("credentials.read_failed", "file-password"); strings.Contains(got, "file-password") {
+		t.Errorf("i18n.T = %q, want the secret redacted", got)
+	}
+
+	// 2. 环境变量优先于 password_file
+	config = models.Config{Login: models.Login{Password: "env-password", PasswordFile: path}}
+	if err := Resolve(&config, Overrides{"login.password": true}, noPassphrase); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if config.Login.Password != "env-password" {
+		t.Errorf("overridden password = %q, want env-password", config.Login.Password)
+	}
+
+	// 3. 文件不存在时报错
+	config = models.Config{Login: models.Login{PasswordFile: filepath.Join(dir, "missing")}}
+	if err := Resolve(&config, Overrides{}, noPassphrase); err == nil {
+		t.Error("Resolve accepted a missing password_file")
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	store, err := OpenStore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if store.Exists() {
+		t.Error("new store exists before Save")
+	}
+	store.Set("login.password", "store-password")
+	store.Set("database.dsn", "store-dsn")
+	store.Set("fieldCheck.regions", "USA,CHN")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("store file = %v, %v, want mode 0600", info, err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "store-password") {
+		t.Error("store file contains the plaintext password")
+	}
+
+	// 1. 用同一口令重新打开
+	reopened, err := OpenStore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if value, ok := reopened.Get("login.password"); !ok || value != "store-password" {
+		t.Errorf("Get(login.password) = %q, %v", value, ok)
+	}
+	if keys := reopened.Keys(); !slices.Equal(keys, []string{"database.dsn", "fieldCheck.regions", "login.password"}) {
+		t.Errorf("Keys = %q", keys)
+	}
+	if _, err := OpenStore(path, "wrong"); err == nil {
+		t.Error("OpenStore accepted a wrong passphrase")
+	}
+
+	// 2. Resolve 用凭据库填充配置，已被环境变量覆盖的字段不变
+	config := models.Config{
+		Database:    models.Database{DSN: "env-dsn"},
+		Credentials: models.Credentials{Store: path},
+	}
+	err = Resolve(&config, Overrides{"database.dsn": true}, func() (string, error) { return "correct horse", nil })
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if config.Login.Password != "store-password" || config.Database.DSN != "env-dsn" {
+		t.Errorf("password = %q, dsn = %q", config.Login.Password, config.Database.DSN)
+	}
+	if !slices.Equal(config.FieldCheck.Regions, []string{"USA", "CHN"}) {
+		t.Errorf("fieldCheck.regions = %q", config.FieldCheck.Regions)
+	}
+}
@@ -0,0 +1,171 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 加密凭据库 -----------------------------------------------
+
+const (
+	storeVersion    = 1
+	storeIterations = 600000
+	storeKeyLen     = 32
+)
+
+// 凭据库文件格式：密钥由口令经 PBKDF2-SHA256 派生，内容用 AES-256-GCM 加密
+type storeFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Store 本地加密凭据库，键为配置路径，如 login.password、profiles.alice.login.password
+type Store struct {
+	path       string
+	passphrase string
+	secrets    map[string]string
+}
+
+// OpenStore 用口令打开凭据库；文件不存在时返回空库，Save 时创建
+func OpenStore(path, passphrase string) (*Store, error) {
+	if passphrase == "" {
+		return nil, i18n.Errorf("credentials.empty_passphrase")
+	}
+	store := &Store{path: path, passphrase: passphrase, secrets: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, i18n.Errorf("credentials.read_failed", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, i18n.Errorf("credentials.corrupt", path, err)
+	}
+	if file.Version != storeVersion {
+		return nil, i18n.Errorf("credentials.bad_version", file.Version)
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, i18n.Errorf("credentials.wrong_passphrase")
+	}
+	if err := json.Unmarshal(plaintext, &store.secrets); err != nil {
+		return nil, i18n.Errorf("credentials.corrupt", path, err)
+	}
+	return store, nil
+}
+
+// Exists 凭据库文件是否存在
+func (s *Store) Exists() bool {
+	_, err := os.Stat(s.path)
+	return err == nil
+}
+
+// Get 读取一个凭据
+func (s *Store) Get(key string) (string, bool) {
+	value, ok := s.secrets[key]
+	return value, ok
+}
+
+// Set 写入一个凭据，需调用 Save 才会落盘
+func (s *Store) Set(key, value string) {
+	s.secrets[key] = value
+}
+
+// Delete 删除一个凭据，返回该键是否存在
+func (s *Store) Delete(key string) bool {
+	_, ok := s.secrets[key]
+	delete(s.secrets, key)
+	return ok
+}
+
+// Keys 返回排序后的全部键
+func (s *Store) Keys() []string {
+	keys := make([]string, 0, len(s.secrets))
+	for key := range s.secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save 重新生成盐和随机数后加密写入文件（权限 0600）
+func (s *Store) Save() error {
+	plaintext, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(s.passphrase, salt, storeIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(storeFile{
+		Version:    storeVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: storeIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return i18n.Errorf("credentials.write_failed", err)
+	}
+	// 先写临时文件再改名，避免写到一半损坏原有凭据库
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return i18n.Errorf("credentials.write_failed", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return i18n.Errorf("credentials.write_failed", err)
+	}
+	return nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, storeKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,160 @@
+package credentials
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 配置字段遍历 -----------------------------------------------
+
+// field 配置中的一个叶子字段，path 由 yaml 标签组成，如 [profiles alice login password]
+type field struct {
+	path   []string
+	value  reflect.Value // 可写
+	secret bool
+}
+
+// Key 凭据库中使用的键，如 profiles.alice.login.password
+func (f field) Key() string {
+	return strings.Join(f.path, ".")
+}
+
+// EnvName 对应的环境变量名，如 WQB_PROFILES_ALICE_LOGIN_PASSWORD
+func (f field) EnvName() string {
+	parts := make([]string, len(f.path))
+	for i, p := range f.path {
+		parts[i] = upperSnake(p)
+	}
+	return "WQB_" + strings.Join(parts, "_")
+}
+
+// 按字符串设置字段值，支持 string、int、bool、逗号分隔的 []string，以及指向前三者的指针（空字符串设为 nil）
+func (f field) set(raw string) error {
+	switch f.value.Kind() {
+	case reflect.Pointer:
+		if strings.TrimSpace(raw) == "" {
+			f.value.SetZero()
+			return nil
+		}
+		elem := reflect.New(f.value.Type().Elem())
+		if err := (field{path: f.path, value: elem.Elem()}).set(raw); err != nil {
+			return err
+		}
+		f.value.Set(elem)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.value.Set(reflect.ValueOf(items))
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return i18n.Errorf("credentials.bad_int", f.Key(), raw)
+		}
+		f.value.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return i18n.Errorf("credentials.bad_bool", f.Key(), raw)
+		}
+		f.value.SetBool(b)
+	}
+	return nil
+}
+
+// walkFields 遍历结构体中所有叶子字段（见 leafField）；map 中的值会先复制出来再写回，因此 fn 中的修改同样生效
+func walkFields(v reflect.Value, path []string, fn func(field) error) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+			if name == "-" || !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(sf.Name)
+			}
+
+			fv := v.Field(i)
+			childPath := append(append([]string{}, path...), name)
+			switch fv.Kind() {
+			case reflect.Struct, reflect.Map:
+				if err := walkFields(fv, childPath, fn); err != nil {
+					return err
+				}
+			default:
+				if !leafField(fv.Type()) {
+					continue
+				}
+				if err := fn(field{path: childPath, value: fv, secret: sf.Tag.Get("secret") == "true"}); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+
+			childPath := append(append([]string{}, path...), key.String())
+			var err error
+			if elem.Kind() == reflect.Struct {
+				err = walkFields(elem, childPath, fn)
+			} else {
+				err = fn(field{path: childPath, value: elem})
+			}
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	}
+	return nil
+}
+
+// 可以按字符串设置的字段类型：string、int、bool、[]string 和指向 string、int、bool 的指针（如 fieldCheck.delay）；
+// 其他类型（如结构体切片）不能通过环境变量或凭据库设置
+func leafField(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int64, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Pointer:
+		return t.Elem().Kind() != reflect.Pointer && t.Elem().Kind() != reflect.Slice && leafField(t.Elem())
+	}
+	return false
+}
+
+// alphaList -> ALPHA_LIST, password_file -> PASSWORD_FILE
+func upperSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '.':
+			b.WriteRune('_')
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package fastexpr
+
+// ------------------------------------------------ 语法树 -----------------------------------------------
+
+// Node 语法树节点
+type Node interface {
+	// Pos 节点在源码中的起始位置
+	Pos() Pos
+}
+
+// Expr 表达式节点
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Ident 标识符：字段、局部变量或 true、nan 等常量
+type Ident struct {
+	NamePos Pos
+	Name    string
+}
+
+// Number 数字字面量，Value 为源码原文
+type Number struct {
+	ValuePos Pos
+	Value    string
+}
+
+// String 字符串字面量，Value 为去掉引号后的内容，Raw 为源码原文
+type String struct {
+	ValuePos Pos
+	Value    string
+	Raw      string
+}
+
+// Call 操作符调用，Args 中可以包含 NamedArg
+type Call struct {
+	Fun    *Ident
+	Lparen Pos
+	Args   []Expr
+	Rparen Pos
+}
+
+// NamedArg 命名参数，如 filter=true、rettype=0
+type NamedArg struct {
+	Name  *Ident
+	Value Expr
+}
+
+// UnaryOp 一元运算：-x、+x、!x
+type UnaryOp struct {
+	OpPos Pos
+	Op    Kind
+	X     Expr
+}
+
+// BinaryOp 二元运算，Op 为 ADD、LSS、AND 等
+type BinaryOp struct {
+	X     Expr
+	OpPos Pos
+	Op    Kind
+	Y     Expr
+}
+
+// Ternary 条件表达式 cond ? then : else
+type Ternary struct {
+	Cond     Expr
+	Question Pos
+	Then     Expr
+	Colon    Pos
+	Else     Expr
+}
+
+// Assign 赋值语句 name = value
+type Assign struct {
+	Name  *Ident
+	Value Expr
+}
+
+// Comment 注释，Text 包含 //、# 或 /* */
+type Comment struct {
+	TextPos Pos
+	Text    string
+}
+
+// Program 一段完整的表达式：以分号分隔的语句，最后一条语句的值为 alpha 的值。
+// Stmts 中的元素为 *Assign 或 Expr
+type Program struct {
+	Stmts    []Node
+	Comments []*Comment
+}
+
+func (n *Ident) Pos() Pos    { return n.NamePos }
+func (n *Number) Pos() Pos   { return n.ValuePos }
+func (n *String) Pos() Pos   { return n.ValuePos }
+func (n *Call) Pos() Pos     { return n.Fun.NamePos }
+func (n *NamedArg) Pos() Pos { return n.Name.NamePos }
+func (n *UnaryOp) Pos() Pos  { return n.OpPos }
+func (n *BinaryOp) Pos() Pos { return n.X.Pos() }
+func (n *Ternary) Pos() Pos  { return n.Cond.Pos() }
+func (n *Assign) Pos() Pos   { return n.Name.NamePos }
+func (n *Comment) Pos() Pos  { return n.TextPos }
+
+func (n *Program) Pos() Pos {
+	if len(n.Stmts) == 0 {
+		return Pos{Line: 1, Col: 1}
+	}
+	return n.Stmts[0].Pos()
+}
+
+func (*Ident) exprNode()    {}
+func (*Number) exprNode()   {}
+func (*String) exprNode()   {}
+func (*Call) exprNode()     {}
+func (*NamedArg) exprNode() {}
+func (*UnaryOp) exprNode()  {}
+func (*BinaryOp) exprNode() {}
+func (*Ternary) exprNode()  {}
+
+// Inspect 深度优先遍历语法树，fn 返回 false 时不再进入该节点的子节点
+func Inspect(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, stmt := range n.Stmts {
+			Inspect(stmt, fn)
+		}
+	case *Assign:
+		Inspect(n.Name, fn)
+		Inspect(n.Value, fn)
+	case *Call:
+		Inspect(n.Fun, fn)
+		for _, arg := range n.Args {
+			Inspect(arg, fn)
+		}
+	case *NamedArg:
+		Inspect(n.Name, fn)
+		Inspect(n.Value, fn)
+	case *UnaryOp:
+		Inspect(n.X, fn)
+	case *BinaryOp:
+		Inspect(n.X, fn)
+		Inspect(n.Y, fn)
+	case *Ternary:
+		Inspect(n.Cond, fn)
+		Inspect(n.Then, fn)
+		Inspect(n.Else, fn)
+	}
+}
@@ -0,0 +1,197 @@
+package fastexpr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------ 规范形式 -----------------------------------------------
+//
+// 规范形式用于判断两个表达式是否等价，不用于阅读：
+//   - 忽略空白和注释
+//   - 局部变量按赋值顺序改名为 $1、$2…，每次赋值都是新名字，因此 a=…; a=a+1 与 a=…; b=a+1 相同
+//   - 运算符改写为对应的操作符调用：a + b 即 add(a, b)，c ? x : y 即 if_else(c, x, y)，
+//     a > b 即 less(b, a)，a >= b 即 less_equal(b, a)
+//   - add、multiply、max、min、and、or 展开嵌套后按参数排序，equal、not_equal 的两个参数排序
+//   - 命名参数按名称排序，数字按数值写出（1.0、1、1e0 相同）
+
+// 可交换的操作符；associative 为 true 时嵌套调用可以展开，如 add(a, add(b, c)) 即 add(a, b, c)
+var commutativeOperators = map[string]struct{ associative bool }{
+	"add":       {true},
+	"multiply":  {true},
+	"max":       {true},
+	"min":       {true},
+	"and":       {true},
+	"or":        {true},
+	"equal":     {false},
+	"not_equal": {false},
+}
+
+// 二元运算对应的操作符
+var binaryOperatorNames = map[Kind]string{
+	ADD: "add", SUB: "subtract", MUL: "multiply", QUO: "divide", POW: "power",
+	LSS: "less", LEQ: "less_equal", GTR: "greater", GEQ: "greater_equal",
+	EQL: "equal", NEQ: "not_equal", AND: "and", OR: "or",
+}
+
+// BinaryOperatorName 返回二元运算对应的操作符名，如 + 即 add
+func BinaryOperatorName(op Kind) string {
+	return binaryOperatorNames[op]
+}
+
+// greater(a, b) 改写为 less(b, a)
+var swappedComparisons = map[string]string{
+	"greater":       "less",
+	"greater_equal": "less_equal",
+}
+
+// Canonical 返回一段表达式的规范形式
+func Canonical(src string) (string, error) {
+	program, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return CanonicalProgram(program), nil
+}
+
+// CanonicalProgram 返回已解析表达式的规范形式
+func CanonicalProgram(program *Program) string {
+	c := &canonicalizer{names: map[string]string{}}
+	stmts := make([]string, len(program.Stmts))
+	for i, stmt := range program.Stmts {
+		switch n := stmt.(type) {
+		case *Assign:
+			// 右边引用的是之前的赋值，之后才换成新名字
+			value := c.expr(n.Value)
+			c.assigned++
+			c.names[n.Name.Name] = "$" + strconv.Itoa(c.assigned)
+			stmts[i] = c.names[n.Name.Name] + "=" + value
+		case Expr:
+			stmts[i] = c.expr(n)
+		}
+	}
+	return strings.Join(stmts, ";")
+}
+
+// CanonicalHash 返回规范形式的 SHA-256（64 位十六进制）
+func CanonicalHash(src string) (string, error) {
+	canonical, err := Canonical(src)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type canonicalizer struct {
+	names    map[string]string // 局部变量当前对应的名字
+	assigned int
+}
+
+func (c *canonicalizer) expr(e Expr) string {
+	switch n := e.(type) {
+	case *Ident:
+		if name, ok := c.names[n.Name]; ok {
+			return name
+		}
+		return n.Name
+	case *Number:
+		return canonicalNumber(n.Value)
+	case *String:
+		return strconv.Quote(n.Value)
+	case *NamedArg:
+		return n.Name.Name + "=" + c.expr(n.Value)
+	case *UnaryOp:
+		switch n.Op {
+		case ADD:
+			return c.expr(n.X)
+		case SUB:
+			if number, ok := n.X.(*Number); ok {
+				return canonicalNumber("-" + number.Value)
+			}
+			return "reverse(" + c.expr(n.X) + ")"
+		default:
+			return "not(" + c.expr(n.X) + ")"
+		}
+	case *BinaryOp:
+		return c.call(binaryOperatorNames[n.Op], []Expr{n.X, n.Y})
+	case *Ternary:
+		return c.call("if_else", []Expr{n.Cond, n.Then, n.Else})
+	case *Call:
+		return c.call(n.Fun.Name, n.Args)
+	}
+	return ""
+}
+
+func (c *canonicalizer) call(name string, args []Expr) string {
+	var positional, named []string
+	for _, arg := range args {
+		if namedArg, ok := arg.(*NamedArg); ok {
+			named = append(named, c.expr(namedArg))
+		} else {
+			positional = append(positional, c.expr(arg))
+		}
+	}
+
+	// 1. greater/greater_equal 交换参数后改写为 less/less_equal
+	if swapped, ok := swappedComparisons[name]; ok && len(positional) == 2 && len(named) == 0 {
+		name = swapped
+		positional[0], positional[1] = positional[1], positional[0]
+	}
+
+	// 2. 可交换的操作符：展开嵌套的同名调用后排序
+	if op, ok := commutativeOperators[name]; ok {
+		if op.associative && len(named) == 0 {
+			positional = c.flatten(name, args)
+		}
+		sort.Strings(positional)
+	}
+	sort.Strings(named)
+
+	return name + "(" + strings.Join(append(positional, named...), ",") + ")"
+}
+
+// 展开 add(a, b + c) 这类同名且没有命名参数的嵌套调用，返回全部操作数的规范形式
+func (c *canonicalizer) flatten(name string, args []Expr) []string {
+	var operands []string
+	for _, arg := range args {
+		if inner, ok := c.sameOperator(name, arg); ok {
+			operands = append(operands, c.flatten(name, inner)...)
+			continue
+		}
+		operands = append(operands, c.expr(arg))
+	}
+	return operands
+}
+
+func (c *canonicalizer) sameOperator(name string, e Expr) ([]Expr, bool) {
+	switch n := e.(type) {
+	case *BinaryOp:
+		if binaryOperatorNames[n.Op] == name {
+			return []Expr{n.X, n.Y}, true
+		}
+	case *Call:
+		if n.Fun.Name != name {
+			return nil, false
+		}
+		for _, arg := range n.Args {
+			if _, isNamed := arg.(*NamedArg); isNamed {
+				return nil, false
+			}
+		}
+		return n.Args, true
+	}
+	return nil, false
+}
+
+// 按数值写出数字，无法解析时保留原文
+func canonicalNumber(text string) string {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return text
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,90 @@
+package fastexpr
+
+import "testing"
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"a + b", "add(a,b)"},
+		{"-x^2", "reverse(power(x,2))"},
+		{"-1.50", "-1.5"},
+		{"!a", "not(a)"},
+		{"+a", "a"},
+		{"c ? x : y", "if_else(c,x,y)"},
+		{"a > b", "less(b,a)"},
+		{"a >= b", "less_equal(b,a)"},
+		{"a - (b - c)", "subtract(a,subtract(b,c))"},
+		{"add(c, add(b, a))", "add(a,b,c)"},
+		{"ts_regression(y, x, 20, rettype=2, lag=1)", "ts_regression(y,x,20,lag=1,rettype=2)"},
+		{`f("a\"b", 'c')`, `f("a\"b","c")`},
+		{"a = close; b = a + 1; rank(b)", "$1=close;$2=add($1,1);rank($2)"},
+	}
+	for _, tt := range tests {
+		got, err := Canonical(tt.src)
+		if err != nil {
+			t.Errorf("Canonical(%q): %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonical(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalEquivalence(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		// 等价
+		{"commutative call", "add(a, b)", "add(b, a)", true},
+		{"operator and call", "a + b", "add(b, a)", true},
+		{"associative", "a + (b + c)", "(c + a) + b", true},
+		{"multiply nested", "multiply(x, multiply(y, z))", "x * y * z", true},
+		{"greater and less", "a > b", "b < a", true},
+		{"greater_equal call", "greater_equal(a, b)", "b <= a", true},
+		{"equal swapped", "a == b", "b == a", true},
+		{"ternary", "c ? x : y", "if_else(c, x, y)", true},
+		{"numbers", "ts_mean(x, 20)", "ts_mean(x, 20.0)", true},
+		{"exponent", "x * 1e3", "x * 1000", true},
+		{"named order", "f(x, a=1, b=2)", "f(x, b=2, a=1)", true},
+		{"whitespace and comments", "rank( close ) // c", "/* a */ rank(close)", true},
+		{"renamed variables", "a = rank(close); b = a * 2; b", "x = rank(close); y = x * 2; y", true},
+		{"reassigned variable", "a = rank(close); a = a + 1; a", "a = rank(close); b = a + 1; b", true},
+		{"trailing semicolon", "rank(close);", "rank(close)", true},
+
+		// 不等价
+		{"subtract order", "a - b", "b - a", false},
+		{"subtract assoc", "a - (b - c)", "(a - b) - c", false},
+		{"pow assoc", "2^3^4", "(2^3)^4", false},
+		{"neg pow", "-x^2", "(-x)^2", false},
+		{"less swapped", "a < b", "b < a", false},
+		{"named value", "f(x, a=1)", "f(x, a=2)", false},
+		{"named not flattened", "add(a, add(b, c, filter=true))", "add(a, b, c, filter=true)", false},
+		{"field vs variable", "a = close; a", "close", false},
+	}
+	for _, tt := range tests {
+		a, err := Canonical(tt.a)
+		if err != nil {
+			t.Errorf("%s: Canonical(%q): %v", tt.name, tt.a, err)
+			continue
+		}
+		b, err := Canonical(tt.b)
+		if err != nil {
+			t.Errorf("%s: Canonical(%q): %v", tt.name, tt.b, err)
+			continue
+		}
+		if (a == b) != tt.equal {
+			t.Errorf("%s: %s vs %s, equal = %v, want %v", tt.name, a, b, a == b, tt.equal)
+		}
+
+		ha, _ := CanonicalHash(tt.a)
+		hb, _ := CanonicalHash(tt.b)
+		if len(ha) != 64 || (ha == hb) != tt.equal {
+			t.Errorf("%s: CanonicalHash %s vs %s", tt.name, ha, hb)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package fastexpr
+
+import "strings"
+
+// ------------------------------------------------ 操作符签名 -----------------------------------------------
+
+// Operator 操作符目录中的一项，Definition 如 "ts_regression(y, x, d, lag = 0, rettype = 0)"，
+// Scope 为允许使用的 alpha 类型，如 REGULAR、COMBO、SELECTION，为空时不限；
+// GeniusLevel 为可以使用该操作符的最低 Genius 等级，Locked 表示高于账户当前等级
+type Operator struct {
+	Name        string
+	Definition  string
+	Scope       []string
+	Level       string
+	GeniusLevel string
+	Locked      bool
+}
+
+// Signature 从 Definition 解析出的参数表
+type Signature struct {
+	Positional []string // 必填的位置参数
+	Named      []string // 带默认值的命名参数
+	Variadic   bool     // 参数表中有 ... 时位置参数可以更多
+}
+
+// ParseDefinition 解析形如 name(x, y, filter = false) 的定义；
+// 定义不是以操作符名调用开头时（如只写了 x + y）返回 false
+func ParseDefinition(name, definition string) (Signature, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(definition), name)
+	if !ok {
+		return Signature{}, false
+	}
+	rest = strings.TrimLeft(rest, " ")
+	if !strings.HasPrefix(rest, "(") {
+		return Signature{}, false
+	}
+
+	params, ok := splitParams(rest[1:])
+	if !ok {
+		return Signature{}, false
+	}
+
+	var sig Signature
+	for _, param := range params {
+		switch {
+		case param == "":
+		case strings.Trim(param, ".") == "":
+			sig.Variadic = true
+		case strings.Contains(param, "="):
+			named, _, _ := strings.Cut(param, "=")
+			sig.Named = append(sig.Named, strings.TrimSpace(named))
+		default:
+			sig.Positional = append(sig.Positional, param)
+		}
+	}
+	return sig, true
+}
+
+// 按顶层逗号切分参数，到与开头匹配的右括号为止；参数中可以有嵌套括号和带逗号的字符串
+func splitParams(s string) ([]string, bool) {
+	var params []string
+	depth := 0
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ')':
+			params = append(params, strings.TrimSpace(s[start:i]))
+			return params, true
+		case c == ',' && depth == 0:
+			params = append(params, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return nil, false
+}
+
+// MinArgs 最少的位置参数个数
+func (s Signature) MinArgs() int {
+	return len(s.Positional)
+}
+
+// MaxArgs 最多的位置参数个数（命名参数也可以按位置传入），可变参数时返回 -1
+func (s Signature) MaxArgs() int {
+	if s.Variadic {
+		return -1
+	}
+	return len(s.Positional) + len(s.Named)
+}
+
+// HasNamed 是否有该命名参数
+func (s Signature) HasNamed(name string) bool {
+	for _, named := range s.Named {
+		if named == name {
+			return true
+		}
+	}
+	return false
+}
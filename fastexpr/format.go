@@ -0,0 +1,258 @@
+package fastexpr
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// ------------------------------------------------ 格式化 -----------------------------------------------
+//
+// 规范格式：
+//   - 每条语句一行，以分号结尾；原文最后一条语句没有分号时，输出中也没有
+//   - 二元运算符和赋值号两侧、逗号之后各一个空格，命名参数写作 name=value
+//   - 只保留优先级需要的括号
+//   - 一行超过 LineWidth 时，操作符调用的参数逐个换行，缩进 4 个空格
+//   - 行尾注释留在所在语句的行尾，其他注释（包括表达式内部的注释）放在其后第一条语句之前
+
+// LineWidth 格式化时一行的最大宽度
+const LineWidth = 80
+
+const indentUnit = "    "
+
+// Format 解析并按规范格式输出一段表达式，结果不以换行结尾
+func Format(src string) (string, error) {
+	tokens, err := Tokenize(src)
+	if err != nil {
+		return "", err
+	}
+	program, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	// 1. 按分号把词法单元分成语句，与 program.Stmts 一一对应
+	spans := statementSpans(tokens)
+
+	// 2. 注释归属：leading[i] 输出在第 i 条语句之前，leading[len(spans)] 输出在最后
+	leading := make([][]string, len(spans)+1)
+	trailing := make([]string, len(spans))
+	stmtOf := func(index int) int {
+		for i, span := range spans {
+			if index >= span.first && index <= span.last {
+				return i
+			}
+		}
+		return -1
+	}
+	for i, tok := range tokens {
+		if tok.Kind != COMMENT {
+			continue
+		}
+		text := strings.TrimRight(tok.Text, " \t\r")
+		prev, next := neighbour(tokens, i, -1), neighbour(tokens, i, 1)
+
+		// 与前一个词法单元在同一行，且该语句到此结束（之后最多只有它的分号）的是行尾注释
+		if prev >= 0 && tokens[prev].Pos.Line == tok.Pos.Line {
+			if s := stmtOf(prev); s >= 0 && (stmtOf(next) != s || next == spans[s].last) && trailing[s] == "" {
+				trailing[s] = text
+				continue
+			}
+		}
+
+		target := len(spans)
+		for s, span := range spans {
+			if span.last > i {
+				target = s
+				break
+			}
+		}
+		leading[target] = append(leading[target], text)
+	}
+
+	// 3. 逐条输出语句
+	p := &printer{width: LineWidth}
+	var lines []string
+	for i, stmt := range program.Stmts {
+		lines = append(lines, leading[i]...)
+		line := p.stmt(stmt)
+		if i < len(program.Stmts)-1 || tokens[spans[i].last].Kind == SEMICOLON {
+			line += ";"
+		}
+		if trailing[i] != "" {
+			line += " " + trailing[i]
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, leading[len(spans)]...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// 一条语句在 tokens 中的范围，last 包含结尾的分号
+type span struct {
+	first, last int
+}
+
+// 空语句（连续的分号）不产生范围，与 Parse 跳过空语句一致
+func statementSpans(tokens []Token) []span {
+	var spans []span
+	first := -1
+	for i, tok := range tokens {
+		switch tok.Kind {
+		case COMMENT:
+		case SEMICOLON:
+			if first >= 0 {
+				spans = append(spans, span{first: first, last: i})
+				first = -1
+			}
+		case EOF:
+			if first >= 0 {
+				spans = append(spans, span{first: first, last: i - 1})
+			}
+		default:
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	return spans
+}
+
+// 向前（step 为 -1）或向后查找最近的非注释词法单元，没有时返回 -1
+func neighbour(tokens []Token, i, step int) int {
+	for j := i + step; j >= 0 && j < len(tokens); j += step {
+		if tokens[j].Kind != COMMENT {
+			return j
+		}
+	}
+	return -1
+}
+
+// ---- 输出 ----
+
+type printer struct {
+	width int
+}
+
+// 不换行的输出，用于判断是否超出宽度
+var flatPrinter = &printer{width: math.MaxInt}
+
+func (p *printer) stmt(n Node) string {
+	switch n := n.(type) {
+	case *Assign:
+		prefix := n.Name.Name + " = "
+		return prefix + p.expr(n.Value, 0, textWidth(prefix))
+	case Expr:
+		return p.expr(n, 0, 0)
+	}
+	return ""
+}
+
+// expr 输出 e，indent 为当前行的缩进级别，col 为 e 开始的列
+func (p *printer) expr(e Expr, indent, col int) string {
+	switch n := e.(type) {
+	case *Ident:
+		return n.Name
+	case *Number:
+		return n.Value
+	case *String:
+		return n.Raw
+	case *NamedArg:
+		prefix := n.Name.Name + "="
+		return prefix + p.expr(n.Value, indent, col+textWidth(prefix))
+	case *UnaryOp:
+		op := n.Op.String()
+		return op + p.operand(n.X, unaryNeedsParens(n.X), indent, col+textWidth(op))
+	case *BinaryOp:
+		left := p.operand(n.X, binaryNeedsParens(n, n.X, true), indent, col)
+		mid := " " + n.Op.String() + " "
+		right := p.operand(n.Y, binaryNeedsParens(n, n.Y, false), indent, endCol(left, col)+textWidth(mid))
+		return left + mid + right
+	case *Ternary:
+		_, nested := n.Cond.(*Ternary)
+		cond := p.operand(n.Cond, nested, indent, col)
+		then := p.expr(n.Then, indent, endCol(cond, col)+3)
+		els := p.expr(n.Else, indent, endCol(then, endCol(cond, col)+3)+3)
+		return cond + " ? " + then + " : " + els
+	case *Call:
+		return p.call(n, indent, col)
+	}
+	return ""
+}
+
+func (p *printer) operand(e Expr, parens bool, indent, col int) string {
+	if !parens {
+		return p.expr(e, indent, col)
+	}
+	return "(" + p.expr(e, indent, col+1) + ")"
+}
+
+// 放得下时写在一行，否则每个参数一行
+func (p *printer) call(call *Call, indent, col int) string {
+	flat := call.Fun.Name + "("
+	for i, arg := range call.Args {
+		if i > 0 {
+			flat += ", "
+		}
+		flat += flatPrinter.expr(arg, 0, 0)
+	}
+	flat += ")"
+	if len(call.Args) == 0 || col+textWidth(flat) <= p.width {
+		return flat
+	}
+
+	var b strings.Builder
+	inner := strings.Repeat(indentUnit, indent+1)
+	b.WriteString(call.Fun.Name + "(\n")
+	for i, arg := range call.Args {
+		b.WriteString(inner)
+		b.WriteString(p.expr(arg, indent+1, textWidth(inner)))
+		if i < len(call.Args)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Repeat(indentUnit, indent) + ")")
+	return b.String()
+}
+
+// 二元运算的操作数：优先级低于父运算时加括号；同级时左结合运算的右操作数加括号；
+// ^ 的左操作数只能是基本表达式
+func binaryNeedsParens(parent *BinaryOp, child Expr, left bool) bool {
+	switch c := child.(type) {
+	case *Ternary:
+		return true
+	case *UnaryOp:
+		return parent.Op == POW && left
+	case *BinaryOp:
+		if parent.Op == POW {
+			return left || Precedence(c.Op) < Precedence(POW)
+		}
+		childLevel, parentLevel := Precedence(c.Op), Precedence(parent.Op)
+		return childLevel < parentLevel || (childLevel == parentLevel && !left)
+	}
+	return false
+}
+
+// -x^2 即 -(x^2)，其他二元运算和条件表达式需要括号
+func unaryNeedsParens(x Expr) bool {
+	switch c := x.(type) {
+	case *Ternary:
+		return true
+	case *BinaryOp:
+		return c.Op != POW
+	}
+	return false
+}
+
+// 输出 s 之后所在的列
+func endCol(s string, col int) int {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return textWidth(s[i+1:])
+	}
+	return col + textWidth(s)
+}
+
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,105 @@
+package fastexpr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"spacing", "rank( ts_mean(close,5) )", "rank(ts_mean(close, 5))"},
+		{"named args", "ts_regression(y,x,20,lag = 1)", "ts_regression(y, x, 20, lag=1)"},
+		{"binary", "a+b*c", "a + b * c"},
+		{"redundant parens", "((a + b)) * (c)", "(a + b) * c"},
+		{"left assoc", "(a - b) - c", "a - b - c"},
+		{"right operand", "a - (b - c)", "a - (b - c)"},
+		{"pow right assoc", "(2^3)^4 + 2^(3^4)", "(2 ^ 3) ^ 4 + 2 ^ 3 ^ 4"},
+		{"unary pow", "-x^2 + (-x)^2", "-x ^ 2 + (-x) ^ 2"},
+		{"unary binary", "-(a + b)", "-(a + b)"},
+		{"ternary", "a>0?x:(b>0?y:z)", "a > 0 ? x : b > 0 ? y : z"},
+		{"ternary cond", "(a ? b : c) ? d : e", "(a ? b : c) ? d : e"},
+		{"ternary operand", "(a ? b : c) + 1", "(a ? b : c) + 1"},
+		{"strings", `f( "a\"b" , 'c' )`, `f("a\"b", 'c')`},
+		{"statements", "a=ts_mean(close,5);b=a-close;rank(b)", "a = ts_mean(close, 5);\nb = a - close;\nrank(b)"},
+		{"empty statements", ";;a = 1;;rank(a)", "a = 1;\nrank(a)"},
+		{"trailing semicolon", "rank( close );", "rank(close);"},
+		{"trailing semicolon kept", "a=1;rank(a);;", "a = 1;\nrank(a);"},
+		{"trailing semicolon comment", "rank(close); // c", "rank(close); // c"},
+		{"leading comment", "# header\nrank(close)", "# header\nrank(close)"},
+		{"trailing comment", "a = 1; // one\nrank(a) // result", "a = 1; // one\nrank(a) // result"},
+		{"inner comment", "ts_mean(\n  close, // price\n  5\n)", "// price\nts_mean(close, 5)"},
+		{"final comment", "rank(close)\n/* end */", "rank(close)\n/* end */"},
+		{
+			name: "wrap",
+			src:  "group_neutralize(ts_regression(ts_zscore(close, 252), ts_zscore(volume, 252), 60), subindustry)",
+			want: "group_neutralize(\n    ts_regression(ts_zscore(close, 252), ts_zscore(volume, 252), 60),\n    subindustry\n)",
+		},
+		{
+			name: "wrap nested",
+			src:  "a = group_neutralize(ts_regression(ts_zscore(close, 252), ts_zscore(vwap_adjusted_volume, 252), 60, lag=1), subindustry)",
+			want: "a = group_neutralize(\n    ts_regression(\n        ts_zscore(close, 252),\n        ts_zscore(vwap_adjusted_volume, 252),\n        60,\n        lag=1\n    ),\n    subindustry\n)",
+		},
+	}
+	for _, tt := range tests {
+		got, err := Format(tt.src)
+		if err != nil {
+			t.Errorf("%s: Format: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Format(%q) =\n%s\nwant\n%s", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
+
+// 格式化结果再次格式化不变，且与原表达式等价
+func TestFormatIdempotent(t *testing.T) {
+	corpus := []string{
+		"-x^2",
+		"a = 1; rank(a);",
+		"2^3^4",
+		"a - (b - c) * -d",
+		"a ? b ? c : d : e ? f : g",
+		"!(a && b) || c == d != e",
+		"x^-1 + (-x)^(a + b)",
+		`trade_when(volume > adv20, rank(-returns), -1, mode="a\"b")`,
+		"# 动量\na = ts_delta(close, 5); // 5 日变化\nb = group_rank(a, industry);\n/* 最终 */ rank(b)",
+		"group_neutralize(ts_regression(ts_zscore(close, 252), ts_zscore(vwap_adjusted_volume, 252), 60, lag=1, rettype=2), bucket(rank(cap), range='0.1,1,0.1'))",
+		"if_else(rank(close) > 0.5, ts_sum(returns * volume, 20) / ts_sum(volume, 20), ts_mean(close - open, 10) * -1)",
+	}
+	for _, src := range corpus {
+		once, err := Format(src)
+		if err != nil {
+			t.Errorf("Format(%q): %v", src, err)
+			continue
+		}
+		twice, err := Format(once)
+		if err != nil {
+			t.Errorf("Format(Format(%q)): %v\n%s", src, err, once)
+			continue
+		}
+		if once != twice {
+			t.Errorf("Format not idempotent for %q:\n%s\n---\n%s", src, once, twice)
+		}
+
+		want, _ := Canonical(src)
+		if got, _ := Canonical(once); got != want {
+			t.Errorf("Format(%q) changed meaning: %s, want %s", src, got, want)
+		}
+		for _, line := range strings.Split(once, "\n") {
+			if textWidth(line) > LineWidth && !strings.Contains(line, "//") {
+				t.Errorf("Format(%q) line longer than %d: %q", src, LineWidth, line)
+			}
+		}
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	if _, err := Format("rank(close"); err == nil {
+		t.Error("Format accepted an unclosed call")
+	}
+}
@@ -0,0 +1,290 @@
+package fastexpr
+
+import (
+	"fmt"
+	"strings"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 词法分析 -----------------------------------------------
+
+// Pos 源码位置，Line、Col 从 1 开始，Col 按字节计
+type Pos struct {
+	Offset int
+	Line   int
+	Col    int
+}
+
+// String 如 3:14
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// Kind 词法单元类型
+type Kind int
+
+const (
+	EOF Kind = iota
+	IDENT
+	NUMBER
+	STRING
+	COMMENT
+
+	LPAREN    // (
+	RPAREN    // )
+	COMMA     // ,
+	SEMICOLON // ;
+	ASSIGN    // =
+	QUESTION  // ?
+	COLON     // :
+
+	ADD // +
+	SUB // -
+	MUL // *
+	QUO // /
+	POW // ^
+
+	LSS // <
+	LEQ // <=
+	GTR // >
+	GEQ // >=
+	EQL // ==
+	NEQ // !=
+	AND // &&
+	OR  // ||
+	NOT // !
+)
+
+var kindText = map[Kind]string{
+	EOF: "EOF", IDENT: "IDENT", NUMBER: "NUMBER", STRING: "STRING", COMMENT: "COMMENT",
+	LPAREN: "(", RPAREN: ")", COMMA: ",", SEMICOLON: ";", ASSIGN: "=", QUESTION: "?", COLON: ":",
+	ADD: "+", SUB: "-", MUL: "*", QUO: "/", POW: "^",
+	LSS: "<", LEQ: "<=", GTR: ">", GEQ: ">=", EQL: "==", NEQ: "!=", AND: "&&", OR: "||", NOT: "!",
+}
+
+func (k Kind) String() string {
+	if text, ok := kindText[k]; ok {
+		return text
+	}
+	return fmt.Sprintf("Kind(%d)", k)
+}
+
+// Token 一个词法单元，Text 为源码中的原文
+type Token struct {
+	Kind Kind
+	Text string
+	Pos  Pos
+}
+
+// SyntaxError 词法或语法错误
+type SyntaxError struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// Tokenize 把源码切分为词法单元（包含注释），最后一个为 EOF
+func Tokenize(src string) ([]Token, error) {
+	lx := &lexer{src: src, line: 1, col: 1}
+	var tokens []Token
+	for {
+		tok, err := lx.next()
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Kind == EOF {
+			return tokens, nil
+		}
+	}
+}
+
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+func (lx *lexer) pos() Pos {
+	return Pos{Offset: lx.off, Line: lx.line, Col: lx.col}
+}
+
+func (lx *lexer) peek(n int) byte {
+	if lx.off+n < len(lx.src) {
+		return lx.src[lx.off+n]
+	}
+	return 0
+}
+
+// 前进 n 个字节，遇到换行时更新行列
+func (lx *lexer) advance(n int) {
+	for i := 0; i < n && lx.off < len(lx.src); i++ {
+		if lx.src[lx.off] == '\n' {
+			lx.line++
+			lx.col = 1
+		} else {
+			lx.col++
+		}
+		lx.off++
+	}
+}
+
+func (lx *lexer) next() (Token, error) {
+	// 1. 跳过空白
+	for lx.off < len(lx.src) && isSpace(lx.src[lx.off]) {
+		lx.advance(1)
+	}
+
+	start := lx.pos()
+	if lx.off >= len(lx.src) {
+		return Token{Kind: EOF, Pos: start}, nil
+	}
+
+	emit := func(kind Kind, n int) (Token, error) {
+		text := lx.src[lx.off : lx.off+n]
+		lx.advance(n)
+		return Token{Kind: kind, Text: text, Pos: start}, nil
+	}
+
+	c := lx.src[lx.off]
+	switch {
+	// 2. 注释：// 与 # 到行尾，/* */ 可跨行
+	case c == '/' && lx.peek(1) == '/', c == '#':
+		n := strings.IndexByte(lx.src[lx.off:], '\n')
+		if n < 0 {
+			n = len(lx.src) - lx.off
+		}
+		return emit(COMMENT, n)
+	case c == '/' && lx.peek(1) == '*':
+		n := strings.Index(lx.src[lx.off+2:], "*/")
+		if n < 0 {
+			return Token{}, &SyntaxError{Pos: start, Msg: i18n.T("fastexpr.unclosed_comment")}
+		}
+		return emit(COMMENT, n+4)
+
+	// 3. 标识符、数字、字符串
+	case isIdentStart(c):
+		n := 1
+		for isIdentPart(lx.peek(n)) {
+			n++
+		}
+		return emit(IDENT, n)
+	case isDigit(c) || (c == '.' && isDigit(lx.peek(1))):
+		return emit(NUMBER, lx.scanNumber())
+	case c == '"' || c == '\'':
+		n := 1
+		for {
+			ch := lx.peek(n)
+			if lx.off+n >= len(lx.src) || ch == '\n' {
+				return Token{}, &SyntaxError{Pos: start, Msg: i18n.T("fastexpr.unclosed_string")}
+			}
+			if ch == '\\' {
+				n += 2
+				continue
+			}
+			n++
+			if ch == c {
+				return emit(STRING, n)
+			}
+		}
+	}
+
+	// 4. 两个字符的运算符优先
+	switch lx.src[lx.off:min(lx.off+2, len(lx.src))] {
+	case "<=":
+		return emit(LEQ, 2)
+	case ">=":
+		return emit(GEQ, 2)
+	case "==":
+		return emit(EQL, 2)
+	case "!=":
+		return emit(NEQ, 2)
+	case "&&":
+		return emit(AND, 2)
+	case "||":
+		return emit(OR, 2)
+	}
+
+	switch c {
+	case '(':
+		return emit(LPAREN, 1)
+	case ')':
+		return emit(RPAREN, 1)
+	case ',':
+		return emit(COMMA, 1)
+	case ';':
+		return emit(SEMICOLON, 1)
+	case '=':
+		return emit(ASSIGN, 1)
+	case '?':
+		return emit(QUESTION, 1)
+	case ':':
+		return emit(COLON, 1)
+	case '+':
+		return emit(ADD, 1)
+	case '-':
+		return emit(SUB, 1)
+	case '*':
+		return emit(MUL, 1)
+	case '/':
+		return emit(QUO, 1)
+	case '^':
+		return emit(POW, 1)
+	case '<':
+		return emit(LSS, 1)
+	case '>':
+		return emit(GTR, 1)
+	case '!':
+		return emit(NOT, 1)
+	}
+
+	return Token{}, &SyntaxError{Pos: start, Msg: i18n.T("fastexpr.bad_char", string(c))}
+}
+
+// 数字：123、1.5、.5、1e-3
+func (lx *lexer) scanNumber() int {
+	n := 0
+	for isDigit(lx.peek(n)) {
+		n++
+	}
+	if lx.peek(n) == '.' {
+		n++
+		for isDigit(lx.peek(n)) {
+			n++
+		}
+	}
+	if e := lx.peek(n); e == 'e' || e == 'E' {
+		m := n + 1
+		if s := lx.peek(m); s == '+' || s == '-' {
+			m++
+		}
+		if isDigit(lx.peek(m)) {
+			n = m
+			for isDigit(lx.peek(n)) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
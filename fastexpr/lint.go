@@ -0,0 +1,272 @@
+package fastexpr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 静态检查 -----------------------------------------------
+
+// 检查结果的级别
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding 一条检查结果，Rule 为规则名，如 unknown-operator
+type Finding struct {
+	Pos      Pos    `json:"pos"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// String 如 2:7: error: 未知的操作符 ts_meen (unknown-operator)
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Pos, f.Severity, f.Message, f.Rule)
+}
+
+// HasErrors 是否有 error 级别的结果
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog 操作符目录，签名在创建时解析一次
+type Catalog struct {
+	operators map[string]catalogEntry
+}
+
+type catalogEntry struct {
+	op     Operator
+	sig    Signature
+	hasSig bool
+}
+
+// NewCatalog 创建操作符目录，同名操作符以后出现的为准
+func NewCatalog(operators []Operator) *Catalog {
+	c := &Catalog{operators: make(map[string]catalogEntry, len(operators))}
+	for _, op := range operators {
+		sig, ok := ParseDefinition(op.Name, op.Definition)
+		c.operators[op.Name] = catalogEntry{op: op, sig: sig, hasSig: ok}
+	}
+	return c
+}
+
+// Len 操作符数量
+func (c *Catalog) Len() int {
+	return len(c.operators)
+}
+
+// Lookup 按名称查找操作符
+func (c *Catalog) Lookup(name string) (Operator, bool) {
+	entry, ok := c.operators[name]
+	return entry.op, ok
+}
+
+// Lint 检查一段表达式：语法、未知操作符、参数个数、未知命名参数、操作符是否允许用于 scope 类型的 alpha、
+// 是否高于账户的 Genius 等级，以及局部变量在赋值前使用、赋值后未使用。scope 为空时不检查类型
+func Lint(src string, catalog *Catalog, scope string) []Finding {
+	program, err := Parse(src)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return []Finding{{Pos: syntaxErr.Pos, Severity: SeverityError, Rule: "syntax", Message: syntaxErr.Msg}}
+		}
+		return []Finding{{Pos: Pos{Line: 1, Col: 1}, Severity: SeverityError, Rule: "syntax", Message: err.Error()}}
+	}
+	return LintProgram(program, catalog, scope)
+}
+
+// LintProgram 检查已经解析的表达式
+func LintProgram(program *Program, catalog *Catalog, scope string) []Finding {
+	l := &linter{
+		catalog:  catalog,
+		scope:    strings.ToUpper(strings.TrimSpace(scope)),
+		assigned: map[string]Pos{},
+		current:  map[string]*assignment{},
+	}
+	l.run(program)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return l.findings[i].Pos.Offset < l.findings[j].Pos.Offset
+	})
+	return l.findings
+}
+
+// 一次赋值，used 表示在被覆盖或结束前是否被引用过
+type assignment struct {
+	name *Ident
+	used bool
+}
+
+type linter struct {
+	catalog  *Catalog
+	scope    string
+	assigned map[string]Pos         // 每个局部变量第一次赋值的位置
+	current  map[string]*assignment // 当前生效的赋值
+	findings []Finding
+}
+
+func (l *linter) report(pos Pos, severity, rule, key string, args ...any) {
+	l.findings = append(l.findings, Finding{Pos: pos, Severity: severity, Rule: rule, Message: i18n.T(key, args...)})
+}
+
+func (l *linter) run(program *Program) {
+	// 1. 表达式不能为空，最后一条语句是 alpha 的值
+	if len(program.Stmts) == 0 {
+		l.report(program.Pos(), SeverityError, "empty", "lint.empty")
+		return
+	}
+	if last, ok := program.Stmts[len(program.Stmts)-1].(*Assign); ok {
+		l.report(last.Pos(), SeverityError, "no-result", "lint.no_result", last.Name.Name)
+	}
+
+	for _, stmt := range program.Stmts {
+		if assign, ok := stmt.(*Assign); ok {
+			if _, seen := l.assigned[assign.Name.Name]; !seen {
+				l.assigned[assign.Name.Name] = assign.Name.NamePos
+			}
+		}
+	}
+
+	// 2. 按顺序检查语句，右边先于赋值生效
+	for _, stmt := range program.Stmts {
+		switch n := stmt.(type) {
+		case *Assign:
+			l.expr(n.Value)
+			if previous := l.current[n.Name.Name]; previous != nil && !previous.used {
+				l.report(previous.name.NamePos, SeverityWarning, "unused-assignment", "lint.overwritten", n.Name.Name, n.Name.NamePos)
+			}
+			if _, isOperator := l.catalog.Lookup(n.Name.Name); isOperator {
+				l.report(n.Name.NamePos, SeverityWarning, "shadowed-operator", "lint.shadowed_operator", n.Name.Name)
+			}
+			l.current[n.Name.Name] = &assignment{name: n.Name}
+		case Expr:
+			l.expr(n)
+		}
+	}
+
+	// 3. 结束时仍未使用的赋值
+	for _, a := range l.current {
+		if !a.used {
+			l.report(a.name.NamePos, SeverityWarning, "unused-assignment", "lint.unused", a.name.Name)
+		}
+	}
+}
+
+func (l *linter) expr(e Expr) {
+	switch n := e.(type) {
+	case *Ident:
+		l.ident(n)
+	case *Call:
+		l.call(n)
+	case *NamedArg:
+		l.expr(n.Value)
+	case *UnaryOp:
+		l.expr(n.X)
+	case *BinaryOp:
+		l.expr(n.X)
+		l.expr(n.Y)
+	case *Ternary:
+		l.expr(n.Cond)
+		l.expr(n.Then)
+		l.expr(n.Else)
+	}
+}
+
+// 已赋值的局部变量标记为使用；后面才赋值的名字报错；其他标识符视为数据字段
+func (l *linter) ident(id *Ident) {
+	if a := l.current[id.Name]; a != nil {
+		a.used = true
+		return
+	}
+	if pos, later := l.assigned[id.Name]; later {
+		l.report(id.NamePos, SeverityError, "undefined-variable", "lint.used_before_assign", id.Name, pos)
+	}
+}
+
+func (l *linter) call(call *Call) {
+	name := call.Fun.Name
+	entry, ok := l.catalog.operators[name]
+
+	for _, arg := range call.Args {
+		l.expr(arg)
+	}
+
+	if !ok {
+		if _, isLocal := l.assigned[name]; isLocal {
+			l.report(call.Fun.NamePos, SeverityError, "unknown-operator", "lint.call_variable", name)
+		} else {
+			l.report(call.Fun.NamePos, SeverityError, "unknown-operator", "lint.unknown_operator", name)
+		}
+		return
+	}
+
+	// 1. 操作符是否允许用于当前类型的 alpha，2. 是否高于账户的 Genius 等级
+	if l.scope != "" && len(entry.op.Scope) > 0 && !containsFold(entry.op.Scope, l.scope) {
+		l.report(call.Fun.NamePos, SeverityError, "scope", "lint.scope", name, l.scope, strings.Join(entry.op.Scope, ", "))
+	}
+
+	if entry.op.Locked {
+		l.report(call.Fun.NamePos, SeverityError, "genius-level", "lint.genius_level", name, entry.op.GeniusLevel)
+	}
+
+	if !entry.hasSig {
+		return
+	}
+
+	// 3. 位置参数个数，位置参数不能出现在命名参数之后
+	positional := 0
+	seenNamed := map[string]bool{}
+	for _, arg := range call.Args {
+		named, isNamed := arg.(*NamedArg)
+		if !isNamed {
+			if len(seenNamed) > 0 {
+				l.report(arg.Pos(), SeverityError, "positional-after-named", "lint.positional_after_named", name)
+			}
+			positional++
+			continue
+		}
+
+		// 3. 命名参数必须在定义中，且不能重复
+		switch {
+		case !entry.sig.HasNamed(named.Name.Name):
+			known := strings.Join(entry.sig.Named, ", ")
+			if known == "" {
+				known = "-"
+			}
+			l.report(named.Pos(), SeverityError, "unknown-parameter", "lint.unknown_parameter", name, named.Name.Name, known)
+		case seenNamed[named.Name.Name]:
+			l.report(named.Pos(), SeverityError, "duplicate-parameter", "lint.duplicate_parameter", name, named.Name.Name)
+		}
+		seenNamed[named.Name.Name] = true
+	}
+
+	minArgs, maxArgs := entry.sig.MinArgs(), entry.sig.MaxArgs()
+	switch {
+	case positional < minArgs && maxArgs == minArgs:
+		l.report(call.Fun.NamePos, SeverityError, "arg-count", "lint.args_exact", name, minArgs, positional, entry.op.Definition)
+	case positional < minArgs:
+		l.report(call.Fun.NamePos, SeverityError, "arg-count", "lint.args_min", name, minArgs, positional, entry.op.Definition)
+	case maxArgs >= 0 && positional > maxArgs:
+		l.report(call.Fun.NamePos, SeverityError, "arg-count", "lint.args_max", name, maxArgs, positional, entry.op.Definition)
+	}
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package fastexpr
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testCatalog = NewCatalog([]Operator{
+	{Name: "rank", Definition: "rank(x, rate=2)"},
+	{Name: "ts_mean", Definition: "ts_mean(x, d)"},
+	{Name: "ts_regression", Definition: "ts_regression(y, x, d, lag = 0, rettype = 0)"},
+	{Name: "add", Definition: "add(x, y, filter = false)"},
+	{Name: "max", Definition: "max(x, y, ..)"},
+	{Name: "group_rank", Definition: "group_rank(x, group)"},
+	{Name: "combo_a", Definition: "combo_a(alpha, nlength = 250)", Scope: []string{"COMBO"}},
+	{Name: "ts_target_tvr_decay", Definition: "ts_target_tvr_decay(x, lambda_min=0)", GeniusLevel: "EXPERT", Locked: true},
+	{Name: "vector_neut", Definition: "x + y"},
+})
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		rule  string
+		src   string
+		scope string
+		pos   string // 该规则唯一一条结果的位置
+	}{
+		{"syntax", "rank(close", "", "1:11"},
+		{"empty", "// only a comment", "", "1:1"},
+		{"no-result", "a = rank(close)", "", "1:1"},
+		{"unused-assignment", "a = rank(close); b = ts_mean(close, 5); a", "", "1:18"},
+		{"unused-assignment", "a = rank(close); a = ts_mean(close, 5); a", "", "1:1"},
+		{"shadowed-operator", "rank = close; ts_mean(rank, 5)", "", "1:1"},
+		{"undefined-variable", "b = a + 1; a = close; b", "", "1:5"},
+		{"unknown-operator", "ts_meen(close, 5)", "", "1:1"},
+		{"unknown-operator", "f = close; f(1)", "", "1:12"},
+		{"scope", "combo_a(close)", "regular", "1:1"},
+		{"genius-level", "ts_target_tvr_decay(close)", "", "1:1"},
+		{"positional-after-named", "rank(close, rate=2, 3)", "", "1:21"},
+		{"unknown-parameter", "rank(close, rat=2)", "", "1:13"},
+		{"duplicate-parameter", "rank(close, rate=2, rate=3)", "", "1:21"},
+		{"arg-count", "ts_mean(close)", "", "1:1"},
+		{"arg-count", "ts_regression(close)", "", "1:1"},
+		{"arg-count", "rank(close, 2, 3)", "", "1:1"},
+	}
+	for _, tt := range tests {
+		var matched []Finding
+		for _, f := range Lint(tt.src, testCatalog, tt.scope) {
+			if f.Rule == tt.rule {
+				matched = append(matched, f)
+			}
+		}
+		if len(matched) != 1 {
+			t.Errorf("Lint(%q) = %v, want one %s finding", tt.src, matched, tt.rule)
+			continue
+		}
+		f := matched[0]
+		if f.Pos.String() != tt.pos || f.Message == "" {
+			t.Errorf("Lint(%q) = %v, want %s at %s", tt.src, f, tt.rule, tt.pos)
+		}
+		if !strings.HasPrefix(f.String(), tt.pos+": "+f.Severity+": ") || !strings.HasSuffix(f.String(), "("+tt.rule+")") {
+			t.Errorf("Finding.String() = %q", f.String())
+		}
+	}
+}
+
+func TestLintSeverity(t *testing.T) {
+	warnings := Lint("rank = close; ts_mean(rank, 5)", testCatalog, "")
+	if HasErrors(warnings) || warnings[0].Severity != SeverityWarning {
+		t.Errorf("shadowed-operator = %v, want a warning", warnings)
+	}
+	if errs := Lint("ts_meen(close, 5)", testCatalog, ""); !HasErrors(errs) {
+		t.Errorf("unknown-operator = %v, want an error", errs)
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	clean := []struct {
+		src   string
+		scope string
+	}{
+		{"rank(ts_mean(close, 20))", ""},
+		{"a = ts_mean(close, 5); b = a - close; group_rank(b, industry)", "REGULAR"},
+		{"ts_regression(close, volume, 20, lag=1, rettype=2)", ""},
+		{"max(a, b, c, d)", ""},
+		{"add(a, b, filter=true)", ""},
+		{"combo_a(alpha)", "COMBO"},
+		{"combo_a(alpha)", ""},
+		{"vector_neut(x, y, z)", ""}, // 定义不是调用形式时不检查参数
+		{"close > open ? rank(close) : -1", ""},
+	}
+	for _, tt := range clean {
+		if findings := Lint(tt.src, testCatalog, tt.scope); len(findings) != 0 {
+			t.Errorf("Lint(%q, %q) = %v, want none", tt.src, tt.scope, findings)
+		}
+	}
+}
+
+func TestLintOrder(t *testing.T) {
+	findings := Lint("x = 1;\nts_meen(rank(close, rat=1))", testCatalog, "")
+	var rules []string
+	for _, f := range findings {
+		rules = append(rules, f.Rule)
+	}
+	if !slices.Equal(rules, []string{"unused-assignment", "unknown-operator", "unknown-parameter"}) {
+		t.Errorf("rules = %v, want sorted by position", rules)
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	tests := []struct {
+		def      string
+		min, max int
+		named    []string
+		ok       bool
+	}{
+		{"rank(x, rate=2)", 1, 2, []string{"rate"}, true},
+		{"ts_regression(y, x, d, lag = 0, rettype = 0)", 3, 5, []string{"lag", "rettype"}, true},
+		{"max(x, y, ..)", 2, -1, nil, true},
+		{"bucket(x, range=\"0, 1, 0.1\")", 1, 2, []string{"range"}, true},
+		{"x + y", 0, 0, nil, false},
+	}
+	for _, tt := range tests {
+		name, _, _ := strings.Cut(tt.def, "(")
+		sig, ok := ParseDefinition(strings.TrimSpace(name), tt.def)
+		if ok != tt.ok {
+			t.Errorf("ParseDefinition(%q) ok = %v, want %v", tt.def, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if sig.MinArgs() != tt.min || sig.MaxArgs() != tt.max || !slices.Equal(sig.Named, tt.named) {
+			t.Errorf("ParseDefinition(%q) = %+v (min %d, max %d)", tt.def, sig, sig.MinArgs(), sig.MaxArgs())
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package fastexpr
+
+import (
+	"strings"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 递归下降语法分析 -----------------------------------------------
+//
+// program := stmt? (';' stmt?)*
+// stmt    := IDENT '=' expr | expr
+// expr    := or ('?' expr ':' expr)?
+// or      := and ('||' and)*
+// and     := eq ('&&' eq)*
+// eq      := rel (('==' | '!=') rel)*
+// rel     := add (('<' | '<=' | '>' | '>=') add)*
+// add     := mul (('+' | '-') mul)*
+// mul     := unary (('*' | '/') unary)*
+// unary   := ('-' | '+' | '!') unary | pow
+// pow     := primary ('^' unary)?
+// primary := NUMBER | STRING | IDENT | IDENT '(' args? ')' | '(' expr ')'
+// args    := arg (',' arg)*
+// arg     := IDENT '=' expr | expr
+
+// Parse 解析一段 FASTEXPR 源码，注释保存在 Program.Comments 中
+func Parse(src string) (*Program, error) {
+	tokens, err := Tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{}
+	program := &Program{}
+	for _, tok := range tokens {
+		if tok.Kind == COMMENT {
+			program.Comments = append(program.Comments, &Comment{TextPos: tok.Pos, Text: tok.Text})
+			continue
+		}
+		p.tokens = append(p.tokens, tok)
+	}
+
+	for p.peek().Kind != EOF {
+		if p.peek().Kind == SEMICOLON {
+			p.pos++
+			continue
+		}
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		program.Stmts = append(program.Stmts, stmt)
+
+		// 语句之间必须用分号分隔
+		if next := p.peek(); next.Kind != SEMICOLON && next.Kind != EOF {
+			return nil, p.unexpected(next, ";")
+		}
+	}
+	return program, nil
+}
+
+// ParseExpr 解析单个表达式（不含赋值和分号）
+func ParseExpr(src string) (Expr, error) {
+	program, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(program.Stmts) != 1 {
+		return nil, &SyntaxError{Pos: program.Pos(), Msg: i18n.T("fastexpr.single_expr", len(program.Stmts))}
+	}
+	expr, ok := program.Stmts[0].(Expr)
+	if !ok {
+		return nil, &SyntaxError{Pos: program.Pos(), Msg: i18n.T("fastexpr.single_expr", len(program.Stmts))}
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) peek() Token {
+	return p.peekN(0)
+}
+
+func (p *parser) peekN(n int) Token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return p.tokens[len(p.tokens)-1] // EOF
+}
+
+func (p *parser) next() Token {
+	tok := p.peek()
+	if tok.Kind != EOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind Kind) (Token, error) {
+	tok := p.peek()
+	if tok.Kind != kind {
+		return tok, p.unexpected(tok, kind.String())
+	}
+	return p.next(), nil
+}
+
+func (p *parser) unexpected(tok Token, want string) error {
+	return &SyntaxError{Pos: tok.Pos, Msg: i18n.T("fastexpr.unexpected", describe(tok), want)}
+}
+
+// 错误信息中的词法单元：标识符和字面量显示原文
+func describe(tok Token) string {
+	switch tok.Kind {
+	case EOF:
+		return i18n.T("fastexpr.eof")
+	case IDENT, NUMBER, STRING:
+		return "'" + tok.Text + "'"
+	}
+	return "'" + tok.Kind.String() + "'"
+}
+
+// ---- 语句 ----
+
+func (p *parser) parseStmt() (Node, error) {
+	if p.peek().Kind == IDENT && p.peekN(1).Kind == ASSIGN {
+		name := p.ident(p.next())
+		p.next() // =
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &Assign{Name: name, Value: value}, nil
+	}
+	return p.parseExpr()
+}
+
+// ---- 表达式 ----
+
+func (p *parser) parseExpr() (Expr, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().Kind != QUESTION {
+		return cond, nil
+	}
+
+	question := p.next().Pos
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	colon, err := p.expect(COLON)
+	if err != nil {
+		return nil, err
+	}
+	els, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &Ternary{Cond: cond, Question: question, Then: then, Colon: colon.Pos, Else: els}, nil
+}
+
+// 二元运算按优先级从低到高分层，同一层左结合
+var binaryLevels = [][]Kind{
+	{OR},
+	{AND},
+	{EQL, NEQ},
+	{LSS, LEQ, GTR, GEQ},
+	{ADD, SUB},
+	{MUL, QUO},
+}
+
+// Precedence 返回二元运算符的优先级（越大越先结合），^ 最高；不是二元运算符时返回 0
+func Precedence(kind Kind) int {
+	if kind == POW {
+		return len(binaryLevels) + 1
+	}
+	for i, level := range binaryLevels {
+		for _, k := range level {
+			if k == kind {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+func (p *parser) parseBinary(level int) (Expr, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+
+	x, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if Precedence(tok.Kind) != level+1 {
+			return x, nil
+		}
+		p.next()
+		y, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryOp{X: x, OpPos: tok.Pos, Op: tok.Kind, Y: y}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch tok := p.peek(); tok.Kind {
+	case SUB, ADD, NOT:
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryOp{OpPos: tok.Pos, Op: tok.Kind, X: x}, nil
+	}
+
+	// -x^2 解析为 -(x^2)，x^-1 的指数可以带符号，x^y^z 右结合
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind == POW {
+		p.next()
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryOp{X: x, OpPos: tok.Pos, Op: POW, Y: y}, nil
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.peek()
+	switch tok.Kind {
+	case NUMBER:
+		p.next()
+		return &Number{ValuePos: tok.Pos, Value: tok.Text}, nil
+	case STRING:
+		p.next()
+		return &String{ValuePos: tok.Pos, Value: unquote(tok.Text), Raw: tok.Text}, nil
+	case IDENT:
+		p.next()
+		if p.peek().Kind == LPAREN {
+			return p.parseCall(p.ident(tok))
+		}
+		return p.ident(tok), nil
+	case LPAREN:
+		p.next()
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(RPAREN); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return nil, p.unexpected(tok, i18n.T("fastexpr.want_expr"))
+}
+
+func (p *parser) parseCall(fun *Ident) (Expr, error) {
+	call := &Call{Fun: fun, Lparen: p.next().Pos}
+
+	for p.peek().Kind != RPAREN {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+
+		if p.peek().Kind != COMMA {
+			break
+		}
+		p.next()
+		// 逗号后必须还有参数，BRAIN 不接受 f(a,) 这样的尾随逗号
+		if tok := p.peek(); tok.Kind == RPAREN {
+			return nil, p.unexpected(tok, i18n.T("fastexpr.want_expr"))
+		}
+	}
+
+	rparen, err := p.expect(RPAREN)
+	if err != nil {
+		return nil, err
+	}
+	call.Rparen = rparen.Pos
+	return call, nil
+}
+
+func (p *parser) parseArg() (Expr, error) {
+	if p.peek().Kind == IDENT && p.peekN(1).Kind == ASSIGN {
+		name := p.ident(p.next())
+		p.next() // =
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &NamedArg{Name: name, Value: value}, nil
+	}
+	return p.parseExpr()
+}
+
+func (p *parser) ident(tok Token) *Ident {
+	return &Ident{NamePos: tok.Pos, Name: tok.Text}
+}
+
+// 去掉引号并处理反斜杠转义
+func unquote(raw string) string {
+	inner := raw[1 : len(raw)-1]
+	if !strings.Contains(inner, `\`) {
+		return inner
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+		}
+		b.WriteByte(inner[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package fastexpr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 以全括号形式输出语法树，便于检查结合方式
+func sexpr(n Node) string {
+	switch n := n.(type) {
+	case *Ident:
+		return n.Name
+	case *Number:
+		return n.Value
+	case *String:
+		return "'" + n.Value + "'"
+	case *NamedArg:
+		return n.Name.Name + "=" + sexpr(n.Value)
+	case *UnaryOp:
+		return "(" + n.Op.String() + sexpr(n.X) + ")"
+	case *BinaryOp:
+		return "(" + sexpr(n.X) + " " + n.Op.String() + " " + sexpr(n.Y) + ")"
+	case *Ternary:
+		return "(" + sexpr(n.Cond) + " ? " + sexpr(n.Then) + " : " + sexpr(n.Else) + ")"
+	case *Call:
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = sexpr(arg)
+		}
+		return n.Fun.Name + "(" + strings.Join(args, ", ") + ")"
+	case *Assign:
+		return n.Name.Name + " := " + sexpr(n.Value)
+	}
+	return "?"
+}
+
+func sexprProgram(program *Program) string {
+	stmts := make([]string, len(program.Stmts))
+	for i, stmt := range program.Stmts {
+		stmts[i] = sexpr(stmt)
+	}
+	return strings.Join(stmts, "; ")
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		// 一元负号低于 ^，^ 右结合，指数可以带符号
+		{"-x^2", "(-(x ^ 2))"},
+		{"2^3^4", "(2 ^ (3 ^ 4))"},
+		{"x^-1", "(x ^ (-1))"},
+		{"(-x)^2", "((-x) ^ 2)"},
+		{"!a && b", "((!a) && b)"},
+
+		// 同级左结合，括号改变结合方式
+		{"a - b - c", "((a - b) - c)"},
+		{"a - (b - c)", "(a - (b - c))"},
+		{"a / b * c", "((a / b) * c)"},
+		{"a + b * c", "(a + (b * c))"},
+		{"(a + b) * c", "((a + b) * c)"},
+
+		// 比较、相等、逻辑运算逐级降低
+		{"a + 1 > b * 2", "((a + 1) > (b * 2))"},
+		{"a < b == c >= d", "((a < b) == (c >= d))"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a == b != c", "((a == b) != c)"},
+
+		// 条件表达式：最低优先级，else 分支右嵌套，then 分支可以是条件表达式
+		{"a > 0 ? x : y", "((a > 0) ? x : y)"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b ? c : d : e", "(a ? (b ? c : d) : e)"},
+		{"(a ? b : c) ? d : e", "((a ? b : c) ? d : e)"},
+		{"a || b ? x + 1 : -y", "((a || b) ? (x + 1) : (-y))"},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpr(tt.src)
+		if err != nil {
+			t.Errorf("ParseExpr(%q): %v", tt.src, err)
+			continue
+		}
+		if got := sexpr(expr); got != tt.want {
+			t.Errorf("ParseExpr(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseProgram(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		want     string
+		comments []string
+	}{
+		{
+			name: "named args",
+			src:  "ts_regression(y, x, 20, lag=1, rettype = 2)",
+			want: "ts_regression(y, x, 20, lag=1, rettype=2)",
+		},
+		{
+			name: "named arg with expression",
+			src:  "group_neutralize(x, bucket(rank(cap), range='0,1,0.1'))",
+			want: "group_neutralize(x, bucket(rank(cap), range='0,1,0.1'))",
+		},
+		{
+			name: "string escapes",
+			src:  `f("a\"b", 'it\'s', "c\\d")`,
+			want: `f('a"b', 'it's', 'c\d')`,
+		},
+		{
+			name: "numbers",
+			src:  "f(1, 1.5, .5, 1e-3, 2E+4)",
+			want: "f(1, 1.5, .5, 1e-3, 2E+4)",
+		},
+		{
+			name:     "comments",
+			src:      "# header\nrank(close) // trailing\n/* block\n comment */",
+			want:     "rank(close)",
+			comments: []string{"# header", "// trailing", "/* block\n comment */"},
+		},
+		{
+			name: "statements",
+			src:  "a = ts_mean(close, 5);\nb = a - close;\nrank(b)",
+			want: "a := ts_mean(close, 5); b := (a - close); rank(b)",
+		},
+		{
+			name: "empty statements",
+			src:  ";;a = 1;; a;",
+			want: "a := 1; a",
+		},
+		{
+			name:     "empty",
+			src:      "  // nothing\n",
+			want:     "",
+			comments: []string{"// nothing"},
+		},
+	}
+	for _, tt := range tests {
+		program, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("%s: Parse: %v", tt.name, err)
+			continue
+		}
+		if got := sexprProgram(program); got != tt.want {
+			t.Errorf("%s: Parse = %s, want %s", tt.name, got, tt.want)
+		}
+		var comments []string
+		for _, c := range program.Comments {
+			comments = append(comments, c.Text)
+		}
+		if strings.Join(comments, "|") != strings.Join(tt.comments, "|") {
+			t.Errorf("%s: comments = %q, want %q", tt.name, comments, tt.comments)
+		}
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	program, err := Parse("a = 1;\n  rank(a, rate=2)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	call := program.Stmts[1].(*Call)
+	if got := call.Pos(); got != (Pos{Offset: 9, Line: 2, Col: 3}) {
+		t.Errorf("call pos = %+v", got)
+	}
+	if got := call.Args[1].Pos(); got.Line != 2 || got.Col != 11 {
+		t.Errorf("named arg pos = %v, want 2:11", got)
+	}
+	if got := call.Rparen; got.Line != 2 || got.Col != 17 {
+		t.Errorf("rparen = %v, want 2:17", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos string // 行:列
+	}{
+		// 词法错误
+		{`rank("abc`, "1:6"},
+		{"rank(x) /* open", "1:9"},
+		{"rank(x) @ 1", "1:9"},
+		{"a = 1;\nb = `x`", "2:5"},
+
+		// 语法错误
+		{"rank(x", "1:7"},
+		{"rank(x,", "1:8"},
+		{"ts_mean(close, 20,)", "1:19"},
+		{"f(a,)", "1:5"},
+		{"a b", "1:3"},
+		{"a = ;", "1:5"},
+		{"(a + b", "1:7"},
+		{"a ? b", "1:6"},
+		{"a +\n* b", "2:1"},
+		{"ts_mean(close, 5))", "1:18"},
+		{"a = 1\nrank(a)", "2:1"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want SyntaxError", tt.src, err)
+			continue
+		}
+		if got := syntaxErr.Pos.String(); got != tt.pos {
+			t.Errorf("Parse(%q) error at %s, want %s (%v)", tt.src, got, tt.pos, err)
+		}
+		if !strings.HasPrefix(err.Error(), tt.pos+": ") {
+			t.Errorf("Parse(%q) error = %q, want position prefix", tt.src, err)
+		}
+	}
+}
+
+func TestParseExprSingle(t *testing.T) {
+	for _, src := range []string{"", "a = 1", "a = 1; a; b"} {
+		if _, err := ParseExpr(src); err == nil {
+			t.Errorf("ParseExpr(%q) succeeded, want error", src)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package fastexpr
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------ 结构相似度 -----------------------------------------------
+//
+// 表达式的结构（Shape）由最后一条语句的值展开局部变量后得到，比较时使用以下特征：
+//   - 子表达式：每个操作符调用的规范形式，数字写作 #，如 ts_mean(close,#)
+//   - 操作符序列：从外到内相邻的 1~3 个操作符，如 rank>ts_mean
+//   - 字段，以及各操作符的数字参数，如 ts_mean:20
+//
+// 与 Canonical 一样，运算符改写为操作符名，可交换操作符的参数排序；相似度为两组特征的 Jaccard 系数
+
+// shingle 操作符序列的最大长度
+const shingleSize = 3
+
+// Shape 表达式的结构特征
+type Shape struct {
+	features map[string]bool
+	subtrees map[string]*subtree // 子表达式的规范形式 → 节点数和其中的子表达式
+}
+
+// 一种子表达式；出现多次时 contains 为各次出现中包含的子表达式的并集
+type subtree struct {
+	size     int
+	contains map[string]bool
+}
+
+// NewShape 解析表达式并提取结构特征
+func NewShape(src string) (*Shape, error) {
+	program, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return ShapeOf(program), nil
+}
+
+// ShapeOf 提取已解析表达式的结构特征；没有表达式语句时返回空的特征
+func ShapeOf(program *Program) *Shape {
+	b := &shapeBuilder{locals: map[string]*shapeNode{}}
+	var result *shapeNode
+	for _, stmt := range program.Stmts {
+		switch n := stmt.(type) {
+		case *Assign:
+			result = b.node(n.Value)
+			b.locals[n.Name.Name] = result
+		case Expr:
+			result = b.node(n)
+		}
+	}
+
+	shape := &Shape{features: map[string]bool{}, subtrees: map[string]*subtree{}}
+	if result != nil {
+		shape.collect(result, nil, "")
+	}
+	return shape
+}
+
+// Similarity 返回两个结构的相似度，0~1，1 表示特征完全相同
+func Similarity(a, b *Shape) float64 {
+	if len(a.features) == 0 && len(b.features) == 0 {
+		return 0
+	}
+	shared := 0
+	for feature := range a.features {
+		if b.features[feature] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a.features)+len(b.features)-shared)
+}
+
+// SharedSubexpressions 返回两个结构共有的子表达式，已包含在更大的共有子表达式中的不再列出，节点多的在前
+func SharedSubexpressions(a, b *Shape) []string {
+	var shared []string
+	for text := range a.subtrees {
+		if _, ok := b.subtrees[text]; ok {
+			shared = append(shared, text)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if a.subtrees[shared[i]].size != a.subtrees[shared[j]].size {
+			return a.subtrees[shared[i]].size > a.subtrees[shared[j]].size
+		}
+		return shared[i] < shared[j]
+	})
+
+	// 按语法树判断包含关系：ts_rank(x) 的文本包含 rank(x)，但 rank(x) 不是它的子表达式
+	var maximal []string
+	for _, text := range shared {
+		contained := false
+		for _, larger := range maximal {
+			if a.subtrees[larger].contains[text] || b.subtrees[larger].contains[text] {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			maximal = append(maximal, text)
+		}
+	}
+	return maximal
+}
+
+// ---- 结构树 ----
+
+// 结构树节点：操作符节点有子节点，叶子为字段、# 或字符串
+type shapeNode struct {
+	label    string
+	value    string // 数字叶子的数值
+	children []*shapeNode
+	text     string // 规范形式
+	size     int
+}
+
+type shapeBuilder struct {
+	locals map[string]*shapeNode // 局部变量当前的值，引用时直接展开
+}
+
+func (b *shapeBuilder) node(e Expr) *shapeNode {
+	switch n := e.(type) {
+	case *Ident:
+		if value, ok := b.locals[n.Name]; ok {
+			return value
+		}
+		return leaf(n.Name, "")
+	case *Number:
+		return leaf("#", canonicalNumber(n.Value))
+	case *String:
+		return leaf(strconv.Quote(n.Value), "")
+	case *NamedArg:
+		return b.op(n.Name.Name+"=", []Expr{n.Value})
+	case *UnaryOp:
+		switch n.Op {
+		case ADD:
+			return b.node(n.X)
+		case SUB:
+			if number, ok := n.X.(*Number); ok {
+				return leaf("#", canonicalNumber("-"+number.Value))
+			}
+			return b.op("reverse", []Expr{n.X})
+		default:
+			return b.op("not", []Expr{n.X})
+		}
+	case *BinaryOp:
+		return b.op(binaryOperatorNames[n.Op], []Expr{n.X, n.Y})
+	case *Ternary:
+		return b.op("if_else", []Expr{n.Cond, n.Then, n.Else})
+	case *Call:
+		return b.op(n.Fun.Name, n.Args)
+	}
+	return leaf("", "")
+}
+
+func leaf(label, value string) *shapeNode {
+	return &shapeNode{label: label, value: value, text: label, size: 1}
+}
+
+func (b *shapeBuilder) op(name string, args []Expr) *shapeNode {
+	children := make([]*shapeNode, len(args))
+	named := false
+	for i, arg := range args {
+		children[i] = b.node(arg)
+		if _, ok := arg.(*NamedArg); ok {
+			named = true
+		}
+	}
+
+	// 1. greater/greater_equal 交换参数后改写为 less/less_equal
+	if swapped, ok := swappedComparisons[name]; ok && len(children) == 2 && !named {
+		name = swapped
+		children[0], children[1] = children[1], children[0]
+	}
+
+	// 2. 可交换的操作符：展开嵌套的同名调用后按规范形式排序
+	if op, ok := commutativeOperators[name]; ok && !named {
+		if op.associative {
+			var flat []*shapeNode
+			for _, child := range children {
+				if child.label == name && !child.hasNamedArgs() {
+					flat = append(flat, child.children...)
+				} else {
+					flat = append(flat, child)
+				}
+			}
+			children = flat
+		}
+		sort.SliceStable(children, func(i, j int) bool { return children[i].text < children[j].text })
+	}
+
+	node := &shapeNode{label: name, children: children, size: 1}
+	texts := make([]string, len(children))
+	for i, child := range children {
+		texts[i] = child.text
+		node.size += child.size
+	}
+	node.text = name + "(" + strings.Join(texts, ",") + ")"
+	return node
+}
+
+func (n *shapeNode) hasNamedArgs() bool {
+	for _, child := range n.children {
+		if strings.HasSuffix(child.label, "=") {
+			return true
+		}
+	}
+	return false
+}
+
+// 收集 n 及其子树的特征，path 为外层操作符，parent 为直接包含 n 的操作符；
+// 返回 n 及其子树中记录的子表达式
+func (s *Shape) collect(n *shapeNode, path []string, parent string) []string {
+	if len(n.children) == 0 {
+		switch {
+		case n.value != "":
+			s.features["C:"+parent+":"+n.value] = true
+		case strings.HasSuffix(parent, "="):
+			// 命名参数的值（如 dense=false）按参数处理
+			s.features["C:"+parent+":"+n.label] = true
+		case n.label != "" && n.label != "#":
+			s.features["F:"+n.label] = true
+		}
+		return nil
+	}
+
+	// 命名参数不算操作符，其中的值归属于外层操作符
+	if strings.HasSuffix(n.label, "=") {
+		var texts []string
+		for _, child := range n.children {
+			texts = append(texts, s.collect(child, path, parent+"."+n.label)...)
+		}
+		return texts
+	}
+
+	s.features["S:"+n.text] = true
+	path = append(path, n.label)
+	for k := 1; k <= shingleSize && k <= len(path); k++ {
+		s.features["P:"+strings.Join(path[len(path)-k:], ">")] = true
+	}
+	var texts []string
+	for _, child := range n.children {
+		texts = append(texts, s.collect(child, path, n.label)...)
+	}
+
+	entry, ok := s.subtrees[n.text]
+	if !ok {
+		entry = &subtree{size: n.size, contains: map[string]bool{}}
+		s.subtrees[n.text] = entry
+	}
+	for _, text := range texts {
+		entry.contains[text] = true
+	}
+	return append(texts, n.text)
+}
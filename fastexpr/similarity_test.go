@@ -0,0 +1,111 @@
+package fastexpr
+
+import (
+	"slices"
+	"testing"
+)
+
+func mustShape(t *testing.T, src string) *Shape {
+	t.Helper()
+	shape, err := NewShape(src)
+	if err != nil {
+		t.Fatalf("NewShape(%q): %v", src, err)
+	}
+	return shape
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		// 结构相同
+		{"identical", "rank(ts_mean(close, 20))", "rank(ts_mean(close, 20))", 1, 1},
+		{"formatting", "rank(ts_mean(close,20)) // c", "rank( ts_mean( close, 20.0 ) )", 1, 1},
+		{"commutative", "add(rank(close), volume)", "volume + rank(close)", 1, 1},
+		{"comparison", "a > b ? x : y", "b < a ? x : y", 1, 1},
+		{"locals inlined", "a = ts_mean(close, 20); rank(a)", "rank(ts_mean(close, 20))", 1, 1},
+		{"locals renamed", "a = ts_delta(close, 5); b = rank(a); -b", "x = ts_delta(close, 5); y = rank(x); -y", 1, 1},
+
+		// 部分相同：参数、字段或外层操作符不同
+		{"window", "rank(ts_mean(close, 20))", "rank(ts_mean(close, 60))", 0.2, 0.8},
+		{"field", "rank(ts_mean(close, 20))", "rank(ts_mean(open, 20))", 0.2, 0.8},
+		{"wrapper", "rank(ts_mean(close, 20))", "zscore(ts_mean(close, 20))", 0.2, 0.8},
+
+		// 完全不同
+		{"disjoint", "rank(close)", "ts_sum(volume, 5)", 0, 0},
+		{"empty", "", "", 0, 0},
+	}
+	for _, tt := range tests {
+		a, b := mustShape(t, tt.a), mustShape(t, tt.b)
+		got := Similarity(a, b)
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: Similarity(%q, %q) = %.3f, want [%.1f, %.1f]", tt.name, tt.a, tt.b, got, tt.min, tt.max)
+		}
+		if reverse := Similarity(b, a); reverse != got {
+			t.Errorf("%s: Similarity not symmetric: %.3f vs %.3f", tt.name, got, reverse)
+		}
+	}
+}
+
+// 参数变化越多相似度越低
+func TestSimilarityOrdering(t *testing.T) {
+	base := mustShape(t, "group_rank(ts_mean(close, 20), industry)")
+	near := mustShape(t, "group_rank(ts_mean(close, 60), industry)")
+	far := mustShape(t, "group_rank(ts_sum(volume, 60), sector)")
+	if Similarity(base, near) <= Similarity(base, far) {
+		t.Errorf("Similarity(near) = %.3f, Similarity(far) = %.3f", Similarity(base, near), Similarity(base, far))
+	}
+}
+
+func TestSharedSubexpressions(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{
+			name: "largest first, contained omitted",
+			a:    "rank(ts_mean(close, 20)) + ts_delta(volume, 5)",
+			b:    "zscore(rank(ts_mean(close, 20))) - ts_delta(volume, 5)",
+			want: []string{"rank(ts_mean(close,#))", "ts_delta(volume,#)"},
+		},
+		{
+			name: "contained by name only",
+			a:    "ts_rank(close) - rank(close)",
+			b:    "rank(close) * ts_rank(close)",
+			want: []string{"rank(close)", "ts_rank(close)"},
+		},
+		{
+			name: "contained in the other shape",
+			a:    "ts_mean(rank(close), 5)",
+			b:    "ts_mean(rank(close), 20) + rank(close)",
+			want: []string{"ts_mean(rank(close),#)"},
+		},
+		{
+			name: "commutative",
+			a:    "add(rank(close), volume) * 2",
+			b:    "(volume + rank(close)) / 3",
+			want: []string{"add(rank(close),volume)"},
+		},
+		{
+			name: "numbers ignored",
+			a:    "ts_mean(close, 20)",
+			b:    "ts_mean(close, 60)",
+			want: []string{"ts_mean(close,#)"},
+		},
+		{
+			name: "none",
+			a:    "rank(close)",
+			b:    "rank(open)",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		got := SharedSubexpressions(mustShape(t, tt.a), mustShape(t, tt.b))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: SharedSubexpressions = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ------------------------------------------------ 中英文消息目录 -----------------------------------------------
+
+// 支持的语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// 单条消息的中英文文本，文本为 fmt 格式串
+type entry struct {
+	zh string
+	en string
+}
+
+var (
+	current  = Chinese
+	messages = map[string]entry{}
+	redact   = func(text string) string { return text }
+)
+
+// 各 messages_*.go 在 init 中注册自己的消息
+func register(catalog map[string]entry) {
+	for key, msg := range catalog {
+		if _, exists := messages[key]; exists {
+			panic("i18n: duplicate message key " + key)
+		}
+		messages[key] = msg
+	}
+}
+
+// SetLanguage 设置当前语言，空字符串表示默认中文
+func SetLanguage(language string) error {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "", Chinese:
+		current = Chinese
+	case English:
+		current = English
+	default:
+		return fmt.Errorf("unsupported language %q (zh|en) / 不支持的语言 %q (zh|en)", language, language)
+	}
+	return nil
+}
+
+// Language 返回当前语言
+func Language() string {
+	return current
+}
+
+// SetRedactor 设置格式化消息的脱敏函数，由 credentials 包注册，参数（如错误信息）中的密钥不会出现在输出中
+func SetRedactor(fn func(string) string) {
+	redact = fn
+}
+
+// T 按当前语言返回消息，带参数时按 fmt 格式化并脱敏；未登记的 key 原样返回，便于发现遗漏
+func T(key string, args ...any) string {
+	format, ok := lookup(key)
+	if !ok || len(args) == 0 {
+		return format
+	}
+	return redact(fmt.Sprintf(format, args...))
+}
+
+// Errorf 按当前语言构造错误，格式串中可以使用 %w
+func Errorf(key string, args ...any) error {
+	format, _ := lookup(key)
+	return fmt.Errorf(format, args...)
+}
+
+// 返回当前语言的格式串，英文缺失时回退到中文，未登记时返回 key 本身
+func lookup(key string) (string, bool) {
+	msg, ok := messages[key]
+	if !ok {
+		return key, false
+	}
+	if current == English && msg.en != "" {
+		return msg.en, true
+	}
+	return msg.zh, true
+}
+
+// Printf 按当前语言格式化消息并输出到标准输出
+func Printf(key string, args ...any) {
+	fmt.Print(T(key, args...))
+}
+
+// IsYes 判断确认输入是否为肯定回答（两种语言都接受）
+func IsYes(input string) bool {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes", "是", "确认", "1":
+		return true
+	}
+	return false
+}
+
+// IsNo 判断确认输入是否为否定回答（两种语言都接受）
+func IsNo(input string) bool {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "n", "no", "否", "取消":
+		return true
+	}
+	return false
+}
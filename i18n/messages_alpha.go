@@ -0,0 +1,62 @@
+package i18n
+
+// 阿尔法管理 (RunActiveAlphaManagement)
+func init() {
+	register(map[string]entry{
+		"alpha.menu.title":                 {"         ActiveAlpha 管理", "         ActiveAlpha management"},
+		"alpha.menu.fetch":                 {"1. 获取新的 Alpha", "1. Fetch new alphas"},
+		"alpha.menu.update":                {"2. 逐个重新拉取现有 Alpha（修复模式）", "2. Re-fetch every existing alpha (repair mode)"},
+		"alpha.menu.sync":                  {"3. 增量同步（按修改时间）", "3. Incremental sync (by modification time)"},
+		"alpha.menu.retry":                 {"4. 重试上次更新失败的 Alpha", "4. Retry alphas that failed last update"},
+		"alpha.menu.back":                  {"5. 返回主菜单", "5. Back to main menu"},
+		"alpha.menu.prompt":                {"请选择操作 (1-5): ", "Choose an option (1-5): "},
+		"alpha.fetch_failed":               {"获取新的 Alpha 失败", "failed to fetch new alphas"},
+		"alpha.fetch_done":                 {"获取新的 Alpha 成功！", "New alphas fetched!"},
+		"alpha.update_failed":              {"更新现有 Alpha 失败", "failed to update existing alphas"},
+		"alpha.update_done":                {"更新现有 Alpha 成功！", "Existing alphas updated!"},
+		"alpha.sync_failed":                {"增量同步 Alpha 失败", "failed to sync alphas"},
+		"alpha.sync_done":                  {"增量同步 Alpha 成功！", "Alphas synced!"},
+		"alpha.retry_failed":               {"重试失败的 Alpha 失败", "failed to retry alphas"},
+		"alpha.retry_done":                 {"重试失败的 Alpha 完成！", "Failed alphas retried!"},
+		"alpha.menu.invalid":               {"无效的选择，请输入 1-5 之间的数字！", "Invalid choice, please enter a number between 1 and 5!"},
+		"alpha.update.start":               {"开始更新模式：重新拉取数据库中已有数据", "update mode: re-fetching alphas already in the database"},
+		"alpha.update.ids_failed":          {"获取 Alpha ID 失败: %v", "failed to get alpha IDs: %v"},
+		"alpha.update.total":               {"数据库中Alpha数据需要更新", "alphas to update"},
+		"alpha.update.workers":             {"并发更新Alpha", "updating alphas concurrently"},
+		"alpha.update.progress":            {"更新进度", "update progress"},
+		"alpha.update.retry_written":       {"失败的Alpha已写入重试文件", "failed alphas written to retry file"},
+		"alpha.update.retry_write_failed":  {"写入重试文件失败", "failed to write retry file"},
+		"alpha.retry.start":                {"开始重试模式：重新拉取上次失败的Alpha", "retry mode: re-fetching alphas that failed last time"},
+		"alpha.retry.read_failed":          {"读取重试文件失败: %v", "failed to read retry file: %v"},
+		"alpha.retry.empty":                {"没有需要重试的Alpha", "no alphas to retry"},
+		"alpha.update.done":                {"更新模式完成", "update mode finished"},
+		"alpha.update.fetch_failed":        {"获取Alpha失败", "failed to fetch alpha"},
+		"alpha.update.save_failed":         {"更新Alpha到数据库失败", "failed to save alpha"},
+		"alpha.update.updated":             {"已更新Alpha", "alpha updated"},
+		"alpha.fetch.start":                {"开始获取模式：拉取新数据", "fetch mode: fetching new alphas"},
+		"alpha.fetch.max_date_failed":      {"获取最大提交日期失败: %v", "failed to get max date: %v"},
+		"alpha.fetch.empty_db":             {"数据库为空，从五年前开始获取", "database is empty, fetching from five years ago"},
+		"alpha.fetch.latest":               {"数据库中最新提交日期", "latest submission date in database"},
+		"alpha.fetch.parse_failed":         {"解析最大提交日期失败: %v", "failed to parse max date: %v"},
+		"alpha.fetch.up_to_date":           {"数据已是最新，无需获取", "already up to date"},
+		"alpha.fetch.page":                 {"获取数据", "fetching page"},
+		"alpha.fetch.no_more":              {"没有更多数据", "no more data"},
+		"alpha.fetch.batch_failed":         {"批量插入失败，尝试逐个插入", "batch insert failed, inserting one by one"},
+		"alpha.fetch.batch_done":           {"批次获取完成", "batch fetched"},
+		"alpha.fetch.done":                 {"获取模式完成", "fetch mode finished"},
+		"alpha.sync.start":                 {"开始增量同步：从水位按修改时间拉取", "incremental sync: fetching alphas modified after the watermark"},
+		"alpha.sync.no_watermark":          {"没有同步水位，从五年前开始增量同步", "no sync watermark yet, syncing from five years ago"},
+		"alpha.sync.watermark_failed":      {"读取同步水位失败: %v", "failed to read sync watermark: %v"},
+		"alpha.sync.save_watermark_failed": {"保存同步水位失败: %v", "failed to save sync watermark: %v"},
+		"alpha.sync.save_failed":           {"保存Alpha失败，水位停在此前", "failed to save alpha, watermark held before it"},
+		"alpha.sync.page_done":             {"增量同步一页完成", "sync page done"},
+		"alpha.sync.done":                  {"增量同步完成", "incremental sync finished"},
+		"alpha.changes.load_failed":        {"读取已有Alpha失败，跳过变化检测", "failed to load stored alpha, skipping change detection"},
+		"alpha.changes.record_failed":      {"记录Alpha字段变化失败", "failed to record alpha changes"},
+		"alpha.changes.none":               {"本次更新没有发现变化", "no alpha changed in this sync"},
+		"alpha.changes.summary": {
+			"%d 个 alpha 有变化：%d 个状态变化，%d 个有新的 OS 检查项，%d 个已下线（按列: %s）",
+			"%d alphas changed: %d changed status, %d got new OS checks, %d were decommissioned (by field: %s)",
+		},
+	})
+}
@@ -0,0 +1,15 @@
+package i18n
+
+// BRAIN 接口请求
+func init() {
+	register(map[string]entry{
+		"brain.api_error":               {"API返回错误: %s, 状态码: %d", "API returned error: %s, status code: %d"},
+		"brain.decode_json_failed":      {"解析JSON失败: %v", "failed to decode JSON: %v"},
+		"brain.fetch_alpha_failed":      {"获取 Alpha 失败: 状态码 %d, 响应: %s", "fetch alpha failed: status %d, response: %s"},
+		"brain.decode_failed_body":      {"解析响应失败: %v, 响应: %s", "decode failed: %v, response: %s"},
+		"brain.fetch_operators_failed":  {"获取操作符失败: 状态码 %d, 响应: %s", "fetch operators failed: status %d, response: %s"},
+		"brain.fetch_consultant_failed": {"获取顾问信息失败: 状态码 %d, 响应: %s", "fetch consultant failed: status %d, response: %s"},
+		"brain.fetch_pyramid_failed":    {"获取金字塔数据失败: %s\n%s", "fetch pyramid failed: %s\n%s"},
+		"brain.decode_failed":           {"解析响应失败: %v", "decode failed: %v"},
+	})
+}
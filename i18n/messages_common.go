@@ -0,0 +1,20 @@
+package i18n
+
+// 多个程序共用的消息
+func init() {
+	register(map[string]entry{
+		"common.db_connect_failed":      {"数据库连接失败: %v", "database connection failed: %v"},
+		"common.db_unknown_driver":      {"不支持的数据库驱动 %q（可选 mysql、postgres、sqlite）", "unsupported database driver %q (use mysql, postgres or sqlite)"},
+		"common.db_mkdir_failed":        {"创建数据库目录 %s 失败: %v", "failed to create database directory %s: %v"},
+		"common.time_parse_failed":      {"无法解析时间格式: %s", "cannot parse time: %s"},
+		"common.create_request_failed":  {"创建请求失败: %v", "create request failed: %v"},
+		"common.request_failed":         {"请求失败: %v", "request failed: %v"},
+		"common.read_response_failed":   {"读取响应失败: %v", "read response body failed: %v"},
+		"common.fetch_operators_failed": {"获取操作符失败: %v", "failed to fetch operators: %v"},
+		"common.fetch_alphas_failed":    {"获取 alpha 列表失败: %v", "failed to fetch alpha list: %v"},
+		"common.cancelled":              {"操作已取消", "Operation cancelled"},
+		"common.read_input_error":       {"读取输入失败: %v", "failed to read input: %v"},
+		"common.yes_no":                 {"请输入 y/是 或 n/否", "Please enter y or n"},
+		"common.read_input_failed":      {"读取输入失败", "failed to read input"},
+	})
+}
@@ -0,0 +1,37 @@
+package i18n
+
+// 配置文件与多账户
+func init() {
+	register(map[string]entry{
+		"config.profile.not_found": {"profiles 中不存在账户 %q", "profile %q not found in profiles"},
+		"config.profile.bad_dsn":   {"账户 %q 的数据库 DSN 无效: %v", "profile %q: invalid database dsn: %v"},
+
+		"config.invalid":              {"配置文件有 %d 处问题:", "config has %d problem(s):"},
+		"config.ok":                   {"配置检查通过: %s", "config OK: %s"},
+		"config.check.required":       {"必填", "is required"},
+		"config.check.no_profile":     {"配置了 profiles 且顶层 login 为空时必填，否则不带 --profile 运行会以空账户登录", "is required when profiles are configured and the top-level login is empty; otherwise running without --profile signs in with blank credentials"},
+		"config.check.password":       {"必填，可用 password_file、WQB_*_PASSWORD 环境变量或凭据库提供", "is required; set it via password_file, a WQB_*_PASSWORD env var or the credential store"},
+		"config.check.language":       {"只支持 zh 或 en，当前为 %q", "must be zh or en, got %q"},
+		"config.check.url":            {"需要 http(s) 开头的完整地址，当前为 %q", "must be an absolute http(s) URL, got %q"},
+		"config.check.trailing_slash": {"不要以 / 结尾（接口路径以 / 开头），当前为 %q", "must not end with / (paths start with /), got %q"},
+		"config.check.path":           {"需要以 / 开头，当前为 %q", "must start with /, got %q"},
+		"config.check.dsn":            {"DSN 格式无效: %v", "invalid DSN: %v"},
+		"config.check.dsn_db":         {"DSN 中缺少数据库名，如 .../worldquant?... 或 dbname=worldquant", "DSN has no database name, e.g. .../worldquant?... or dbname=worldquant"},
+		"config.check.schema":         {"库名不能包含 /、?、` 或空格，当前为 %q", "schema must not contain /, ?, ` or spaces, got %q"},
+		"config.check.schema_driver":  {"sqlite 驱动不支持 schema，当前驱动为 %s", "schema is not supported by the %s driver"},
+		"config.check.driver":         {"只支持 mysql、postgres 或 sqlite，当前为 %q", "must be mysql, postgres or sqlite, got %q"},
+		"config.check.non_negative":   {"不能为负数，当前为 %d", "must not be negative, got %d"},
+		"config.check.idle_gt_open":   {"最大空闲连接数 %d 大于最大连接数 %d", "max idle connections %d exceed max open connections %d"},
+		"config.check.addr":           {"需要 host:port 格式，如 :8080，当前为 %q", "must be host:port such as :8080, got %q"},
+		"config.check.server_basic":   {"server.username 与 server.password 需要同时配置", "server.username and server.password must be set together"},
+		"config.check.duration":       {"需要正的时长，如 30m、1h，当前为 %q", "must be a positive duration such as 30m or 1h, got %q"},
+		"config.check.log_level":      {"只支持 debug、info、warn、error，当前为 %q", "must be debug, info, warn or error, got %q"},
+		"config.check.log_format":     {"只支持 text 或 json，当前为 %q", "must be text or json, got %q"},
+		"config.check.genius_level":   {"只支持 %[2]s，当前为 %[1]q", "must be one of %[2]s, got %[1]q"},
+		"config.check.genius_quarter": {"需要 YYYY-Q[1-4] 格式，如 2025-Q3，当前为 %q", "must look like YYYY-Q[1-4] such as 2025-Q3, got %q"},
+		"config.check.date":           {"需要 YYYY-MM-DD 格式，当前为 %q", "must be a YYYY-MM-DD date, got %q"},
+		"config.check.date_range":     {"结束日期 %[2]s 早于开始日期 %[1]s", "end date %[2]s is before start date %[1]s"},
+		"config.check.alpha_type":     {"只支持 REGULAR 或 SUPER，当前为 %q", "must be REGULAR or SUPER, got %q"},
+		"config.check.delay":          {"只支持 0 或 1，当前为 %d", "must be 0 or 1, got %d"},
+	})
+}
@@ -0,0 +1,29 @@
+package i18n
+
+// 凭据：环境变量、密钥文件与加密凭据库
+func init() {
+	register(map[string]entry{
+		"credentials.bad_int":            {"%s 需要整数，得到 %q", "%s expects an integer, got %q"},
+		"credentials.bad_bool":           {"%s 需要 true/false，得到 %q", "%s expects true/false, got %q"},
+		"credentials.empty_passphrase":   {"凭据库口令不能为空", "credential store passphrase must not be empty"},
+		"credentials.read_failed":        {"读取凭据库失败: %v", "failed to read credential store: %v"},
+		"credentials.corrupt":            {"凭据库 %s 已损坏: %v", "credential store %s is corrupt: %v"},
+		"credentials.bad_version":        {"不支持的凭据库版本: %d", "unsupported credential store version: %d"},
+		"credentials.wrong_passphrase":   {"凭据库口令错误", "wrong credential store passphrase"},
+		"credentials.write_failed":       {"写入凭据库失败: %v", "failed to write credential store: %v"},
+		"credentials.secret_file_failed": {"读取密钥文件 %s 失败: %v", "failed to read secret file %s: %v"},
+		"credentials.passphrase_prompt":  {"请输入凭据库口令: ", "Credential store passphrase: "},
+		"credentials.passphrase_new":     {"请设置凭据库口令: ", "Choose a credential store passphrase: "},
+		"credentials.passphrase_confirm": {"请再次输入口令: ", "Repeat the passphrase: "},
+		"credentials.passphrase_differs": {"两次输入的口令不一致", "passphrases do not match"},
+		"credentials.read_secret_failed": {"读取输入失败: %v", "failed to read input: %v"},
+		"credentials.no_store":           {"未配置 credentials.store", "credentials.store is not configured"},
+		"credentials.unknown_key":        {"配置中不存在字段 %q", "no config field %q"},
+		"credentials.value_prompt":       {"请输入 %s 的值: ", "Value for %s: "},
+		"credentials.saved":              {"已保存 %s", "Saved %s"},
+		"credentials.deleted":            {"已删除 %s", "Deleted %s"},
+		"credentials.not_found":          {"凭据库中没有 %s", "%s is not in the credential store"},
+		"credentials.empty":              {"凭据库为空", "The credential store is empty"},
+		"credentials.usage":              {"用法: credentials set <键> | delete <键> | list，键如 login.password、profiles.alice.login.password", "usage: credentials set <key> | delete <key> | list, keys look like login.password or profiles.alice.login.password"},
+	})
+}
@@ -0,0 +1,22 @@
+package i18n
+
+// 重复表达式查找 (dupes)
+func init() {
+	register(map[string]entry{
+		"dupes.load_alphas_failed": {"读取 alpha 列表失败: %v", "failed to load alphas: %v"},
+		"dupes.update_failed":      {"更新 alpha %s 的 canonical_hash 失败: %v", "failed to update canonical_hash of alpha %s: %v"},
+		"dupes.query_failed":       {"按 canonical_hash 查询失败: %v", "failed to query by canonical_hash: %v"},
+		"dupes.read_input_failed":  {"读取表达式失败: %v", "failed to read expressions: %v"},
+		"dupes.backfilled":         {"已补算规范化表达式哈希", "canonical hashes backfilled"},
+		"dupes.backfill_done":      {"已更新 %d 个 alpha 的 canonical_hash，%d 个表达式无法解析", "updated canonical_hash of %d alphas, %d expressions could not be parsed"},
+		"dupes.flag_backfill":      {"先为已有的 alpha 计算 canonical_hash", "compute canonical_hash for existing alphas first"},
+		"dupes.flag_same_settings": {"只把地区、股票池、延迟和中性化方式也相同的 alpha 视为重复", "only treat alphas with the same region, universe, delay and neutralization as duplicates"},
+		"dupes.flag_file":          {"从文件读取候选表达式，表达式可以跨行，在语法完整的行尾结束", "read candidate expressions from a file; an expression may span lines and ends at the first line where it is complete"},
+		"dupes.none":               {"没有发现重复的 alpha（没有 canonical_hash 的记录请先运行 dupes --backfill）", "no duplicate alphas found (run dupes --backfill for rows without canonical_hash)"},
+		"dupes.group":              {"\n#%d  %d 个 alpha  hash %s", "\n#%d  %d alphas  hash %s"},
+		"dupes.summary":            {"\n共 %d 组，%d 个 alpha", "\n%d groups, %d alphas"},
+		"dupes.parse_failed":       {"%s: 无法解析: %v", "%s: cannot parse: %v"},
+		"dupes.unique":             {"%s: 没有重复", "%s: no duplicates"},
+		"dupes.duplicate":          {"%s: 与 %d 个已有表达式相同", "%s: same as %d existing expressions"},
+	})
+}
@@ -0,0 +1,18 @@
+package i18n
+
+// 权重|因子价值差分 (SaveWeightValueFactor)
+func init() {
+	register(map[string]entry{
+		"factor.banner":           {"\n====================== 执行Weight和Value_factor更新 ======================", "\n====================== Weight and value factor update ======================"},
+		"factor.nil_response":     {"顾问信息响应为空", "consultant response is nil"},
+		"factor.query_failed":     {"查询失败: %v", "query failed: %v"},
+		"factor.count_failed":     {"查询用户数据总数失败: %v", "failed to count user records: %v"},
+		"factor.first_record":     {"这是第一次记录 Weight_factor 和 Value_factor 数据。\n", "This is the first weight/value factor record for this user.\n"},
+		"factor.latest_failed":    {"获取最新数据失败: %v", "failed to fetch latest record: %v"},
+		"factor.already_recorded": {"今天已经记录过数据 (记录时间 %s)\n", "Already recorded today (at %s)\n"},
+		"factor.compare":          {"与最近记录(日期: %s, 记录时间: %s)比较:\n", "Compared with the latest record (date: %s, time: %s):\n"},
+		"factor.weight_change":    {"权重因子: %.4f -> %.4f (变化: %+.4f, 变化率: %+.2f%%)\n", "Weight factor: %.4f -> %.4f (change: %+.4f, rate: %+.2f%%)\n"},
+		"factor.value_change":     {"价值因子: %.4f -> %.4f (变化: %+.4f, 变化率: %+.2f%%)\n", "Value factor: %.4f -> %.4f (change: %+.4f, rate: %+.2f%%)\n"},
+		"factor.saved":            {"数据保存成功!", "Data saved!"},
+	})
+}
@@ -0,0 +1,14 @@
+package i18n
+
+// FASTEXPR 词法与语法分析
+func init() {
+	register(map[string]entry{
+		"fastexpr.unclosed_comment": {"注释 /* 没有结束", "unterminated /* comment"},
+		"fastexpr.unclosed_string":  {"字符串没有结束", "unterminated string"},
+		"fastexpr.bad_char":         {"无法识别的字符 %q", "unexpected character %q"},
+		"fastexpr.unexpected":       {"意外的 %s，期望 %s", "unexpected %s, expected %s"},
+		"fastexpr.eof":              {"表达式结尾", "end of expression"},
+		"fastexpr.want_expr":        {"表达式", "an expression"},
+		"fastexpr.single_expr":      {"需要单个表达式，得到 %d 条语句", "expected a single expression, got %d statements"},
+	})
+}
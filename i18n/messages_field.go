@@ -0,0 +1,67 @@
+package i18n
+
+// 字段使用情况检查 (FieldCheck)
+func init() {
+	register(map[string]entry{
+		"field.found":                 {"\n🔍 找到相关Alpha:", "\n🔍 Matching alphas:"},
+		"field.detail_api":            {"     如需查看详情: %s/alphas/%s\n", "     Details: %s/alphas/%s\n"},
+		"field.detail_web":            {"     或访问: %s/alpha/%s\n", "     Or visit: %s/alpha/%s\n"},
+		"field.no_other":              {"\n⚠️  未找到包含这些字段的其他Alpha。\n", "\n⚠️  No other alpha uses these fields.\n"},
+		"field.current":               {"   当前Alpha: %s/alphas/%s\n", "   Current alpha: %s/alphas/%s\n"},
+		"field.none":                  {"\n❌ 未找到包含这些字段的Alpha。", "\n❌ No alpha uses these fields."},
+		"field.genius_locked":         {"\n⚠️  以下操作符高于账户的 Genius 等级:", "\n⚠️  These operators are above the account's Genius level:"},
+		"field.genius_failed":         {"Genius 等级检查失败", "Genius level check failed"},
+		"field.prompt":                {"\n请输入（输入 'quit' 退出）: ", "\nEnter input ('quit' to exit): "},
+		"field.read_error":            {"读取输入时出错:", "Error reading input:"},
+		"field.empty_input":           {"输入不能为空", "input must not be empty"},
+		"field.banner":                {"\n====================== 执行字段检查 ======================", "\n====================== Field check ======================"},
+		"field.running":               {"🚀 字段检查功能正在执行...", "🚀 Running field check..."},
+		"field.formats":               {"📝 支持的输入格式:", "📝 Supported input formats:"},
+		"field.format_url":            {"   1. 完整URL: https://platform.worldquantbrain.com/alpha/1Y5Nj28K", "   1. Full URL: https://platform.worldquantbrain.com/alpha/1Y5Nj28K"},
+		"field.format_expr":           {"   3. Alpha表达式: (rank(correlation(close, volume, 10)))", "   3. Alpha expression: (rank(correlation(close, volume, 10)))"},
+		"field.scope":                 {"🔎 查找范围: 提交日期 %s ~ %s，类型 %s，地区 %s，股票池 %s，延迟 %s\n", "🔎 Scope: submitted %s ~ %s, type %s, region %s, universe %s, delay %s\n"},
+		"field.bye":                   {"👋 再见！", "👋 Bye!"},
+		"field.empty_retry":           {"⚠️  输入不能为空，请重新输入。", "⚠️  Input must not be empty, please try again."},
+		"field.failed":                {"❌ 字段检查失败: %v\n", "❌ Field check failed: %v\n"},
+		"field.detected_id":           {"🔍 检测到Alpha ID: %s\n", "🔍 Detected alpha ID: %s\n"},
+		"field.fetch_failed":          {"❌ 无法获取Alpha '%s' 的详情: %v\n", "❌ Cannot fetch details of alpha '%s': %v\n"},
+		"field.no_code":               {"📝 没有该 Alpha 的代码，不提取字段", "📝 No code for this alpha, no fields extracted"},
+		"field.fields_from_alpha":     {"📊 从Alpha代码中提取到 %d 个字段\n", "📊 Extracted %d fields from the alpha code\n"},
+		"field.detected_expr":         {"📝 检测到Alpha表达式", "📝 Detected alpha expression"},
+		"field.fields_from_expr":      {"📊 从表达式中提取到 %d 个字段\n", "📊 Extracted %d fields from the expression\n"},
+		"field.done":                  {"✅ 字段检查完成！", "✅ Field check finished!"},
+		"field.overlap":               {"     重叠字段 %d/%d: %s\n", "     Overlapping fields %d/%d: %s\n"},
+		"field.match_info":            {"     类型: %s  设置: %s  提交: %s\n", "     Type: %s  Settings: %s  Submitted: %s\n"},
+		"field.load_operators_failed": {"读取操作符失败: %v", "failed to load operators: %v"},
+		"field.no_operators":          {"operators 表为空，请先更新操作符", "the operators table is empty, update operators first"},
+		"field.load_alphas_failed":    {"读取 alpha 失败: %v", "failed to load alphas: %v"},
+		"field.query_failed":          {"查询字段索引失败: %v", "failed to query the field index: %v"},
+		"field.index_write_failed":    {"写入字段索引失败: %v", "failed to write the field index: %v"},
+		"field.index_disabled":        {"无法加载操作符，本次同步不更新字段索引", "cannot load operators, the field index is not updated in this sync"},
+		"field.index_failed":          {"更新字段索引失败", "failed to update the field index"},
+		"field.reindexed":             {"字段索引已重建", "field index rebuilt"},
+		"field.reindex_done":          {"已为 %d 个 alpha 重建字段索引，共 %d 条记录", "rebuilt the field index for %d alphas, %d rows"},
+		"field.index_header":          {"\n🔍 使用 %s 的 alpha（%d 个）:", "\n🔍 Alphas using %s (%d):"},
+		"field.flag_reindex":          {"由已保存的 alpha 代码重建字段索引", "rebuild the field index from stored alpha code"},
+		"field.usage":                 {"用法: fields --reindex | [--from 日期] [--to 日期] [--type 类型] [--region 地区] [--universe 股票池] [--delay 延迟] <字段>... | --batch 文件|- [--format json|csv] [--output 文件]", "usage: fields --reindex | [--from DATE] [--to DATE] [--type TYPE] [--region REGION] [--universe UNIVERSE] [--delay DELAY] <field>... | --batch path|- [--format json|csv] [--output path]"},
+		"field.flag_from":             {"只查找此日期（YYYY-MM-DD）及之后提交的 alpha", "only alphas submitted on or after this date (YYYY-MM-DD)"},
+		"field.flag_to":               {"只查找此日期（YYYY-MM-DD）及之前提交的 alpha", "only alphas submitted on or before this date (YYYY-MM-DD)"},
+		"field.flag_type":             {"alpha 类型，逗号分隔: REGULAR,SUPER", "alpha types, comma separated: REGULAR,SUPER"},
+		"field.flag_region":           {"地区，逗号分隔，如 USA,CHN", "regions, comma separated, e.g. USA,CHN"},
+		"field.flag_universe":         {"股票池，逗号分隔，如 TOP3000", "universes, comma separated, e.g. TOP3000"},
+		"field.flag_delay":            {"延迟: 0 或 1", "delay: 0 or 1"},
+		"field.bad_delay":             {"延迟需要是整数，当前为 %q", "delay must be an integer, got %q"},
+		"field.bad_date":              {"日期范围无效: %v", "invalid date range: %v"},
+		"field.not_stored":            {"数据库中没有 alpha %s，未登录时无法通过 API 获取", "alpha %s is not stored and cannot be fetched without logging in"},
+		"field.load_alpha_failed":     {"从数据库读取 alpha %s 失败: %v", "failed to load alpha %s from the database: %v"},
+		"field.flag_batch":            {"批量检查的输入文件，- 为标准输入；每行一个 Alpha ID 或链接，表达式可以跨行，在语法完整的行尾结束；也可以是字符串的 JSON 数组", "input file for a batch check, - for stdin; one alpha ID or link per line, an expression may span lines and ends at the first line where it is complete; or a JSON array of strings"},
+		"field.flag_format":           {"批量检查报告的格式: json 或 csv", "batch report format: json or csv"},
+		"field.flag_output":           {"批量检查报告的输出文件，默认为标准输出", "file to write the batch report to, stdout by default"},
+		"field.bad_format":            {"报告格式只能是 json 或 csv，当前为 %q", "report format must be json or csv, got %q"},
+		"field.batch_read_failed":     {"读取批量输入失败: %v", "failed to read batch input: %v"},
+		"field.batch_bad_json":        {"批量输入不是字符串的 JSON 数组: %v", "batch input is not a JSON array of strings: %v"},
+		"field.batch_empty":           {"批量输入中没有表达式或 Alpha ID", "no expressions or alpha IDs in the batch input"},
+		"field.batch_done":            {"批量字段检查完成", "batch field check finished"},
+		"field.batch_write_failed":    {"写入批量检查报告失败: %v", "failed to write the batch report: %v"},
+	})
+}
@@ -0,0 +1,22 @@
+package i18n
+
+// 表达式格式化 (wqb fmt)
+func init() {
+	register(map[string]entry{
+		"fmt.usage":              {"用法: fmt [--check] [--stored [--type 类型] | --file 文件 [-w] | <表达式> | -]，文件中的表达式可以跨行，在语法完整的行尾结束", "usage: fmt [--check] [--stored [--type TYPE] | --file path [-w] | <expression> | -], an expression in a file may span lines and ends at the first line where it is complete"},
+		"fmt.flag_check":         {"只检查，列出未格式化的表达式，有则退出码为 1", "check only: list unformatted expressions and exit 1 if there are any"},
+		"fmt.flag_file":          {"格式化文件中的多个表达式，表达式可以跨行，在语法完整的行尾结束", "format the expressions in a file; an expression may span lines and ends at the first line where it is complete"},
+		"fmt.flag_write":         {"把结果写回 --file 指定的文件", "write the result back to the --file path"},
+		"fmt.flag_stored":        {"格式化 active_alpha_list 中保存的表达式（只输出，不修改数据库）", "format the expressions stored in active_alpha_list (output only, the database is not modified)"},
+		"fmt.flag_type":          {"与 --stored 一起使用，只处理该类型的 alpha，如 REGULAR、SUPER", "with --stored, only alphas of this type such as REGULAR or SUPER"},
+		"fmt.read_input_failed":  {"读取表达式失败: %v", "failed to read expressions: %v"},
+		"fmt.write_failed":       {"写回文件失败: %v", "failed to write the file: %v"},
+		"fmt.write_needs_file":   {"-w 只能与 --file 一起使用，不能用于 --stored、参数或标准输入", "-w requires --file and cannot be used with --stored, an argument or standard input"},
+		"fmt.boundary_changed":   {"格式化后的文件重新分隔得到的表达式与原来不一致（%d 个 / %d 个），未写回", "the formatted file would not split back into the same expressions (%d / %d), not written"},
+		"fmt.written":            {"已格式化 %s", "formatted %s"},
+		"fmt.load_alphas_failed": {"读取 alpha 列表失败: %v", "failed to load alphas: %v"},
+		"fmt.parse_failed":       {"%s: 无法格式化: %v", "%s: cannot format: %v"},
+		"fmt.check_ok":           {"%d 个表达式均已格式化", "all %d expressions are formatted"},
+		"fmt.check_summary":      {"%d 个表达式需要格式化，%d 个解析失败（共 %d 个）", "%d expressions need formatting, %d failed to parse (%d in total)"},
+	})
+}
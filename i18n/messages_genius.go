@@ -0,0 +1,20 @@
+package i18n
+
+// Genius 等级与操作符 (genius)
+func init() {
+	register(map[string]entry{
+		"genius.no_quarter":          {"operators 表中没有 %s 季度的操作符（已有: %s），请先运行 UpdateOperators", "no operators for quarter %s in the operators table (available: %s), run UpdateOperators first"},
+		"genius.load_alphas_failed":  {"读取 alpha 列表失败: %v", "failed to load alphas: %v"},
+		"genius.report_built":        {"操作符等级报告已生成", "operator level report built"},
+		"genius.report_title":        {"已提交 alpha 依赖的最高等级操作符（账户等级: %s，季度: %s）", "Highest-level operator used by each submitted alpha (account level: %s, quarter: %s)"},
+		"genius.report_empty":        {"没有已提交的 alpha", "no submitted alphas"},
+		"genius.report_header":       {"Alpha ID    类型      地区    操作符                        等级          备注", "Alpha ID    Type      Region  Operator                      Level         Notes"},
+		"genius.report_level_count":  {"%-12s  %d 个 alpha", "%-12s  %d alphas"},
+		"genius.report_locked_count": {"%d 个 alpha 使用了高于 %s 的操作符", "%d alphas use operators above %s"},
+		"genius.note_locked":         {"高于当前等级", "above account level"},
+		"genius.note_unknown":        {"未知操作符: %s", "unknown operators: %s"},
+		"genius.note_parse_error":    {"解析失败: %s", "parse error: %s"},
+		"genius.usage":               {"用法: genius [--level 等级] [--quarter 季度] [--locked]", "usage: genius [--level LEVEL] [--quarter QUARTER] [--locked]"},
+		"genius.flag_locked":         {"只列出使用了高于账户等级操作符的 alpha", "only list alphas that use operators above the account level"},
+	})
+}
@@ -0,0 +1,26 @@
+package i18n
+
+// alpha 指标历史 (history)
+func init() {
+	register(map[string]entry{
+		"history.record_failed": {"记录 alpha 指标快照失败", "failed to record alpha snapshots"},
+		"history.query_failed":  {"查询 alpha 指标快照失败: %v", "failed to query alpha snapshots: %v"},
+		"history.bad_metric":    {"未知指标 %s，可用指标: %s", "unknown metric %s, available: %s"},
+		"history.bad_range":     {"开始日期 %s 必须早于结束日期 %s", "start date %s must be before end date %s"},
+		"history.bad_date":      {"日期格式无效: %s（应为 2006-01-02）", "invalid date: %s (want 2006-01-02)"},
+		"history.empty":         {"Alpha %s 还没有指标快照，执行一次同步后再查看", "no snapshots for alpha %s yet, run a sync first"},
+		"history.title":         {"Alpha %s 指标历史", "Metric history of alpha %s"},
+		"history.header": {
+			"日期        状态                 IS夏普    IS适应度      OS夏普  收盘前夏普    生产相关  金字塔主题",
+			"date        status               sharpe     fitness   os_sharpe   pre_close   prod_corr  pyramid themes",
+		},
+		"history.movers_title":  {"%s 变化排行（%s → %s）", "Biggest %s movers (%s → %s)"},
+		"history.movers_empty":  {"两个日期都有快照的 alpha 为空", "no alpha has snapshots on both dates"},
+		"history.movers_header": {"  #  Alpha            起始       结束       变化  状态", "  #  alpha            from         to     change  status"},
+		"history.usage":         {"用法: history <alpha_id> | history movers --from 日期 --to 日期 [--metric 指标] [--top N]", "usage: history <alpha_id> | history movers --from DATE --to DATE [--metric NAME] [--top N]"},
+		"history.flag_from":     {"开始日期，格式 2006-01-02", "start date, format 2006-01-02"},
+		"history.flag_to":       {"结束日期，格式 2006-01-02，默认今天", "end date, format 2006-01-02, defaults to today"},
+		"history.flag_metric":   {"比较的指标: %s", "metric to compare: %s"},
+		"history.flag_top":      {"显示的 alpha 数量", "number of alphas to show"},
+	})
+}
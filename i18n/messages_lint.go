@@ -0,0 +1,33 @@
+package i18n
+
+// 表达式静态检查 (wqb lint)
+func init() {
+	register(map[string]entry{
+		"lint.empty":                  {"表达式为空", "expression is empty"},
+		"lint.no_result":              {"最后一条语句是对 %s 的赋值，表达式没有结果", "the last statement assigns %s, so the expression has no result"},
+		"lint.overwritten":            {"%s 在 %v 被重新赋值之前没有使用", "%s is reassigned at %v before being used"},
+		"lint.unused":                 {"%s 赋值后没有使用", "%s is assigned but never used"},
+		"lint.shadowed_operator":      {"局部变量 %s 与操作符同名", "local variable %s has the same name as an operator"},
+		"lint.used_before_assign":     {"局部变量 %s 在赋值（%v）之前使用", "local variable %s is used before it is assigned at %v"},
+		"lint.call_variable":          {"%s 是局部变量，不能调用", "%s is a local variable and cannot be called"},
+		"lint.unknown_operator":       {"未知的操作符 %s", "unknown operator %s"},
+		"lint.scope":                  {"操作符 %s 不能用于 %s 类型的 alpha（允许: %s）", "operator %s is not allowed in %s alphas (allowed: %s)"},
+		"lint.genius_level":           {"操作符 %s 需要 Genius 等级 %s，高于当前账户等级", "operator %s requires Genius level %s, above the account's level"},
+		"lint.positional_after_named": {"%s 的位置参数出现在命名参数之后", "positional argument to %s after a named argument"},
+		"lint.unknown_parameter":      {"%s 没有命名参数 %s（可用: %s）", "%s has no named parameter %s (known: %s)"},
+		"lint.duplicate_parameter":    {"%s 的命名参数 %s 重复", "%s: named parameter %s given twice"},
+		"lint.args_exact":             {"%s 需要 %d 个参数，实际 %d 个；定义: %s", "%s expects %d arguments, got %d; definition: %s"},
+		"lint.args_min":               {"%s 至少需要 %d 个参数，实际 %d 个；定义: %s", "%s expects at least %d arguments, got %d; definition: %s"},
+		"lint.args_max":               {"%s 最多 %d 个参数，实际 %d 个；定义: %s", "%s expects at most %d arguments, got %d; definition: %s"},
+		"lint.no_operators":           {"数据库中没有操作符，请先运行 UpdateOperators", "no operators in the database, run UpdateOperators first"},
+		"lint.load_operators_failed":  {"读取操作符失败: %v", "failed to load operators: %v"},
+		"lint.read_input_failed":      {"读取表达式失败: %v", "failed to read expression: %v"},
+		"lint.usage":                  {"用法: lint [--scope REGULAR|COMBO|SELECTION] [--level 等级] [--quarter 季度] [--file 文件] <表达式>，表达式为 - 时从标准输入读取", "usage: lint [--scope REGULAR|COMBO|SELECTION] [--level LEVEL] [--quarter QUARTER] [--file path] <expression>, use - to read from stdin"},
+		"lint.flag_scope":             {"alpha 类型: REGULAR、COMBO 或 SELECTION", "alpha type: REGULAR, COMBO or SELECTION"},
+		"lint.flag_file":              {"从文件读取表达式并分别检查，表达式可以跨行，在语法完整的行尾结束", "lint the expressions in a file; an expression may span lines and ends at the first line where it is complete"},
+		"lint.flag_level":             {"账户的 Genius 等级，默认取配置中的 genius.level", "account Genius level, defaults to genius.level in the config"},
+		"lint.flag_quarter":           {"Genius 季度，默认取配置中的 genius.quarter 或操作符表中最新的季度", "Genius quarter, defaults to genius.quarter in the config or the latest quarter in the operators table"},
+		"lint.ok":                     {"没有发现问题（%d 个操作符）", "no problems found (%d operators)"},
+		"lint.summary":                {"%d 个错误，%d 个警告", "%d errors, %d warnings"},
+	})
+}
@@ -0,0 +1,12 @@
+package i18n
+
+// 日志配置
+func init() {
+	register(map[string]entry{
+		"log.bad_format":   {"log.format 只支持 text 或 json: %q", "log.format must be text or json: %q"},
+		"log.bad_level":    {"未知的日志级别: %q", "unknown log level: %q"},
+		"log.mkdir_failed": {"创建日志目录失败: %v", "failed to create log directory: %v"},
+		"log.open_failed":  {"打开日志文件失败: %v", "failed to open log file: %v"},
+		"log.stat_failed":  {"读取日志文件信息失败: %v", "failed to stat log file: %v"},
+	})
+}
@@ -0,0 +1,71 @@
+package i18n
+
+// 主程序：菜单、确认提示、登录与子命令
+func init() {
+	register(map[string]entry{
+		"main.config.open_failed":  {"无法打开配置文件: %v", "cannot open config file: %v"},
+		"main.config.parse_failed": {"配置文件解析失败: %v", "failed to parse config file: %v"},
+		"main.config.load_failed":  {"加载配置失败", "failed to load config"},
+		"main.log.setup_failed":    {"初始化日志失败", "failed to initialise logging"},
+
+		"main.login.failed":          {"登录失败: %v", "login failed: %v"},
+		"main.login.bad_status":      {"登录失败: 状态码 %d", "login failed: status code %d"},
+		"main.login.decode_failed":   {"解析响应失败: %v", "failed to decode response: %v"},
+		"main.login.success":         {"登录 BRAIN 成功。", "Login to BRAIN successfully."},
+		"main.login.response":        {"登录响应", "login response"},
+		"main.login.no_token":        {"未从 Set-Cookie 中获取到名为 t 的 token", "no token named t found in Set-Cookie"},
+		"main.login.signin_failed":   {"登录 BRAIN 失败", "failed to sign in to BRAIN"},
+		"main.credentials.failed":    {"凭据处理失败", "credential handling failed"},
+		"main.migrate.failed":        {"数据库迁移失败", "migration failed"},
+		"main.db.connect_failed":     {"连接数据库失败", "failed to connect to database"},
+		"main.history.failed":        {"查询 alpha 指标历史失败", "alpha history failed"},
+		"main.lint.failed":           {"表达式检查失败", "lint failed"},
+		"main.genius.failed":         {"生成操作符等级报告失败", "genius report failed"},
+		"main.fmt.failed":            {"表达式格式化失败", "fmt failed"},
+		"main.dupes.failed":          {"查找重复表达式失败", "dupes failed"},
+		"main.fields.failed":         {"字段索引命令失败", "fields failed"},
+		"main.similar.failed":        {"结构相似度查找失败", "similar failed"},
+		"main.opstats.failed":        {"统计操作符使用情况失败", "opstats failed"},
+		"main.profile.invalid":       {"账户配置无效", "invalid profile"},
+		"main.flag.config":           {"配置文件路径", "path to the config file"},
+		"main.flag.profile":          {"使用 profiles 中的指定账户", "use the named account from profiles"},
+		"main.flag.quarter":          {"金字塔快照的季度，格式 YYYY-QN", "quarter for the pyramid snapshot, format YYYY-QN"},
+		"main.flag.all_profiles":     {"依次处理 profiles 中的所有账户", "run for every account in profiles"},
+		"main.snapshot.no_profiles":  {"配置文件中没有 profiles", "no profiles in config file"},
+		"main.snapshot.failed":       {"快照失败", "snapshot failed"},
+		"main.snapshot.failed_count": {"%d/%d 个账户快照失败", "snapshot failed for %d of %d profiles"},
+		"main.program.failed":        {"程序执行失败", "program failed"},
+		"main.command.unknown":       {"未知命令: %s", "unknown command: %s"},
+		"main.command.available":     {"可用命令: %s", "available commands: %s"},
+		"main.server.exited":         {"API 服务退出", "API server exited"},
+		"main.metrics.exited":        {"指标服务退出", "metrics server exited"},
+		"main.menu.title":            {"程序集合控制中心", "Program Collection Control Center"},
+		"main.menu.run_all":          {"运行所有程序", "Run all programs"},
+		"main.menu.run_custom":       {"自定义选择多个程序", "Choose several programs"},
+		"main.menu.serve":            {"启动 HTTP API 服务 (RunAPIServer)", "Start HTTP API server (RunAPIServer)"},
+		"main.menu.exit":             {"退出", "Exit"},
+		"main.menu.prompt":           {"请选择要执行的操作 (0-9): ", "Choose an action (0-9): "},
+		"main.menu.invalid":          {"无效的选择，请输入 0-9 之间的数字！", "Invalid choice, please enter a number between 0 and 9!"},
+		"main.menu.all_programs":     {"所有程序", "all programs"},
+		"main.menu.selected_above":   {"以上程序", "the programs above"},
+		"main.run_all.begin":         {">>>>>>>>>>>>>>>> 开始执行所有程序 <<<<<<<<<<<<<<<<", ">>>>>>>>>>>>>>>> Running all programs <<<<<<<<<<<<<<<<"},
+		"main.run_all.end":           {">>>>>>>>>>>>>>>> 所有程序执行完毕 <<<<<<<<<<<<<<<<", ">>>>>>>>>>>>>>>> All programs finished <<<<<<<<<<<<<<<<"},
+		"main.select.prompt":         {"请选择要运行的程序（输入数字，用空格分隔）:", "Choose the programs to run (numbers separated by spaces):"},
+		"main.select.example":        {"示例: 1 2 3 4 或 1  3", "Example: 1 2 3 4 or 1  3"},
+		"main.select.your_choice":    {"你的选择: ", "Your choice: "},
+		"main.select.invalid":        {"无效的选择: %s，已跳过", "invalid choice: %s, skipped"},
+		"main.select.none":           {"未选择任何程序，返回菜单。", "No program selected, back to the menu."},
+		"main.select.chosen":         {"你选择了以下程序:", "You selected:"},
+		"main.confirm.run":           {"确定要运行 %s 吗？(y/n): ", "Run %s? (y/n): "},
+		"main.confirm.continue":      {"是否继续运行其他程序？(y/n): ", "Run another program? (y/n): "},
+		"main.goodbye":               {"感谢使用，再见！", "Thanks for using, goodbye!"},
+
+		// 程序名称，菜单和确认提示共用
+		"program.1": {"字段使用情况检查 (FieldCheck)", "Field usage check (FieldCheck)"},
+		"program.2": {"相似度检测 (ProdCorrCheck)", "Correlation check (ProdCorrCheck)"},
+		"program.3": {"更新操作符 (UpdateOperators)", "Update operators (UpdateOperators)"},
+		"program.4": {"阿尔法管理 (RunActiveAlphaManagement)", "Alpha management (RunActiveAlphaManagement)"},
+		"program.5": {"权重|因子价值差分 (SaveWeightValueFactor)", "Weight|value factor changes (SaveWeightValueFactor)"},
+		"program.6": {"优先推金字塔 (PyramidAlphaInfo)", "Pyramid priorities (PyramidAlphaInfo)"},
+	})
+}
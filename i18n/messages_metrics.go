@@ -0,0 +1,17 @@
+package i18n
+
+// Prometheus 指标服务
+func init() {
+	register(map[string]entry{
+		"metrics.scrape_failed":      {"读取数据库指标失败", "failed to read database metrics"},
+		"metrics.banner":             {"\n====================== 启动 Prometheus 指标服务 ======================", "\n====================== Starting Prometheus metrics server ======================"},
+		"metrics.bad_interval":       {"metrics.syncInterval 格式错误: %q", "invalid metrics.syncInterval: %q"},
+		"metrics.listening":          {"指标服务监听于 %s/metrics\n", "metrics server listening on %s/metrics\n"},
+		"metrics.sync_alphas_failed": {"定时获取新的 Alpha 失败", "scheduled alpha fetch failed"},
+		"metrics.sync_factor_failed": {"定时记录 Weight|Value_factor 失败", "scheduled weight/value factor snapshot failed"},
+		"metrics.signin_failed":      {"定时同步前重新登录 BRAIN 失败，沿用之前的会话", "failed to sign in to BRAIN before the scheduled sync, keeping the previous session"},
+		"metrics.shutting_down":      {"收到退出信号，等待正在进行的同步结束（再次 Ctrl+C 立即退出）", "shutting down, waiting for the running sync to finish (press Ctrl+C again to exit immediately)"},
+		"metrics.shutdown_failed":    {"关闭指标服务失败: %v", "failed to shut down the metrics server: %v"},
+		"metrics.stopped":            {"指标服务已停止", "metrics server stopped"},
+	})
+}
@@ -0,0 +1,24 @@
+package i18n
+
+// 数据库迁移 (migrate)
+func init() {
+	register(map[string]entry{
+		"migrate.unknown_dialect":   {"没有 %s 方言的迁移", "no migrations for dialect %s"},
+		"migrate.bad_file_name":     {"迁移文件名无效: %s（应为 NNNN_名称.up.sql / .down.sql）", "invalid migration file name: %s (want NNNN_name.up.sql / .down.sql)"},
+		"migrate.duplicate_version": {"迁移版本 %d 重复", "duplicate migration version %d"},
+		"migrate.missing_up":        {"迁移 %d 缺少 up 脚本", "migration %d has no up script"},
+		"migrate.missing_down":      {"迁移 %d 缺少 down 脚本，无法回滚", "migration %d has no down script and cannot be rolled back"},
+		"migrate.table_failed":      {"读取 schema_migrations 失败: %v", "failed to read schema_migrations: %v"},
+		"migrate.up_failed":         {"执行迁移 %04d_%s 失败: %v", "migration %04d_%s failed: %v"},
+		"migrate.down_failed":       {"回滚迁移 %04d_%s 失败: %v", "rollback of %04d_%s failed: %v"},
+		"migrate.applied":           {"已执行 %04d_%s", "applied %04d_%s"},
+		"migrate.rolled_back":       {"已回滚 %04d_%s", "rolled back %04d_%s"},
+		"migrate.up_to_date":        {"数据库已是最新版本", "database is up to date"},
+		"migrate.nothing_to_undo":   {"没有可回滚的迁移", "nothing to roll back"},
+		"migrate.status_applied":    {"%04d_%-30s 已执行 %s", "%04d_%-30s applied %s"},
+		"migrate.status_pending":    {"%04d_%-30s 未执行", "%04d_%-30s pending"},
+		"migrate.usage":             {"用法: migrate [up [--to 版本] | down [--steps N] | status]", "usage: migrate [up [--to VERSION] | down [--steps N] | status]"},
+		"migrate.flag_to":           {"迁移到的目标版本，0 表示最新", "target version, 0 means latest"},
+		"migrate.flag_steps":        {"回滚的迁移个数", "number of migrations to roll back"},
+	})
+}
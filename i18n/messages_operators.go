@@ -0,0 +1,55 @@
+package i18n
+
+// 更新操作符 (UpdateOperators)
+func init() {
+	register(map[string]entry{
+		"operators.db_instance_failed":   {"获取数据库实例失败: %v", "failed to get database instance: %v"},
+		"operators.db_connected":         {"数据库连接成功", "database connected"},
+		"operators.scope_marshal_failed": {"序列化 Scope 失败: %v", "failed to marshal scope: %v"},
+		"operators.insert_failed":        {"插入数据失败: %v", "insert failed: %v"},
+		"operators.inserted":             {"插入操作符记录", "inserted operator records"},
+		"operators.clear_failed":         {"清空表数据失败: %v", "failed to clear table: %v"},
+		"operators.cleared":              {"已清空操作符表", "operators table cleared"},
+		"operators.bad_level":            {"'%s' 不是有效的Genius等级，可选值: %s", "'%s' is not a valid Genius level, choose from: %s"},
+		"operators.bad_quarter":          {"Genius季度格式不正确: %s", "invalid Genius quarter: %s"},
+		"operators.level_prompt":         {"请输入Genius等级 (必填), 可选值: Gold, Expert, Master, Grand Master", "Enter the Genius level (required), one of: Gold, Expert, Master, Grand Master"},
+		"operators.input":                {"请输入: ", "Input: "},
+		"operators.level_empty":          {"❌ 错误: Genius等级不能为空，请重新输入", "❌ Error: Genius level must not be empty, please try again"},
+		"operators.level_invalid":        {"❌ 错误: '%s' 不是有效的Genius等级，请选择: Gold, Expert, Master, Grand Master\n", "❌ Error: '%s' is not a valid Genius level, choose from: Gold, Expert, Master, Grand Master\n"},
+		"operators.level_entered":        {"\n您输入的Genius等级是: %s\n", "\nYou entered Genius level: %s\n"},
+		"operators.confirm":              {"确认吗? (y/n): ", "Confirm? (y/n): "},
+		"operators.read_confirm_failed":  {"读取确认输入失败", "failed to read confirmation"},
+		"operators.level_set":            {"✅ Genius等级设置完成", "✅ Genius level set"},
+		"operators.level_retry":          {"重新输入Genius等级...", "Re-enter the Genius level..."},
+		"operators.confirm_invalid":      {"❌ 无效的确认输入，请输入 y/n 或 是/否", "❌ Invalid confirmation, please enter y or n"},
+		"operators.quarter_prompt":       {"请输入Genius季度 (必填):", "Enter the Genius quarter (required):"},
+		"operators.quarter_format":       {"格式: YYYY-Q[1-4] (例如: %s)\n", "Format: YYYY-Q[1-4] (e.g. %s)\n"},
+		"operators.quarter_empty":        {"❌ 错误: Genius季度不能为空，请重新输入", "❌ Error: Genius quarter must not be empty, please try again"},
+		"operators.quarter_invalid":      {"❌ 错误: 季度格式不正确", "❌ Error: invalid quarter format"},
+		"operators.quarter_examples":     {"正确格式: YYYY-Q[1-4] (例如: 2025-Q1, 2025-Q2, 2025-Q3, 2025-Q4)", "Valid format: YYYY-Q[1-4] (e.g. 2025-Q1, 2025-Q2, 2025-Q3, 2025-Q4)"},
+		"operators.quarter_parse_failed": {"❌ 错误: 季度格式解析失败", "❌ Error: failed to parse quarter"},
+		"operators.year_digits":          {"❌ 错误: 年份必须是4位数字", "❌ Error: year must have 4 digits"},
+		"operators.year_range":           {"❌ 错误: 年份必须在 2000 到 2100 之间", "❌ Error: year must be between 2000 and 2100"},
+		"operators.quarter_range":        {"❌ 错误: 季度必须是 1-4 之间的数字", "❌ Error: quarter must be a number between 1 and 4"},
+		"operators.quarter_entered":      {"\n您输入的Genius季度是: %s\n", "\nYou entered Genius quarter: %s\n"},
+		"operators.quarter_set":          {"✅ Genius季度设置完成", "✅ Genius quarter set"},
+		"operators.quarter_retry":        {"重新输入Genius季度...", "Re-enter the Genius quarter..."},
+		"operators.banner":               {"\n====================== 执行更新操作符 ======================", "\n====================== Update operators ======================"},
+		"operators.check_failed":         {"检查数据失败: %v", "failed to check data: %v"},
+		"operators.reimport_prompt":      {"数据库已有数据，是否清空重新导入？(y/n): ", "The table already has data. Clear and re-import? (y/n): "},
+		"operators.clear_table_failed":   {"清空表失败: %v", "failed to clear table: %v"},
+		"operators.fetched":              {"成功获取 %d 个操作符\n", "Fetched %d operators\n"},
+		"operators.level_failed":         {"获取Genius等级失败: %v", "failed to get Genius level: %v"},
+		"operators.quarter_failed":       {"获取Genius季度失败: %v", "failed to get Genius quarter: %v"},
+		"operators.final_title":          {"                  最终配置确认", "                  Final confirmation"},
+		"operators.final_level":          {"Genius等级: %s\n", "Genius level: %s\n"},
+		"operators.final_quarter":        {"Genius季度: %s\n", "Genius quarter: %s\n"},
+		"operators.final_prompt":         {"\n确认使用以上配置保存到数据库吗? (y/n): ", "\nSave to the database with the settings above? (y/n): "},
+		"operators.final_read_failed":    {"读取最终确认失败", "failed to read final confirmation"},
+		"operators.final_cancelled":      {"❌ 操作已取消", "❌ Operation cancelled"},
+		"operators.final_invalid":        {"❌ 无效输入，请输入 y/n 或 是/否", "❌ Invalid input, please enter y or n"},
+		"operators.saving":               {"\n正在保存到数据库...", "\nSaving to the database..."},
+		"operators.save_failed":          {"保存到数据库失败: %v", "failed to save to the database: %v"},
+		"operators.done":                 {"更新操作符完成！", "Operators updated!"},
+	})
+}
@@ -0,0 +1,23 @@
+package i18n
+
+// 操作符使用统计 (opstats)
+func init() {
+	register(map[string]entry{
+		"opstats.load_alphas_failed": {"读取 alpha 列表失败: %v", "failed to load alphas: %v"},
+		"opstats.built":              {"已统计操作符使用情况", "counted operator usage"},
+		"opstats.title":              {"已提交 alpha %d 个，操作符列表季度 %s，用到表中操作符 %d 个，表外操作符 %d 个", "%d submitted alphas, operator list of %s, %d listed operators used, %d unlisted operators"},
+		"opstats.unparsable":         {"%d 段代码无法解析: %s", "%d codes cannot be parsed: %s"},
+		"opstats.usage_header":       {"操作符                        alpha数  调用数    sharpe  sharpe差  相关系数   fitness  fitness差  相关系数", "operator                      alphas   calls    sharpe    s.lift    s.corr   fitness    f.lift    f.corr"},
+		"opstats.by_header":          {"按 %s 统计使用各操作符的 alpha 数", "alphas using each operator by %s"},
+		"opstats.top_sharpe":         {"与 IS sharpe 正相关最强的操作符（至少 %d 个 alpha 使用；相关系数  操作符  alpha数）", "operators most positively correlated with IS sharpe (used by at least %d alphas; corr  operator  alphas)"},
+		"opstats.top_fitness":        {"与 IS fitness 正相关最强的操作符（至少 %d 个 alpha 使用；相关系数  操作符  alpha数）", "operators most positively correlated with IS fitness (used by at least %d alphas; corr  operator  alphas)"},
+		"opstats.rank_empty":         {"   （无）", "   (none)"},
+		"opstats.unknown":            {"不在操作符列表中: %s", "not in the operator list: %s"},
+		"opstats.never_used":         {"可用但从未使用的操作符 %d 个:", "%d available operators never used:"},
+		"opstats.usage":              {"用法: opstats [--by region|quarter|type] [--min-alphas 5] [--type 类型] [--region 地区] [--level 等级] [--quarter 季度]", "usage: opstats [--by region|quarter|type] [--min-alphas 5] [--type TYPE] [--region REGION] [--level LEVEL] [--quarter QUARTER]"},
+		"opstats.flag_by":            {"按维度分组输出: region、quarter 或 type", "break usage down by region, quarter or type"},
+		"opstats.flag_min_alphas":    {"参与相关系数排行的最少 alpha 数", "minimum number of alphas for the correlation ranking"},
+		"opstats.flag_type":          {"只统计此类型的 alpha: REGULAR 或 SUPER", "only count alphas of this type: REGULAR or SUPER"},
+		"opstats.flag_region":        {"只统计此地区的 alpha，如 USA", "only count alphas of this region, e.g. USA"},
+	})
+}
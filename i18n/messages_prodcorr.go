@@ -0,0 +1,11 @@
+package i18n
+
+// 相似度检测 (ProdCorrCheck)
+func init() {
+	register(map[string]entry{
+		"prodcorr.banner": {"\n====================== 执行相似度检测 ======================", "\n====================== Production correlation check ======================"},
+		"prodcorr.header": {"\n统计结果如下:\n", "\nStatistics:\n"},
+		"prodcorr.stats":  {"十月共提交 (不包括sa) alpha 数量: %d, prod_corr最小值: %.4f, 最大值: %.4f, 平均值: %.4f\n", "Alphas submitted in October (excluding SA): %d, prod_corr min: %.4f, max: %.4f, avg: %.4f\n"},
+		"prodcorr.done":   {"相似度检测完成！", "Production correlation check finished!"},
+	})
+}
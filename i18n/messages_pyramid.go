@@ -0,0 +1,41 @@
+package i18n
+
+// 优先推金字塔 (PyramidAlphaInfo)
+func init() {
+	register(map[string]entry{
+		"pyramid.bad_quarter":       {"季度格式错误: %v", "invalid quarter: %v"},
+		"pyramid.confirm_header":    {"请确认以下信息:", "Please confirm:"},
+		"pyramid.quarter":           {"季度: %s\n", "Quarter: %s\n"},
+		"pyramid.date_range":        {"日期范围: %s 至 %s\n", "Date range: %s to %s\n"},
+		"pyramid.user":              {"用户ID: %s\n", "User ID: %s\n"},
+		"pyramid.confirm_fetch":     {"是否继续执行数据获取和保存？", "Fetch and save the data?"},
+		"pyramid.fetching":          {"\n正在获取 %s 的数据...\n", "\nFetching data for %s...\n"},
+		"pyramid.fetch_failed":      {"获取金字塔数据失败: %v", "failed to fetch pyramid data: %v"},
+		"pyramid.check_failed":      {"检查已有数据失败: %v", "failed to check existing data: %v"},
+		"pyramid.confirm_update":    {"该季度数据已存在，是否更新？", "Data for this quarter already exists. Update it?"},
+		"pyramid.delete_failed":     {"删除现有数据失败: %v", "failed to delete existing data: %v"},
+		"pyramid.deleted":           {"已删除现有数据，准备重新插入...", "Existing data deleted, re-inserting..."},
+		"pyramid.saved":             {"\n✅ 成功保存 %d 条金字塔Alpha记录\n", "\n✅ Saved %d pyramid alpha records\n"},
+		"pyramid.saved_quarter":     {"   季度: %s\n", "   Quarter: %s\n"},
+		"pyramid.saved_user":        {"   用户: %s\n", "   User: %s\n"},
+		"pyramid.saved_range":       {"   时间: %s 至 %s\n", "   Period: %s to %s\n"},
+		"pyramid.bad_quarter_value": {"季度格式错误: %s", "invalid quarter: %s"},
+		"pyramid.user_required":     {"用户ID不能为空", "user ID must not be empty"},
+		"pyramid.insert_failed":     {"批量插入数据失败: %v", "batch insert failed: %v"},
+		"pyramid.quarter_prompt":    {"\n请输入季度（格式: 年份-QN，例如: 2025-Q3）:", "\nEnter the quarter (format: YEAR-QN, e.g. 2025-Q3):"},
+		"pyramid.bad_format":        {"❌ 格式错误: %s\n", "❌ Invalid format: %s\n"},
+		"pyramid.format_examples":   {"正确格式示例: 2025-Q3, 2024-Q1, 2023-Q4", "Valid examples: 2025-Q3, 2024-Q1, 2023-Q4"},
+		"pyramid.quarter_months":    {"Q1: 1-3月, Q2: 4-6月, Q3: 7-9月, Q4: 10-12月", "Q1: Jan-Mar, Q2: Apr-Jun, Q3: Jul-Sep, Q4: Oct-Dec"},
+		"pyramid.invalid_quarter":   {"无效的季度格式", "invalid quarter format"},
+		"pyramid.quarter_range":     {"季度数字必须在1-4之间", "quarter number must be between 1 and 4"},
+		"pyramid.user_prompt":       {"\n请输入用户ID:", "\nEnter the user ID:"},
+		"pyramid.user_empty":        {"❌ 用户ID不能为空", "❌ User ID must not be empty"},
+		"pyramid.user_entered":      {"您输入的用户ID是: %s\n", "You entered user ID: %s\n"},
+		"pyramid.confirm":           {"是否确认？", "Confirm?"},
+		"pyramid.check_prompt":      {"\n是否检查 %s 用户 %s 季度的现有数据？\n", "\nCheck existing data of user %s for quarter %s?\n"},
+		"pyramid.check_confirm":     {"检查并提示是否更新？", "Check and ask before updating?"},
+		"pyramid.existing":          {"⚠️  数据库中发现 %d 条已存在的 %s 季度数据\n", "⚠️  Found %d existing records for quarter %s\n"},
+		"pyramid.no_existing":       {"✅ 数据库中未找到 %s 季度的现有数据\n", "✅ No existing data for quarter %s\n"},
+		"pyramid.deleted_count":     {"已删除 %d 条现有记录\n", "Deleted %d existing records\n"},
+	})
+}
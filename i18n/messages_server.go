@@ -0,0 +1,16 @@
+package i18n
+
+// HTTP API 服务
+func init() {
+	register(map[string]entry{
+		"server.auth_required":   {"server.token 与 server.username/password 至少需要配置一种认证方式", "at least one of server.token or server.username/password must be configured"},
+		"server.banner":          {"\n====================== 启动 HTTP API 服务 ======================", "\n====================== Starting HTTP API server ======================"},
+		"server.listening":       {"API 服务监听于 %s\n", "API server listening on %s\n"},
+		"server.job_failed":      {"API 程序执行失败", "API job failed"},
+		"server.write_failed":    {"写入响应失败", "failed to write response"},
+		"server.signin_failed":   {"重新登录 BRAIN 失败: %v", "failed to sign in to BRAIN again: %v"},
+		"server.shutting_down":   {"收到退出信号，等待正在运行的程序结束（再次 Ctrl+C 立即退出）", "shutting down, waiting for running programs to finish (press Ctrl+C again to exit immediately)"},
+		"server.shutdown_failed": {"关闭 API 服务失败: %v", "failed to shut down the API server: %v"},
+		"server.stopped":         {"API 服务已停止", "API server stopped"},
+	})
+}
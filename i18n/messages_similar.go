@@ -0,0 +1,19 @@
+package i18n
+
+// 表达式结构相似度 (similar)
+func init() {
+	register(map[string]entry{
+		"similar.load_alphas_failed": {"读取 alpha 列表失败: %v", "failed to load alphas: %v"},
+		"similar.read_input_failed":  {"读取表达式失败: %v", "failed to read expressions: %v"},
+		"similar.index_built":        {"已解析已保存 alpha 的结构", "parsed the structure of stored alphas"},
+		"similar.parse_failed":       {"%s: 表达式无法解析: %v", "%s: cannot parse expression: %v"},
+		"similar.none":               {"%s: 没有结构相似的 alpha", "%s: no structurally similar alphas"},
+		"similar.header":             {"%s: 结构最相似的 %d 个 alpha（得分  ID  类型  提交日期  代码  设置，= 为共有的子表达式）", "%s: %d most similar alphas (score  ID  type  submitted  code  settings, = marks shared sub-expressions)"},
+		"similar.usage":              {"用法: similar [--top 10] [--min 0.2] [--type 类型] [--region 地区] [--file 文件 | <表达式> | -]", "usage: similar [--top 10] [--min 0.2] [--type TYPE] [--region REGION] [--file path | <expression> | -]"},
+		"similar.flag_top":           {"最多列出的 alpha 数量，0 为不限", "maximum number of alphas to list, 0 for no limit"},
+		"similar.flag_min":           {"最低相似度（0~1）", "minimum similarity (0-1)"},
+		"similar.flag_type":          {"只比较此类型的 alpha: REGULAR 或 SUPER", "only compare alphas of this type: REGULAR or SUPER"},
+		"similar.flag_region":        {"只比较此地区的 alpha，如 USA", "only compare alphas of this region, e.g. USA"},
+		"similar.flag_file":          {"从文件读取多个表达式，表达式可以跨行，在语法完整的行尾结束", "read expressions from a file; an expression may span lines and ends at the first line where it is complete"},
+	})
+}
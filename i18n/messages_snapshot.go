@@ -0,0 +1,15 @@
+package i18n
+
+// 每日快照 (snapshot)
+func init() {
+	register(map[string]entry{
+		"snapshot.factor_failed":  {"记录 Weight|Value_factor 失败: %v", "failed to record weight/value factor: %v"},
+		"snapshot.factor_done":    {"已记录 Weight|Value_factor", "weight/value factor recorded"},
+		"snapshot.pyramid_failed": {"刷新金字塔数据失败: %v", "failed to refresh pyramid data: %v"},
+		"snapshot.pyramid_done":   {"已刷新金字塔数据", "pyramid data refreshed"},
+		"snapshot.summary": {
+			"[%s] 用户: %s, 权重因子: %.2f, 价值因子: %.2f, %s 金字塔记录: %d 条",
+			"[%s] user: %s, weight factor: %.2f, value factor: %.2f, %s pyramid records: %d",
+		},
+	})
+}
@@ -2,61 +2,69 @@ package main
 
 import (
 	"bufio"
+	"cmp"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"program-collection/credentials"
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+	"program-collection/migrations"
 	"program-collection/models"
 	sp "program-collection/small_program"
 
 	"gopkg.in/yaml.v2"
-	"gorm.io/gorm"
 )
 
 // 1. 加载配置文件
-func loadConfig() models.Config {
-	file, err := os.Open("configs/config.yaml")
+func loadConfig(path string) (models.Config, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		log.Fatalf("无法打开配置文件: %v", err)
+		return models.Config{}, i18n.Errorf("main.config.open_failed", err)
 	}
 	defer file.Close()
 
 	var config models.Config
 	decoder := yaml.NewDecoder(file)
 	if err := decoder.Decode(&config); err != nil {
-		log.Fatalf("配置文件解析失败: %v", err)
+		return models.Config{}, i18n.Errorf("main.config.parse_failed", err)
 	}
-	return config
+	return config, nil
 }
 
 // 2. 登录并获取 token
-func globalSignIn(config models.Config) string {
+func globalSignIn(config models.Config) (string, error) {
 	// 用户登录
 	req, _ := http.NewRequest("POST", fmt.Sprintf("%s%s", config.Third.Addr, config.Paths.Auth), nil)
 	req.SetBasicAuth(config.Login.Username, config.Login.Password)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil || resp.StatusCode != 201 {
-		log.Fatalf("登录失败: %v", err)
+	resp, err := sp.DoBrainRequest("authentication", req)
+	if err != nil {
+		return "", i18n.Errorf("main.login.failed", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", i18n.Errorf("main.login.bad_status", resp.StatusCode)
+	}
 
 	// 解析 JSON 响应
 	var authResp models.AuthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		log.Fatalf("解析响应失败: %v", err)
+		return "", i18n.Errorf("main.login.decode_failed", err)
 	}
 
-	// 打印结果
-	fmt.Println("Login to BRAIN successfully.")
-	data, err := json.Marshal(authResp)
-	if err != nil {
-		log.Fatalf("序列化 JSON 失败: %v", err)
-	}
-	fmt.Println(string(data))
+	// 打印结果，响应中的权限列表等信息不输出
+	fmt.Println(i18n.T("main.login.success"))
+	slog.Debug(i18n.T("main.login.response"), "user_id", authResp.User.ID, "expiry", authResp.Token.Expiry)
 
 	// 从 Set-Cookie 中查找名为 "t" 的 token
 	var token string
@@ -67,28 +75,34 @@ func globalSignIn(config models.Config) string {
 		}
 	}
 	if token == "" {
-		log.Fatal("未从 Set-Cookie 中获取到名为 t 的 token")
+		return "", i18n.Errorf("main.login.no_token")
 	}
 
-	return strings.TrimPrefix(token, "Bearer ")
+	// 会话 token 与密码一样不能出现在日志和输出中
+	token = strings.TrimPrefix(token, "Bearer ")
+	credentials.RegisterSecret(token)
+	return token, nil
 }
 
 // 3. 显示菜单
 func showMenu() {
 	fmt.Println("\n============================================")
-	fmt.Println("         程序集合控制中心")
+	fmt.Println("         " + i18n.T("main.menu.title"))
 	fmt.Println("============================================")
-	fmt.Println("1. 字段使用情况检查 (FieldCheck)")
-	fmt.Println("2. 相似度检测 (ProdCorrCheck)")
-	fmt.Println("3. 更新操作符 (UpdateOperators)")
-	fmt.Println("4. 阿尔法管理 (RunActiveAlphaManagement)")
-	fmt.Println("5. 权重|因子价值差分 (SaveWeightValueFactor)")
-	fmt.Println("6. 优先推金字塔 (PyramidAlphaInfo)")
-	fmt.Println("7. 运行所有程序")
-	fmt.Println("8. 自定义选择多个程序")
-	fmt.Println("0. 退出")
+	for i := 1; i <= 6; i++ {
+		fmt.Printf("%d. %s\n", i, programName(i))
+	}
+	fmt.Println("7. " + i18n.T("main.menu.run_all"))
+	fmt.Println("8. " + i18n.T("main.menu.run_custom"))
+	fmt.Println("9. " + i18n.T("main.menu.serve"))
+	fmt.Println("0. " + i18n.T("main.menu.exit"))
 	fmt.Println("============================================")
-	fmt.Print("请选择要执行的操作 (0-8): ")
+	fmt.Print(i18n.T("main.menu.prompt"))
+}
+
+// 菜单编号对应的程序名称
+func programName(selection int) string {
+	return i18n.T(fmt.Sprintf("program.%d", selection))
 }
 
 // 4. 获取用户输入
@@ -99,57 +113,52 @@ func getUserInput() string {
 }
 
 // 5. 运行所有程序
-func runAllPrograms(config models.Config, token string) {
-	fmt.Println("\n>>>>>>>>>>>>>>>> 开始执行所有程序 <<<<<<<<<<<<<<<<")
-
-	sp.FieldCheck(config, token)
-	fmt.Println()
-
-	sp.ProdCorrCheck(config, token)
-	fmt.Println()
-
-	sp.UpdateOperators(config, token)
-	fmt.Println()
-
-	sp.RunActiveAlphaManagement(config, token)
-	fmt.Println()
+func runAllPrograms(deps sp.Deps) {
+	fmt.Println("\n" + i18n.T("main.run_all.begin"))
 
-	sp.SaveWeightValueFactor(config, token)
-	fmt.Println()
+	runSelectedPrograms(deps, []int{1, 2, 3, 4, 5, 6})
 
-	sp.PyramidAlphaInfo(config, token)
-
-	fmt.Println("\n>>>>>>>>>>>>>>>> 所有程序执行完毕 <<<<<<<<<<<<<<<<")
+	fmt.Println("\n" + i18n.T("main.run_all.end"))
 }
 
 // 6. 运行选择的程序
-func runSelectedPrograms(config models.Config, token string, selections []int) {
+func runSelectedPrograms(deps sp.Deps, selections []int) {
 	for i, selection := range selections {
-		switch selection {
-		case 1:
-			sp.FieldCheck(config, token)
-		case 2:
-			sp.ProdCorrCheck(config, token)
-		case 3:
-			sp.UpdateOperators(config, token)
-		case 4:
-			sp.RunActiveAlphaManagement(config, token)
-		case 5:
-			sp.SaveWeightValueFactor(config, token)
-		case 6:
-			sp.PyramidAlphaInfo(config, token)
-		}
+		runProgram(deps, selection)
 		if i != len(selections)-1 {
 			fmt.Println() // 在程序之间添加空行
 		}
 	}
 }
 
+// 按菜单编号运行单个程序，程序返回的错误只记录日志，不退出交互界面
+func runProgram(deps sp.Deps, selection int) {
+	programs := map[int]struct {
+		name string
+		run  func(sp.Deps) error
+	}{
+		1: {"FieldCheck", sp.FieldCheck},
+		2: {"ProdCorrCheck", func(d sp.Deps) error { return sp.ProdCorrCheck(d.Config, d.Token) }},
+		3: {"UpdateOperators", sp.UpdateOperators},
+		4: {"RunActiveAlphaManagement", sp.RunActiveAlphaManagement},
+		5: {"SaveWeightValueFactor", sp.SaveWeightValueFactor},
+		6: {"PyramidAlphaInfo", sp.PyramidAlphaInfo},
+	}
+
+	program, ok := programs[selection]
+	if !ok {
+		return
+	}
+	if err := program.run(deps); err != nil {
+		slog.Error(i18n.T("main.program.failed"), "program", program.name, "error", err)
+	}
+}
+
 // 7. 获取多个选择
 func getMultipleSelections() []int {
-	fmt.Println("\n请选择要运行的程序（输入数字，用空格分隔）:")
-	fmt.Println("示例: 1 2 3 4 或 1  3")
-	fmt.Print("你的选择: ")
+	fmt.Println("\n" + i18n.T("main.select.prompt"))
+	fmt.Println(i18n.T("main.select.example"))
+	fmt.Print(i18n.T("main.select.your_choice"))
 
 	input := getUserInput()
 	if input == "" {
@@ -161,8 +170,8 @@ func getMultipleSelections() []int {
 
 	for _, part := range parts {
 		num, err := strconv.Atoi(part)
-		if err != nil || num < 1 || num > 7 {
-			fmt.Printf("无效的选择: %s，已跳过\n", part)
+		if err != nil || num < 1 || num > 6 {
+			fmt.Println(i18n.T("main.select.invalid", part))
 			continue
 		}
 
@@ -185,41 +194,911 @@ func getMultipleSelections() []int {
 
 // 8. 确认运行
 func confirmRun(programName string) bool {
-	fmt.Printf("确定要运行 %s 吗？(y/n): ", programName)
-	input := strings.ToLower(getUserInput())
-	return input == "y" || input == "yes" || input == "是" || input == "1"
-}
-
-// 9. 初始化数据库连接
-func initDatabase(config models.Config) *gorm.DB {
-
-	// db, err := sp.ConnectDB(config)
-	// if err != nil {
-	// 	log.Fatal("数据库连接失败:", err)
-	// }
-	// defer func() {
-	// 	sqlDB, _ := db.DB()
-	// 	sqlDB.Close()
-	// }()
+	fmt.Print(i18n.T("main.confirm.run", programName))
+	return i18n.IsYes(getUserInput())
+}
+
+// 9. 初始化数据库连接，创建各程序共用的依赖，返回的 close 用于关闭连接池
+func initDeps(config models.Config, token string) (sp.Deps, func(), error) {
+	db, err := sp.ConnectDB(config)
+	if err != nil {
+		return sp.Deps{}, nil, err
+	}
+
+	deps := sp.Deps{
+		Config: config,
+		Token:  token,
+		Repos:  sp.NewGormRepos(db),
+	}
+	closeDB := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+	return deps, closeDB, nil
+}
+
+// 10. 执行命令行子命令，如: program-collection serve | metrics | snapshot
+func runCommand(deps sp.Deps, args []string) {
+	switch args[0] {
+	case "serve":
+		if err := sp.RunAPIServer(deps); err != nil {
+			fatal(i18n.T("main.server.exited"), err)
+		}
+	case "metrics":
+		if err := sp.RunMetricsServer(deps); err != nil {
+			fatal(i18n.T("main.metrics.exited"), err)
+		}
+	default:
+		fmt.Println(i18n.T("main.command.unknown", args[0]))
+		fmt.Println(i18n.T("main.command.available", "serve, metrics, snapshot, history, lint, fmt, dupes, fields, similar, genius, opstats, credentials, config check, migrate"))
+		os.Exit(2)
+	}
+}
+
+// 11. 每日快照: snapshot [--quarter 2025-Q3] [--all-profiles]
+// 不指定 --all-profiles 时只处理当前账户，否则依次登录 profiles 中的每个账户
+func runSnapshotCommand(config models.Config, profile string, args []string) {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	quarter := fs.String("quarter", sp.CurrentQuarter(time.Now()), i18n.T("main.flag.quarter"))
+	allProfiles := fs.Bool("all-profiles", false, i18n.T("main.flag.all_profiles"))
+	fs.Parse(args)
+
+	profiles := []string{cmp.Or(profile, config.DefaultProfile)}
+	if *allProfiles {
+		profiles = config.ProfileNames()
+		if len(profiles) == 0 {
+			fatal(i18n.T("main.snapshot.no_profiles"), nil)
+		}
+	}
+
+	failed := 0
+	for _, name := range profiles {
+		profileConfig, err := config.WithProfile(name)
+		if err != nil {
+			fatal(i18n.T("main.profile.invalid"), err)
+		}
+
+		token, err := globalSignIn(profileConfig)
+		if err != nil {
+			slog.Error(i18n.T("main.login.signin_failed"), "profile", name, "error", err)
+			failed++
+			continue
+		}
+
+		deps, closeDB, err := initDeps(profileConfig, token)
+		if err != nil {
+			slog.Error(i18n.T("main.db.connect_failed"), "profile", name, "error", err)
+			failed++
+			continue
+		}
+
+		result, err := sp.RunDailySnapshot(context.Background(), deps, *quarter)
+		closeDB()
+		if err != nil {
+			slog.Error(i18n.T("main.snapshot.failed"), "profile", name, "error", err)
+			failed++
+			continue
+		}
+		sp.PrintDailySnapshotResult(name, result)
+	}
+
+	if failed > 0 {
+		fatal(i18n.T("main.snapshot.failed_count", failed, len(profiles)), nil)
+	}
+}
+
+// 12. 管理加密凭据库: credentials set <键> | delete <键> | list
+func runCredentialsCommand(config models.Config, args []string) error {
+	if config.Credentials.Store == "" {
+		return i18n.Errorf("credentials.no_store")
+	}
+	if len(args) == 0 || (args[0] != "list" && len(args) < 2) {
+		fmt.Println(i18n.T("credentials.usage"))
+		os.Exit(2)
+	}
+
+	store, err := openCredentialStore(config.Credentials.Store)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "set":
+		key := args[1]
+		if !credentials.ValidKey(config, key) {
+			return i18n.Errorf("credentials.unknown_key", key)
+		}
+		value, err := credentials.ReadSecret(i18n.T("credentials.value_prompt", key))
+		if err != nil {
+			return err
+		}
+		store.Set(key, value)
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("credentials.saved", key))
+
+	case "delete":
+		if !store.Delete(args[1]) {
+			fmt.Println(i18n.T("credentials.not_found", args[1]))
+			return nil
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("credentials.deleted", args[1]))
+
+	case "list":
+		keys := store.Keys()
+		if len(keys) == 0 {
+			fmt.Println(i18n.T("credentials.empty"))
+		}
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+
+	default:
+		fmt.Println(i18n.T("credentials.usage"))
+		os.Exit(2)
+	}
+	return nil
+}
+
+// 打开凭据库；首次创建时需要输入两次口令
+func openCredentialStore(path string) (*credentials.Store, error) {
+	if _, err := os.Stat(path); err == nil {
+		passphrase, err := credentials.ReadPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return credentials.OpenStore(path, passphrase)
+	}
+
+	passphrase := os.Getenv(credentials.PassphraseEnv)
+	if passphrase == "" {
+		first, err := credentials.ReadSecret(i18n.T("credentials.passphrase_new"))
+		if err != nil {
+			return nil, err
+		}
+		second, err := credentials.ReadSecret(i18n.T("credentials.passphrase_confirm"))
+		if err != nil {
+			return nil, err
+		}
+		if first != second {
+			return nil, i18n.Errorf("credentials.passphrase_differs")
+		}
+		passphrase = first
+	}
+	return credentials.OpenStore(path, passphrase)
+}
+
+// 13. 数据库迁移: migrate [up [--to 版本] | down [--steps N] | status]
+func runMigrateCommand(config models.Config, args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	db, err := sp.ConnectDB(config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}()
+
+	dialect := config.Database.DriverName()
+	switch action {
+	case "up":
+		fs := flag.NewFlagSet("migrate up", flag.ExitOnError)
+		target := fs.Int("to", 0, i18n.T("migrate.flag_to"))
+		fs.Parse(args)
+
+		done, err := migrations.Up(db, dialect, *target)
+		for _, m := range done {
+			fmt.Println(i18n.T("migrate.applied", m.Version, m.Name))
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println(i18n.T("migrate.up_to_date"))
+		}
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, i18n.T("migrate.flag_steps"))
+		fs.Parse(args)
+
+		done, err := migrations.Down(db, dialect, *steps)
+		for _, m := range done {
+			fmt.Println(i18n.T("migrate.rolled_back", m.Version, m.Name))
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println(i18n.T("migrate.nothing_to_undo"))
+		}
+
+	case "status":
+		statuses, err := migrations.List(db, dialect)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				fmt.Println(i18n.T("migrate.status_applied", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05")))
+			} else {
+				fmt.Println(i18n.T("migrate.status_pending", s.Version, s.Name))
+			}
+		}
+
+	default:
+		fmt.Println(i18n.T("migrate.usage"))
+		os.Exit(2)
+	}
+	return nil
+}
+
+// 14. alpha 指标历史: history <alpha_id> | history movers --from 日期 --to 日期 [--metric 指标] [--top N]
+func runHistoryCommand(config models.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(i18n.T("history.usage"))
+		os.Exit(2)
+	}
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	if args[0] != "movers" {
+		snapshots, err := sp.AlphaHistory(ctx, deps.Snapshots, args[0])
+		if err != nil {
+			return err
+		}
+		sp.PrintAlphaHistory(args[0], snapshots)
+		return nil
+	}
+
+	fs := flag.NewFlagSet("history movers", flag.ExitOnError)
+	from := fs.String("from", "", i18n.T("history.flag_from"))
+	to := fs.String("to", time.Now().Format("2006-01-02"), i18n.T("history.flag_to"))
+	metric := fs.String("metric", "os_sharpe", i18n.T("history.flag_metric", strings.Join(sp.SnapshotMetricNames(), ", ")))
+	top := fs.Int("top", 20, i18n.T("history.flag_top"))
+	fs.Parse(args[1:])
+
+	fromDate, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+	if err != nil {
+		return i18n.Errorf("history.bad_date", *from)
+	}
+	toDate, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+	if err != nil {
+		return i18n.Errorf("history.bad_date", *to)
+	}
+
+	movers, err := sp.AlphaMovers(ctx, deps.Snapshots, fromDate, toDate, *metric, *top)
+	if err != nil {
+		return err
+	}
+	sp.PrintAlphaMovers(*metric, fromDate, toDate, movers)
+	return nil
+}
+
+// 15. 表达式静态检查: lint [--scope REGULAR] [--level 等级] [--quarter 季度] [--file 文件] <表达式>，有错误时返回 false
+func runLintCommand(config models.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	scope := fs.String("scope", "REGULAR", i18n.T("lint.flag_scope"))
+	fs.String("file", "", i18n.T("lint.flag_file"))
+	level := fs.String("level", config.Genius.Level, i18n.T("lint.flag_level"))
+	quarter := fs.String("quarter", config.Genius.Quarter, i18n.T("lint.flag_quarter"))
+	fs.Parse(args)
+
+	// 表达式来自 --file、参数或标准输入（参数为 -）
+	input, err := readExpressionInput(fs)
+	if err != nil {
+		return false, err
+	}
+	if input.Name == "" {
+		fmt.Println(i18n.T("lint.usage"))
+		os.Exit(2)
+	}
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return false, err
+	}
+	defer closeDB()
+
+	// 配置了 Genius 等级时只使用该季度的操作符，并标出高于账户等级的操作符
+	var catalog *fastexpr.Catalog
+	if *level != "" {
+		catalog, _, err = sp.GeniusCatalog(context.Background(), deps.Operators, *level, *quarter)
+	} else {
+		catalog, err = sp.OperatorCatalog(context.Background(), deps.Operators)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// --file 中的多个表达式分别检查，结果的行号为文件中的行号
+	var findings []fastexpr.Finding
+	if input.File {
+		for _, expr := range sp.SplitExpressions(input.Text) {
+			for _, finding := range fastexpr.Lint(expr.Text, catalog, *scope) {
+				finding.Pos.Line += expr.Line - 1
+				findings = append(findings, finding)
+			}
+		}
+	} else {
+		findings = fastexpr.Lint(input.Text, catalog, *scope)
+	}
+	sp.PrintLintFindings(findings, catalog)
+	return !fastexpr.HasErrors(findings), nil
+}
+
+// 表达式输入：Name 为 --file 的文件名、<arg> 或 <stdin>，没有输入时为空
+type expressionInput struct {
+	Name string
+	Text string
+	File bool // 来自 --file
+}
+
+// 读取子命令的表达式输入：--file 文件、其余参数拼成的表达式，或参数为 - 时的标准输入；
+// 读取失败时返回 <子命令>.read_input_failed
+func readExpressionInput(fs *flag.FlagSet) (expressionInput, error) {
+	var input expressionInput
+	var data []byte
+	var err error
+	switch file := fs.Lookup("file"); {
+	case file != nil && file.Value.String() != "":
+		input = expressionInput{Name: file.Value.String(), File: true}
+		data, err = os.ReadFile(input.Name)
+	case fs.NArg() == 1 && fs.Arg(0) == "-":
+		input.Name = "<stdin>"
+		data, err = io.ReadAll(os.Stdin)
+	case fs.NArg() > 0:
+		return expressionInput{Name: "<arg>", Text: strings.Join(fs.Args(), " ")}, nil
+	default:
+		return input, nil
+	}
+	if err != nil {
+		return input, i18n.Errorf(fmt.Sprintf("%s.read_input_failed", fs.Name()), err)
+	}
+	input.Text = string(data)
+	return input, nil
+}
+
+// 候选表达式：--file 中的多个表达式（见 SplitExpressions），或参数、标准输入中的一个表达式；没有输入时为空
+func expressionCandidates(input expressionInput) []sp.DuplicateCandidate {
+	switch {
+	case input.File:
+		return sp.CandidatesFromText(input.Name, input.Text)
+	case input.Name != "":
+		return []sp.DuplicateCandidate{{Name: input.Name, Code: input.Text}}
+	}
+	return nil
+}
+
+// 16. 操作符等级报告: genius [--level 等级] [--quarter 季度] [--locked]
+func runGeniusCommand(config models.Config, args []string) error {
+	fs := flag.NewFlagSet("genius", flag.ExitOnError)
+	level := fs.String("level", config.Genius.Level, i18n.T("lint.flag_level"))
+	quarter := fs.String("quarter", config.Genius.Quarter, i18n.T("lint.flag_quarter"))
+	lockedOnly := fs.Bool("locked", false, i18n.T("genius.flag_locked"))
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Println(i18n.T("genius.usage"))
+		os.Exit(2)
+	}
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	report, err := sp.GeniusOperatorReport(context.Background(), deps, *level, *quarter)
+	if err != nil {
+		return err
+	}
+	if *lockedOnly {
+		report.Alphas = slices.DeleteFunc(report.Alphas, func(usage sp.GeniusAlphaUsage) bool { return !usage.Locked })
+	}
+	sp.PrintGeniusReport(report)
 	return nil
 }
 
+// 17. 表达式格式化: fmt [--check] [--stored [--type 类型] | --file 文件 [-w] | <表达式> | -]，
+// 检查模式下有未格式化或解析失败的表达式时返回 false
+func runFmtCommand(config models.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := fs.Bool("check", false, i18n.T("fmt.flag_check"))
+	file := fs.String("file", "", i18n.T("fmt.flag_file"))
+	var write bool
+	fs.BoolVar(&write, "w", false, i18n.T("fmt.flag_write"))
+	stored := fs.Bool("stored", false, i18n.T("fmt.flag_stored"))
+	alphaType := fs.String("type", "", i18n.T("fmt.flag_type"))
+	fs.Parse(args)
+
+	// -w 只能写回 --file 指定的文件，其他输入下静默忽略会让人以为已经写回
+	if write && (*file == "" || *stored) {
+		fmt.Println(i18n.T("fmt.write_needs_file"))
+		fmt.Println(i18n.T("fmt.usage"))
+		os.Exit(2)
+	}
+
+	// 1. 数据库中的 alpha：只输出或检查，不修改
+	if *stored {
+		deps, closeDB, err := initDeps(config, "")
+		if err != nil {
+			return false, err
+		}
+		defer closeDB()
+
+		var filter sp.AlphaFilter
+		if *alphaType != "" {
+			filter.Types = []string{strings.ToUpper(*alphaType)}
+		}
+		results, err := sp.FormatStoredAlphas(context.Background(), deps.Alphas, filter)
+		if err != nil {
+			return false, err
+		}
+		if *check {
+			return sp.PrintFormatCheck(results), nil
+		}
+		return sp.PrintFormatted(results, true), nil
+	}
+
+	input, err := readExpressionInput(fs)
+	if err != nil {
+		return false, err
+	}
+
+	// 2. 文件中的多个表达式（见 SplitExpressions），-w 时写回文件
+	if input.File {
+		results := sp.FormatExpressions(input.Name, input.Text)
+		switch {
+		case *check:
+			return sp.PrintFormatCheck(results), nil
+		case write:
+			formatted, err := sp.JoinExpressions(results)
+			if err != nil {
+				return false, err
+			}
+			if formatted != input.Text {
+				if err := os.WriteFile(input.Name, []byte(formatted), 0o644); err != nil {
+					return false, i18n.Errorf("fmt.write_failed", err)
+				}
+				fmt.Println(i18n.T("fmt.written", input.Name))
+			}
+			for _, result := range results {
+				if result.Err != nil {
+					fmt.Fprintln(os.Stderr, i18n.T("fmt.parse_failed", result.Name, result.Err))
+				}
+			}
+			return !slices.ContainsFunc(results, func(r sp.FormatResult) bool { return r.Err != nil }), nil
+		}
+		return sp.PrintFormatted(results, false), nil
+	}
+
+	// 3. 单个表达式来自参数或标准输入（参数为 -）
+	if input.Name == "" {
+		fmt.Println(i18n.T("fmt.usage"))
+		os.Exit(2)
+	}
+	result := sp.FormatSource(input.Name, input.Text)
+	if *check {
+		return sp.PrintFormatCheck([]sp.FormatResult{result}), nil
+	}
+	return sp.PrintFormatted([]sp.FormatResult{result}, false), nil
+}
+
+// 18. 重复表达式: dupes [--backfill] [--same-settings] [--file 文件 | <表达式> | -]
+// 没有候选表达式时列出已保存 alpha 中的重复组；候选表达式与已保存的 alpha 重复时返回 false
+func runDupesCommand(config models.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("dupes", flag.ExitOnError)
+	backfill := fs.Bool("backfill", false, i18n.T("dupes.flag_backfill"))
+	sameSettings := fs.Bool("same-settings", false, i18n.T("dupes.flag_same_settings"))
+	fs.String("file", "", i18n.T("dupes.flag_file"))
+	fs.Parse(args)
+
+	// 1. 候选表达式来自 --file、参数或标准输入（参数为 -）
+	input, err := readExpressionInput(fs)
+	if err != nil {
+		return false, err
+	}
+	candidates := expressionCandidates(input)
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return false, err
+	}
+	defer closeDB()
+	ctx := context.Background()
+
+	// 2. 先为已有记录补算哈希
+	if *backfill {
+		updated, unparsable, err := sp.BackfillCanonicalHashes(ctx, deps.Alphas)
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(i18n.T("dupes.backfill_done", updated, len(unparsable)))
+	}
+
+	// 3. 比对候选表达式，或列出已保存 alpha 中的重复组
+	if len(candidates) > 0 {
+		results, err := sp.MatchCandidates(ctx, deps.Alphas, candidates)
+		if err != nil {
+			return false, err
+		}
+		return sp.PrintCandidateMatches(results), nil
+	}
+
+	groups, err := sp.FindDuplicateAlphas(ctx, deps.Alphas, *sameSettings)
+	if err != nil {
+		return false, err
+	}
+	sp.PrintDuplicateGroups(groups)
+	return true, nil
+}
+
+// 19. 字段索引: fields --reindex | [--from 日期] [--to 日期] [--type 类型] [--region 地区] [--universe 股票池] [--delay 延迟] <字段>...
+// | --batch 文件|- [--format json|csv] [--output 文件]
+// 重建 alpha_fields，列出范围内使用了给定字段的 alpha，或批量检查文件中的表达式和 Alpha ID
+func runFieldsCommand(config models.Config, args []string) error {
+	fs := flag.NewFlagSet("fields", flag.ExitOnError)
+	reindex := fs.Bool("reindex", false, i18n.T("field.flag_reindex"))
+	batch := fs.String("batch", "", i18n.T("field.flag_batch"))
+	format := fs.String("format", "json", i18n.T("field.flag_format"))
+	output := fs.String("output", "", i18n.T("field.flag_output"))
+	fieldCheck := addFieldCheckFlags(fs, config.FieldCheck)
+	fs.Parse(args)
+
+	if !*reindex && *batch == "" && fs.NArg() == 0 {
+		return i18n.Errorf("field.usage")
+	}
+	if *format != "json" && *format != "csv" {
+		return i18n.Errorf("field.bad_format", *format)
+	}
+	check, err := fieldCheck()
+	if err != nil {
+		return err
+	}
+	scope, err := sp.NewFieldCheckScope(check)
+	if err != nil {
+		return err
+	}
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	ctx := context.Background()
+
+	if *reindex {
+		alphas, rows, err := sp.RebuildAlphaFieldIndex(ctx, deps)
+		if err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("field.reindex_done", alphas, rows))
+	}
+
+	if fs.NArg() > 0 {
+		matches, err := sp.FindAlphasByFields(ctx, deps.Repos, fs.Args(), "", scope)
+		if err != nil {
+			return err
+		}
+		sp.PrintFieldMatches(fs.Args(), matches)
+	}
+
+	if *batch != "" {
+		return runFieldCheckBatch(ctx, deps, scope, *batch, *format, *output)
+	}
+	return nil
+}
+
+// 批量字段检查：输入来自文件或标准输入（-），报告写到 --output 或标准输出
+func runFieldCheckBatch(ctx context.Context, deps sp.Deps, scope sp.FieldCheckScope, input, format, output string) error {
+	var data []byte
+	var err error
+	if input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return i18n.Errorf("field.batch_read_failed", err)
+	}
+	inputs, err := sp.ParseFieldCheckInputs(string(data))
+	if err != nil {
+		return err
+	}
+
+	items, err := sp.BatchFieldCheck(ctx, deps, inputs, scope)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return i18n.Errorf("field.batch_write_failed", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if format == "csv" {
+		return sp.WriteFieldCheckCSV(w, items)
+	}
+	return sp.WriteFieldCheckJSON(w, items)
+}
+
+// 字段检查范围的参数，默认值取自配置中的 fieldCheck，列表以逗号分隔；解析参数后调用返回的函数取得范围
+func addFieldCheckFlags(fs *flag.FlagSet, defaults models.FieldCheck) func() (models.FieldCheck, error) {
+	defaultDelay := ""
+	if defaults.Delay != nil {
+		defaultDelay = strconv.Itoa(*defaults.Delay)
+	}
+	from := fs.String("from", defaults.From, i18n.T("field.flag_from"))
+	to := fs.String("to", defaults.To, i18n.T("field.flag_to"))
+	types := fs.String("type", strings.Join(defaults.Types, ","), i18n.T("field.flag_type"))
+	regions := fs.String("region", strings.Join(defaults.Regions, ","), i18n.T("field.flag_region"))
+	universes := fs.String("universe", strings.Join(defaults.Universes, ","), i18n.T("field.flag_universe"))
+	delay := fs.String("delay", defaultDelay, i18n.T("field.flag_delay"))
+
+	list := func(value string) []string {
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, strings.ToUpper(item))
+			}
+		}
+		return values
+	}
+	return func() (models.FieldCheck, error) {
+		check := models.FieldCheck{From: *from, To: *to, Types: list(*types), Regions: list(*regions), Universes: list(*universes)}
+		if *delay != "" {
+			value, err := strconv.Atoi(*delay)
+			if err != nil {
+				return check, i18n.Errorf("field.bad_delay", *delay)
+			}
+			check.Delay = &value
+		}
+		return check, nil
+	}
+}
+
+// 20. 结构相似度: similar [--top 10] [--min 0.2] [--type 类型] [--region 地区] [--file 文件 | <表达式> | -]
+// 列出与候选表达式语法树结构最相似的已保存 alpha，以及共有的子表达式
+func runSimilarCommand(config models.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("similar", flag.ExitOnError)
+	top := fs.Int("top", 10, i18n.T("similar.flag_top"))
+	minScore := fs.Float64("min", 0.2, i18n.T("similar.flag_min"))
+	alphaType := fs.String("type", "", i18n.T("similar.flag_type"))
+	region := fs.String("region", "", i18n.T("similar.flag_region"))
+	fs.String("file", "", i18n.T("similar.flag_file"))
+	fs.Parse(args)
+
+	// 1. 候选表达式来自 --file、参数或标准输入（参数为 -）
+	input, err := readExpressionInput(fs)
+	if err != nil {
+		return false, err
+	}
+	candidates := expressionCandidates(input)
+	if len(candidates) == 0 {
+		return false, i18n.Errorf("similar.usage")
+	}
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return false, err
+	}
+	defer closeDB()
+
+	// 2. 解析范围内已保存的 alpha 后逐个比较
+	var filter sp.AlphaFilter
+	if *alphaType != "" {
+		filter.Types = []string{strings.ToUpper(*alphaType)}
+	}
+	if *region != "" {
+		filter.Regions = []string{strings.ToUpper(*region)}
+	}
+	index, err := sp.NewSimilarityIndex(context.Background(), deps.Alphas, filter)
+	if err != nil {
+		return false, err
+	}
+	return sp.PrintSimilarityResults(index.SearchCandidates(candidates, *top, *minScore)), nil
+}
+
+// 21. 操作符使用统计: opstats [--by region|quarter|type] [--min-alphas 5] [--type 类型] [--region 地区] [--level 等级] [--quarter 季度]
+func runOperatorStatsCommand(config models.Config, args []string) error {
+	fs := flag.NewFlagSet("opstats", flag.ExitOnError)
+	by := fs.String("by", "", i18n.T("opstats.flag_by"))
+	minAlphas := fs.Int("min-alphas", 5, i18n.T("opstats.flag_min_alphas"))
+	alphaType := fs.String("type", "", i18n.T("opstats.flag_type"))
+	region := fs.String("region", "", i18n.T("opstats.flag_region"))
+	level := fs.String("level", config.Genius.Level, i18n.T("lint.flag_level"))
+	quarter := fs.String("quarter", config.Genius.Quarter, i18n.T("lint.flag_quarter"))
+	fs.Parse(args)
+
+	if *by != "" && !slices.Contains([]string{"region", "quarter", "type"}, *by) {
+		return i18n.Errorf("opstats.usage")
+	}
+
+	deps, closeDB, err := initDeps(config, "")
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	var filter sp.AlphaFilter
+	if *alphaType != "" {
+		filter.Types = []string{strings.ToUpper(*alphaType)}
+	}
+	if *region != "" {
+		filter.Regions = []string{strings.ToUpper(*region)}
+	}
+	stats, err := sp.OperatorUsageStats(context.Background(), deps, filter, *level, *quarter)
+	if err != nil {
+		return err
+	}
+	sp.PrintOperatorStats(stats, *by, *minAlphas)
+	return nil
+}
+
+// 22. 记录错误日志并退出
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, "error", err)
+	} else {
+		slog.Error(msg)
+	}
+	os.Exit(1)
+}
+
 // ---------------------------------------------- main-主程序 --------------------------------------------
 
+// 只读写数据库、不需要登录 BRAIN 的子命令，在切换到 --profile 指定的账户后运行；
+// 返回 false 时以状态码 1 退出（如 lint 发现错误），出错时记录 main.<子命令>.failed
+var offlineCommands = map[string]func(models.Config, []string) (bool, error){
+	"migrate": alwaysOK(runMigrateCommand),       // 数据库迁移
+	"history": alwaysOK(runHistoryCommand),       // 查询指标历史
+	"lint":    runLintCommand,                    // 表达式检查，使用数据库中的操作符
+	"genius":  alwaysOK(runGeniusCommand),        // 操作符等级报告
+	"fmt":     runFmtCommand,                     // 格式化，只在读取已保存的 alpha 时使用数据库
+	"dupes":   runDupesCommand,                   // 重复表达式查找
+	"fields":  alwaysOK(runFieldsCommand),        // 字段索引和字段检查
+	"similar": runSimilarCommand,                 // 结构相似度
+	"opstats": alwaysOK(runOperatorStatsCommand), // 操作符使用统计
+}
+
+// 没有失败状态的子命令
+func alwaysOK(run func(models.Config, []string) error) func(models.Config, []string) (bool, error) {
+	return func(config models.Config, args []string) (bool, error) {
+		return true, run(config, args)
+	}
+}
+
 func main() {
 
+	// 命令行参数: [--config 配置文件] [--profile 账户名] [子命令]
+	configPath := flag.String("config", "configs/config.yaml", i18n.T("main.flag.config"))
+	profile := flag.String("profile", "", i18n.T("main.flag.profile"))
+	flag.Parse()
+
 	// 加载配置
-	// fmt.Println("正在加载配置文件...")
-	config := loadConfig()
-	// fmt.Println("配置文件加载成功！")
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fatal(i18n.T("main.config.load_failed"), err)
+	}
 
-	// 初始化数据库
-	// db := initDatabase(config)
+	// 环境变量覆盖配置
+	overrides, err := credentials.ApplyEnv(&config)
+	if err != nil {
+		fatal(i18n.T("main.config.load_failed"), err)
+	}
+
+	// 设置界面语言，无效的语言由 Validate 与其他问题一起报告
+	_ = i18n.SetLanguage(config.Language)
+
+	// 凭据管理不需要解析现有凭据，也不需要登录
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "credentials" {
+		if err := runCredentialsCommand(config, args[1:]); err != nil {
+			fatal(i18n.T("main.credentials.failed"), err)
+		}
+		return
+	}
+
+	// 读取密钥文件和加密凭据库
+	if err := credentials.Resolve(&config, overrides, credentials.ReadPassphrase); err != nil {
+		fatal(i18n.T("main.credentials.failed"), err)
+	}
+
+	// 校验配置，一次列出所有问题
+	validateErr := config.Validate()
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) < 2 || args[1] != "check" {
+			fmt.Println(i18n.T("main.command.unknown", strings.Join(args, " ")))
+			fmt.Println(i18n.T("main.command.available", "config check"))
+			os.Exit(2)
+		}
+		if validateErr != nil {
+			fmt.Println(credentials.Redact(validateErr.Error()))
+			os.Exit(1)
+		}
+		fmt.Println(i18n.T("config.ok", *configPath))
+		return
+	}
+	if validateErr != nil {
+		fatal(i18n.T("main.config.load_failed"), validateErr)
+	}
+
+	// 初始化日志
+	logCloser, err := sp.SetupLogger(config.Log)
+	if err != nil {
+		fatal(i18n.T("main.log.setup_failed"), err)
+	}
+	defer logCloser.Close()
+
+	// 只读写数据库的子命令不需要登录 BRAIN
+	if len(args) > 0 {
+		if run, ok := offlineCommands[args[0]]; ok {
+			profileConfig, err := config.WithProfile(*profile)
+			if err != nil {
+				fatal(i18n.T("main.profile.invalid"), err)
+			}
+			ok, err := run(profileConfig, args[1:])
+			if err != nil {
+				fatal(i18n.T(fmt.Sprintf("main.%s.failed", args[0])), err)
+			}
+			if !ok {
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// 每日快照会按账户分别登录
+	if len(args) > 0 && args[0] == "snapshot" {
+		runSnapshotCommand(config, *profile, args[1:])
+		return
+	}
+
+	// 切换到指定账户
+	config, err = config.WithProfile(*profile)
+	if err != nil {
+		fatal(i18n.T("main.profile.invalid"), err)
+	}
 
 	// 登录获取token
 	// fmt.Println("\n正在登录获取token...")
-	token := globalSignIn(config)
-	// fmt.Printf("Token 获取成功！\n")
+	token, err := globalSignIn(config)
+	if err != nil {
+		fatal(i18n.T("main.login.signin_failed"), err)
+	}
+
+	// 初始化数据库
+	deps, closeDB, err := initDeps(config, token)
+	if err != nil {
+		fatal(i18n.T("main.db.connect_failed"), err)
+	}
+	defer closeDB()
+	deps.SignIn = func() (string, error) { return globalSignIn(config) }
+
+	// 命令行子命令模式
+	if len(args) > 0 {
+		runCommand(deps, args)
+		return
+	}
 
 	// 主循环
 	for {
@@ -228,80 +1107,75 @@ func main() {
 
 		switch choice {
 		case "0":
-			fmt.Println("感谢使用，再见！")
+			fmt.Println(i18n.T("main.goodbye"))
 			return
 
 		case "1":
-			if confirmRun("字段使用情况检查 (FieldCheck)") {
-				sp.FieldCheck(config, token)
+			if confirmRun(programName(1)) {
+				runProgram(deps, 1)
 			}
 
 		case "2":
-			if confirmRun("相似度检测 (ProdCorrCheck)") {
-				sp.ProdCorrCheck(config, token)
+			if confirmRun(programName(2)) {
+				runProgram(deps, 2)
 			}
 
 		case "3":
-			if confirmRun("更新操作符 (UpdateOperators)") {
-				sp.UpdateOperators(config, token)
+			if confirmRun(programName(3)) {
+				runProgram(deps, 3)
 			}
 
 		case "4":
-			if confirmRun("阿尔法管理 (RunActiveAlphaManagement)") {
-				sp.RunActiveAlphaManagement(config, token)
+			if confirmRun(programName(4)) {
+				runProgram(deps, 4)
 			}
 
 		case "5":
-			if confirmRun("权重|因子价值差分 (SaveWeightValueFactor)") {
-				sp.SaveWeightValueFactor(config, token)
+			if confirmRun(programName(5)) {
+				runProgram(deps, 5)
 			}
 
 		case "6":
-			if confirmRun("优先推金字塔 (PyramidAlphaInfo)") {
-				sp.PyramidAlphaInfo(config, token)
+			if confirmRun(programName(6)) {
+				runProgram(deps, 6)
 			}
 
 		case "7":
-			if confirmRun("所有程序") {
-				runAllPrograms(config, token)
+			if confirmRun(i18n.T("main.menu.all_programs")) {
+				runAllPrograms(deps)
 			}
 
 		case "8":
 			selections := getMultipleSelections()
 			if len(selections) == 0 {
-				fmt.Println("未选择任何程序，返回菜单。")
+				fmt.Println(i18n.T("main.select.none"))
 				continue
 			}
 
-			fmt.Println("\n你选择了以下程序:")
+			fmt.Println("\n" + i18n.T("main.select.chosen"))
 			for _, s := range selections {
-				switch s {
-				case 1:
-					fmt.Println("  - 字段使用情况检查 (FieldCheck)")
-				case 2:
-					fmt.Println("  - 相似度检测 (ProdCorrCheck)")
-				case 3:
-					fmt.Println("  - 更新操作符 (UpdateOperators)")
-				case 4:
-					fmt.Println("  - 阿尔法管理 (RunActiveAlphaManagement)")
-				case 5:
-					fmt.Println("  - 权重|因子价值差分 (SaveWeightValueFactor)")
-				}
+				fmt.Println("  - " + programName(s))
 			}
 
-			if confirmRun("以上程序") {
-				runSelectedPrograms(config, token, selections)
+			if confirmRun(i18n.T("main.menu.selected_above")) {
+				runSelectedPrograms(deps, selections)
+			}
+
+		case "9":
+			if confirmRun(i18n.T("main.menu.serve")) {
+				if err := sp.RunAPIServer(deps); err != nil {
+					slog.Error(i18n.T("main.server.exited"), "error", err)
+				}
 			}
 
 		default:
-			fmt.Println("无效的选择，请输入 0-7 之间的数字！")
+			fmt.Println(i18n.T("main.menu.invalid"))
 		}
 
 		// 询问是否继续
-		fmt.Print("\n是否继续运行其他程序？(y/n): ")
-		cont := strings.ToLower(getUserInput())
-		if cont != "y" && cont != "yes" && cont != "是" && cont != "1" {
-			fmt.Println("感谢使用，再见！")
+		fmt.Print("\n" + i18n.T("main.confirm.continue"))
+		if !i18n.IsYes(getUserInput()) {
+			fmt.Println(i18n.T("main.goodbye"))
 			break
 		}
 	}
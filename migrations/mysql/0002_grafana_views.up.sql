@@ -0,0 +1,40 @@
+-- Grafana 使用的提交统计视图，三种数据库中视图名和列名一致，面板查询视图即可在各数据库间通用
+-- submission_date / submission_month 为 BRAIN 使用的美国东部时间的日期和月份
+
+-- date_submitted 按北京时间保存，夏令时（3月第二个周日 ~ 11月第一个周日）减 12 小时，否则减 13 小时
+CREATE OR REPLACE VIEW v_alpha_submissions AS
+SELECT
+  s.*,
+  DATE(s.submitted_et) AS submission_date,
+  DATE_FORMAT(s.submitted_et, '%Y-%m') AS submission_month
+FROM (
+  SELECT
+    a.*,
+    DATE_ADD(
+      a.date_submitted,
+      INTERVAL CASE
+        WHEN a.date_submitted >=
+             CONCAT(YEAR(a.date_submitted), '-03-01') + INTERVAL
+             ((14 - DAYOFWEEK(CONCAT(YEAR(a.date_submitted), '-03-01'))) % 7) + 7 DAY + INTERVAL 7 HOUR
+         AND a.date_submitted <
+             CONCAT(YEAR(a.date_submitted), '-11-01') + INTERVAL
+             ((7 - DAYOFWEEK(CONCAT(YEAR(a.date_submitted), '-11-01'))) % 7) DAY + INTERVAL 6 HOUR
+        THEN -12
+        ELSE -13
+      END HOUR
+    ) AS submitted_et
+  FROM active_alpha_list a
+  WHERE a.date_submitted IS NOT NULL
+) s;
+
+-- 每日提交数（按类型、地区）
+CREATE OR REPLACE VIEW v_alpha_daily_submissions AS
+SELECT submission_date, type, region, COUNT(*) AS submitted_alphas
+FROM v_alpha_submissions
+GROUP BY submission_date, type, region;
+
+-- 每月提交数（按类型、地区）
+CREATE OR REPLACE VIEW v_alpha_monthly_submissions AS
+SELECT submission_month, type, region, COUNT(*) AS submitted_alphas
+FROM v_alpha_submissions
+GROUP BY submission_month, type, region;
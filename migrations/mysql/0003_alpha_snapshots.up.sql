@@ -0,0 +1,31 @@
+-- alpha 指标快照：每次同步记录一次关键指标，同一 alpha 同一天只保留最后一次
+CREATE TABLE IF NOT EXISTS `alpha_snapshots` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增长',
+  `alpha_id` VARCHAR(50) NOT NULL COMMENT 'Alpha ID',
+  `snapshot_date` DATE NOT NULL COMMENT '快照日期',
+
+  -- 状态
+  `status` VARCHAR(20) COMMENT '状态',
+  `stage` VARCHAR(20) COMMENT '阶段',
+
+  -- IS 指标
+  `is_sharpe` DECIMAL(10,2) COMMENT 'IS夏普比率',
+  `is_fitness` DECIMAL(10,2) COMMENT 'IS适应度',
+  `is_returns` DECIMAL(10,4) COMMENT 'IS收益率',
+  `is_turnover` DECIMAL(10,4) COMMENT 'IS换手率',
+  `is_drawdown` DECIMAL(10,4) COMMENT 'IS回撤',
+  `is_self_correlation` DECIMAL(10,4) COMMENT 'IS自相关',
+  `is_prod_correlation` DECIMAL(10,4) COMMENT 'IS与生产相关',
+
+  -- OS 指标
+  `os_is_sharpe_ratio` DECIMAL(10,4) COMMENT 'OS/IS夏普比率',
+  `os_pre_close_sharpe_ratio` DECIMAL(10,4) COMMENT 'OS前收盘夏普比率',
+
+  `pyramid_themes` JSON COMMENT '金字塔主题',
+
+  `snapshot_time` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '快照时间',
+
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_alpha_date` (`alpha_id`, `snapshot_date`) COMMENT 'Alpha和日期唯一索引',
+  KEY `idx_snapshot_date` (`snapshot_date`) COMMENT '快照日期索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='Alpha指标快照表';
@@ -0,0 +1,14 @@
+-- alpha 字段变化审计：更新模式重新拉取 alpha 时，记录与数据库中已有数据不同的字段
+CREATE TABLE IF NOT EXISTS `alpha_changes` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增长',
+  `alpha_id` VARCHAR(50) NOT NULL COMMENT 'Alpha ID',
+  `field` VARCHAR(64) NOT NULL COMMENT '变化的列名',
+  `old_value` TEXT COMMENT '原值',
+  `new_value` TEXT COMMENT '新值',
+  `detected_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '发现变化的时间',
+
+  PRIMARY KEY (`id`),
+  KEY `idx_alpha_id` (`alpha_id`) COMMENT 'Alpha ID索引',
+  KEY `idx_field` (`field`) COMMENT '列名索引',
+  KEY `idx_detected_at` (`detected_at`) COMMENT '发现时间索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='Alpha字段变化记录表';
@@ -0,0 +1,8 @@
+-- 增量同步水位：记录每种同步已经处理到的修改时间
+CREATE TABLE IF NOT EXISTS `sync_state` (
+  `name` VARCHAR(64) NOT NULL COMMENT '同步名称',
+  `watermark` TIMESTAMP NULL DEFAULT NULL COMMENT '已处理到的修改时间',
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '水位更新时间',
+
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='增量同步水位表';
@@ -0,0 +1,3 @@
+ALTER TABLE `active_alpha_list`
+  DROP KEY `idx_canonical_hash`,
+  DROP COLUMN `canonical_hash`;
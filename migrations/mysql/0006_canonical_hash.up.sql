@@ -0,0 +1,4 @@
+-- 规范化表达式哈希：regular_code 忽略空白、注释、变量名、可交换参数顺序和数字写法后的 SHA-256，用于查找重复 alpha
+ALTER TABLE `active_alpha_list`
+  ADD COLUMN `canonical_hash` CHAR(64) NULL DEFAULT NULL COMMENT '规范化表达式哈希' AFTER `regular_operator_count`,
+  ADD KEY `idx_canonical_hash` (`canonical_hash`) COMMENT '重复表达式查询索引';
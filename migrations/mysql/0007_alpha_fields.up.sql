@@ -0,0 +1,13 @@
+-- alpha 字段倒排索引：同步 alpha 时由 regular_code 提取的数据字段及其所在的操作符路径，字段检查按字段查询
+CREATE TABLE IF NOT EXISTS `alpha_fields` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增长',
+  `alpha_id` VARCHAR(50) NOT NULL COMMENT 'Alpha ID',
+  `field` VARCHAR(255) NOT NULL COMMENT '数据字段',
+  `operator_path` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '从外到内包含该字段的操作符，以/连接',
+  `region` VARCHAR(50) DEFAULT NULL COMMENT '地区',
+  `delay` INT DEFAULT NULL COMMENT '延迟参数',
+
+  PRIMARY KEY (`id`),
+  KEY `idx_alpha_id` (`alpha_id`) COMMENT 'Alpha ID索引',
+  KEY `idx_field` (`field`) COMMENT '字段索引'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='Alpha字段倒排索引表';
@@ -0,0 +1 @@
+ALTER TABLE `alpha_fields` DROP COLUMN `source`;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS combined_alpha_performance;
+DROP TABLE IF EXISTS pyramid_alphas;
+DROP TABLE IF EXISTS weight_value_factor;
+DROP TABLE IF EXISTS active_alpha_list;
+DROP TABLE IF EXISTS operators;
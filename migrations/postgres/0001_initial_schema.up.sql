@@ -0,0 +1,264 @@
+-- 初始表结构（PostgreSQL 版），与 mysql/0001_initial_schema.up.sql 字段一致
+-- JSON 字段使用 JSONB，时间字段使用 TIMESTAMPTZ；索引名在同一 schema 内唯一，因此带上表名前缀
+
+-- operator信息表
+CREATE TABLE IF NOT EXISTS operators (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  category VARCHAR(100) NOT NULL,
+  scope JSONB,
+  definition TEXT,
+  en_description TEXT,
+  cn_description TEXT,
+  documentation TEXT,
+  level VARCHAR(50),
+  genius_level VARCHAR(50),
+  genius_quarter VARCHAR(50),
+
+  -- 三个时间字段
+  create_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  create_date DATE DEFAULT CURRENT_DATE,
+  create_month VARCHAR(7)
+);
+CREATE INDEX IF NOT EXISTS idx_operators_name ON operators (name);
+CREATE INDEX IF NOT EXISTS idx_operators_category ON operators (category);
+CREATE INDEX IF NOT EXISTS idx_operators_level ON operators (level);
+CREATE INDEX IF NOT EXISTS idx_operators_create_time ON operators (create_time);
+CREATE INDEX IF NOT EXISTS idx_operators_create_date ON operators (create_date);
+CREATE INDEX IF NOT EXISTS idx_operators_create_month ON operators (create_month);
+
+-- alpha信息表
+CREATE TABLE IF NOT EXISTS active_alpha_list (
+  id VARCHAR(50) NOT NULL PRIMARY KEY,
+  type VARCHAR(20) NOT NULL,
+  author VARCHAR(50) NOT NULL,
+
+  -- Settings字段
+  instrument_type VARCHAR(50),
+  region VARCHAR(50),
+  universe VARCHAR(100),
+  delay INTEGER,
+  decay INTEGER,
+  neutralization VARCHAR(50),
+  truncation NUMERIC(5,4),
+  pasteurization VARCHAR(20),
+  unit_handling VARCHAR(50),
+  nan_handling VARCHAR(50),
+  selection_handling VARCHAR(50),
+  selection_limit INTEGER,
+  max_trade VARCHAR(20),
+  language VARCHAR(50),
+  visualization BOOLEAN DEFAULT FALSE,
+  start_date DATE,
+  end_date DATE,
+  component_activation VARCHAR(50),
+  test_period VARCHAR(20),
+
+  -- Alpha代码内容
+  combo_code TEXT,
+  combo_description TEXT,
+  combo_operator_count INTEGER,
+
+  selection_code TEXT,
+  selection_description TEXT,
+  selection_operator_count INTEGER,
+
+  regular_code TEXT,
+  regular_description TEXT,
+  regular_operator_count INTEGER,
+
+  -- 基础信息
+  date_created TIMESTAMPTZ,
+  date_submitted TIMESTAMPTZ,
+  date_modified TIMESTAMPTZ,
+  name VARCHAR(255),
+  favorite BOOLEAN DEFAULT FALSE,
+  hidden BOOLEAN DEFAULT FALSE,
+  color VARCHAR(50),
+  category VARCHAR(100),
+
+  -- 标签和分类
+  tags JSONB,
+  classifications JSONB,
+
+  grade VARCHAR(50),
+  stage VARCHAR(20) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+
+  -- IS性能指标
+  is_pnl INTEGER,
+  is_book_size INTEGER,
+  is_long_count INTEGER,
+  is_short_count INTEGER,
+  is_turnover NUMERIC(10,4),
+  is_returns NUMERIC(10,4),
+  is_drawdown NUMERIC(10,4),
+  is_margin NUMERIC(10,6),
+  is_sharpe NUMERIC(10,2),
+  is_fitness NUMERIC(10,2),
+  is_start_date DATE,
+  is_self_correlation NUMERIC(10,4),
+  is_prod_correlation NUMERIC(10,4),
+  is_checks JSONB,
+
+  -- OS信息
+  os_start_date DATE,
+  os_is_sharpe_ratio JSONB,
+  os_pre_close_sharpe_ratio JSONB,
+  os_checks JSONB,
+
+  -- Train性能指标（SUPER类型特有）
+  train_pnl INTEGER,
+  train_book_size INTEGER,
+  train_long_count INTEGER,
+  train_short_count INTEGER,
+  train_turnover NUMERIC(10,4),
+  train_returns NUMERIC(10,4),
+  train_drawdown NUMERIC(10,4),
+  train_margin NUMERIC(10,6),
+  train_sharpe NUMERIC(10,2),
+  train_fitness NUMERIC(10,2),
+  train_start_date DATE,
+
+  -- Test性能指标（SUPER类型特有）
+  test_pnl INTEGER,
+  test_book_size INTEGER,
+  test_long_count INTEGER,
+  test_short_count INTEGER,
+  test_turnover NUMERIC(10,4),
+  test_returns NUMERIC(10,4),
+  test_drawdown NUMERIC(10,4),
+  test_margin NUMERIC(10,6),
+  test_sharpe NUMERIC(10,2),
+  test_fitness NUMERIC(10,2),
+  test_start_date DATE,
+
+  -- 其他字段
+  prod JSONB,
+  competitions JSONB,
+  themes JSONB,
+  pyramids JSONB,
+  pyramid_themes JSONB,
+  team JSONB,
+  osmosis_points JSONB,
+
+  -- 三个时间字段（用于记录数据更新时间）
+  create_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  create_date DATE DEFAULT CURRENT_DATE,
+  create_month VARCHAR(7)
+);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_type ON active_alpha_list (type);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_author ON active_alpha_list (author);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_region ON active_alpha_list (region);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_universe ON active_alpha_list (universe);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_status ON active_alpha_list (status);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_stage ON active_alpha_list (stage);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_favorite ON active_alpha_list (favorite);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_sharpe ON active_alpha_list (is_sharpe);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_fitness ON active_alpha_list (is_fitness);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_date_created ON active_alpha_list (date_created);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_date_submitted ON active_alpha_list (date_submitted);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_create_time ON active_alpha_list (create_time);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_create_date ON active_alpha_list (create_date);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_create_month ON active_alpha_list (create_month);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_type_author ON active_alpha_list (type, author);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_region_universe ON active_alpha_list (region, universe);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_stage_status ON active_alpha_list (stage, status);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_sharpe_fitness ON active_alpha_list (is_sharpe, is_fitness);
+
+-- 顾问 weight | value_factor 数据表
+CREATE TABLE IF NOT EXISTS weight_value_factor (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+
+  -- 基本信息
+  user_id VARCHAR(50) NOT NULL,
+
+  -- 日期标识
+  stat_date DATE NOT NULL,
+
+  -- 因子相关（当天数据）
+  weight_factor NUMERIC(5,2),
+  value_factor NUMERIC(5,2),
+
+  -- 变化量（相比于前有数据的一天）
+  weight_factor_change NUMERIC(6,3),
+  value_factor_change NUMERIC(6,3),
+
+  -- 变化率（相比于前有数据的一天）
+  weight_factor_change_rate NUMERIC(8,4),
+  value_factor_change_rate NUMERIC(8,4),
+
+  -- 其他数据
+  data_fields_used INTEGER DEFAULT 0,
+  submissions_count INTEGER DEFAULT 0,
+  super_alpha_submissions_count INTEGER DEFAULT 0,
+  mean_prod_correlation NUMERIC(5,4),
+  mean_self_correlation NUMERIC(5,4),
+  super_alpha_mean_prod_correlation NUMERIC(5,4),
+  super_alpha_mean_self_correlation NUMERIC(5,4),
+  university VARCHAR(255),
+  country VARCHAR(100),
+  date_started DATE,
+
+  -- 时间字段；PostgreSQL 没有 ON UPDATE，update_time 由 GORM 在更新时写入
+  create_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  update_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_user_date ON weight_value_factor (user_id, stat_date);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_user_id ON weight_value_factor (user_id);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_stat_date ON weight_value_factor (stat_date);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_weight_factor ON weight_value_factor (weight_factor);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_value_factor ON weight_value_factor (value_factor);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_weight_factor_change ON weight_value_factor (weight_factor_change);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_value_factor_change ON weight_value_factor (value_factor_change);
+CREATE INDEX IF NOT EXISTS idx_weight_value_factor_create_time ON weight_value_factor (create_time);
+
+-- 金字塔优先推塔表
+CREATE TABLE IF NOT EXISTS pyramid_alphas (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  user_id VARCHAR(100) NOT NULL,
+  category_id VARCHAR(50),
+  category_name VARCHAR(255),
+  region VARCHAR(100),
+  delay INTEGER DEFAULT 0,
+  alpha_count INTEGER DEFAULT 0,
+
+  -- 新增：季度标签字段
+  quarter_tag VARCHAR(10),
+
+  -- 三个时间字段（与参考表保持一致）
+  create_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  create_date DATE DEFAULT CURRENT_DATE,
+  -- 生成列只能使用 IMMUTABLE 函数，按北京时间取年月（与 MySQL 库的会话时区一致）
+  create_month VARCHAR(7) GENERATED ALWAYS AS (
+    EXTRACT(YEAR FROM create_time AT TIME ZONE 'Asia/Shanghai')::INTEGER::TEXT || '-' ||
+    LPAD(EXTRACT(MONTH FROM create_time AT TIME ZONE 'Asia/Shanghai')::INTEGER::TEXT, 2, '0')
+  ) STORED,
+
+  -- 查询时间范围字段（可根据API参数动态记录）
+  stat_start_date DATE,
+  stat_end_date DATE
+);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_user_id ON pyramid_alphas (user_id);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_category_id ON pyramid_alphas (category_id);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_region ON pyramid_alphas (region);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_quarter_tag ON pyramid_alphas (quarter_tag);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_user_quarter ON pyramid_alphas (user_id, quarter_tag);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_create_time ON pyramid_alphas (create_time);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_create_date ON pyramid_alphas (create_date);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_create_month ON pyramid_alphas (create_month);
+CREATE INDEX IF NOT EXISTS idx_pyramid_alphas_stat_date_range ON pyramid_alphas (stat_start_date, stat_end_date);
+
+-- alpha的Combin表现表
+CREATE TABLE IF NOT EXISTS combined_alpha_performance (
+    id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    value_factor NUMERIC(12, 6),
+    combined_alpha_performance NUMERIC(10, 4),
+    combined_selected_alpha_performance NUMERIC(10, 4),
+    combined_power_pool_alpha_performance NUMERIC(10, 4),
+    genius_level VARCHAR(20),
+    calculation_date DATE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    -- PostgreSQL 没有 ON UPDATE，更新时由程序写入
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS v_alpha_monthly_submissions;
+DROP VIEW IF EXISTS v_alpha_daily_submissions;
+DROP VIEW IF EXISTS v_alpha_submissions;
@@ -0,0 +1,23 @@
+-- Grafana 使用的提交统计视图，与 mysql/0002_grafana_views.up.sql 的视图名和列名一致
+-- date_submitted 为 TIMESTAMPTZ，直接换算到美国东部时间，夏令时由时区库处理
+
+CREATE OR REPLACE VIEW v_alpha_submissions AS
+SELECT
+  a.*,
+  a.date_submitted AT TIME ZONE 'America/New_York' AS submitted_et,
+  (a.date_submitted AT TIME ZONE 'America/New_York')::DATE AS submission_date,
+  TO_CHAR(a.date_submitted AT TIME ZONE 'America/New_York', 'YYYY-MM') AS submission_month
+FROM active_alpha_list a
+WHERE a.date_submitted IS NOT NULL;
+
+-- 每日提交数（按类型、地区）
+CREATE OR REPLACE VIEW v_alpha_daily_submissions AS
+SELECT submission_date, type, region, COUNT(*) AS submitted_alphas
+FROM v_alpha_submissions
+GROUP BY submission_date, type, region;
+
+-- 每月提交数（按类型、地区）
+CREATE OR REPLACE VIEW v_alpha_monthly_submissions AS
+SELECT submission_month, type, region, COUNT(*) AS submitted_alphas
+FROM v_alpha_submissions
+GROUP BY submission_month, type, region;
@@ -0,0 +1,29 @@
+-- alpha 指标快照：每次同步记录一次关键指标，同一 alpha 同一天只保留最后一次
+CREATE TABLE IF NOT EXISTS alpha_snapshots (
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  alpha_id VARCHAR(50) NOT NULL,
+  snapshot_date DATE NOT NULL,
+
+  -- 状态
+  status VARCHAR(20),
+  stage VARCHAR(20),
+
+  -- IS 指标
+  is_sharpe NUMERIC(10,2),
+  is_fitness NUMERIC(10,2),
+  is_returns NUMERIC(10,4),
+  is_turnover NUMERIC(10,4),
+  is_drawdown NUMERIC(10,4),
+  is_self_correlation NUMERIC(10,4),
+  is_prod_correlation NUMERIC(10,4),
+
+  -- OS 指标
+  os_is_sharpe_ratio NUMERIC(10,4),
+  os_pre_close_sharpe_ratio NUMERIC(10,4),
+
+  pyramid_themes JSONB,
+
+  snapshot_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_alpha_snapshots_alpha_date ON alpha_snapshots (alpha_id, snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_alpha_snapshots_snapshot_date ON alpha_snapshots (snapshot_date);
@@ -0,0 +1,6 @@
+-- 增量同步水位：记录每种同步已经处理到的修改时间
+CREATE TABLE IF NOT EXISTS sync_state (
+  name VARCHAR(64) PRIMARY KEY,
+  watermark TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,11 @@
+-- alpha 字段倒排索引：同步 alpha 时由 regular_code 提取的数据字段及其所在的操作符路径，字段检查按字段查询
+CREATE TABLE IF NOT EXISTS alpha_fields (
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  alpha_id VARCHAR(50) NOT NULL,
+  field VARCHAR(255) NOT NULL,
+  operator_path VARCHAR(255) NOT NULL DEFAULT '',
+  region VARCHAR(50),
+  delay INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_alpha_fields_alpha_id ON alpha_fields (alpha_id);
+CREATE INDEX IF NOT EXISTS idx_alpha_fields_field ON alpha_fields (field);
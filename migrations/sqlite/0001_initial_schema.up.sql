@@ -0,0 +1,243 @@
+-- 初始表结构（SQLite 版），与 mysql/0001_initial_schema.up.sql 字段一致
+-- JSON 字段存为 TEXT；索引单独创建；金额类字段使用 NUMERIC
+
+-- operator信息表
+CREATE TABLE IF NOT EXISTS operators (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(255) NOT NULL,
+  category VARCHAR(100) NOT NULL,
+  scope TEXT,
+  definition TEXT,
+  en_description TEXT,
+  cn_description TEXT,
+  documentation TEXT,
+  level VARCHAR(50),
+  genius_level VARCHAR(50),
+  genius_quarter VARCHAR(50),
+
+  -- 三个时间字段
+  create_time DATETIME DEFAULT CURRENT_TIMESTAMP,
+  create_date DATE DEFAULT CURRENT_DATE,
+  create_month VARCHAR(7)
+);
+CREATE INDEX IF NOT EXISTS idx_operators_name ON operators (name);
+CREATE INDEX IF NOT EXISTS idx_operators_category ON operators (category);
+CREATE INDEX IF NOT EXISTS idx_operators_level ON operators (level);
+CREATE INDEX IF NOT EXISTS idx_operators_create_time ON operators (create_time);
+CREATE INDEX IF NOT EXISTS idx_operators_create_date ON operators (create_date);
+CREATE INDEX IF NOT EXISTS idx_operators_create_month ON operators (create_month);
+
+-- alpha信息表
+CREATE TABLE IF NOT EXISTS active_alpha_list (
+  id VARCHAR(50) NOT NULL PRIMARY KEY,
+  type VARCHAR(20) NOT NULL,
+  author VARCHAR(50) NOT NULL,
+
+  -- Settings字段
+  instrument_type VARCHAR(50),
+  region VARCHAR(50),
+  universe VARCHAR(100),
+  delay INTEGER,
+  decay INTEGER,
+  neutralization VARCHAR(50),
+  truncation NUMERIC,
+  pasteurization VARCHAR(20),
+  unit_handling VARCHAR(50),
+  nan_handling VARCHAR(50),
+  selection_handling VARCHAR(50),
+  selection_limit INTEGER,
+  max_trade VARCHAR(20),
+  language VARCHAR(50),
+  visualization BOOLEAN DEFAULT 0,
+  start_date DATE,
+  end_date DATE,
+  component_activation VARCHAR(50),
+  test_period VARCHAR(20),
+
+  -- Alpha代码内容
+  combo_code TEXT,
+  combo_description TEXT,
+  combo_operator_count INTEGER,
+
+  selection_code TEXT,
+  selection_description TEXT,
+  selection_operator_count INTEGER,
+
+  regular_code TEXT,
+  regular_description TEXT,
+  regular_operator_count INTEGER,
+
+  -- 基础信息
+  date_created DATETIME,
+  date_submitted DATETIME,
+  date_modified DATETIME,
+  name VARCHAR(255),
+  favorite BOOLEAN DEFAULT 0,
+  hidden BOOLEAN DEFAULT 0,
+  color VARCHAR(50),
+  category VARCHAR(100),
+
+  -- 标签和分类
+  tags TEXT,
+  classifications TEXT,
+
+  grade VARCHAR(50),
+  stage VARCHAR(20) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+
+  -- IS性能指标
+  is_pnl INTEGER,
+  is_book_size INTEGER,
+  is_long_count INTEGER,
+  is_short_count INTEGER,
+  is_turnover NUMERIC,
+  is_returns NUMERIC,
+  is_drawdown NUMERIC,
+  is_margin NUMERIC,
+  is_sharpe NUMERIC,
+  is_fitness NUMERIC,
+  is_start_date DATE,
+  is_self_correlation NUMERIC,
+  is_prod_correlation NUMERIC,
+  is_checks TEXT,
+
+  -- OS信息
+  os_start_date DATE,
+  os_is_sharpe_ratio TEXT,
+  os_pre_close_sharpe_ratio TEXT,
+  os_checks TEXT,
+
+  -- Train性能指标（SUPER类型特有）
+  train_pnl INTEGER,
+  train_book_size INTEGER,
+  train_long_count INTEGER,
+  train_short_count INTEGER,
+  train_turnover NUMERIC,
+  train_returns NUMERIC,
+  train_drawdown NUMERIC,
+  train_margin NUMERIC,
+  train_sharpe NUMERIC,
+  train_fitness NUMERIC,
+  train_start_date DATE,
+
+  -- Test性能指标（SUPER类型特有）
+  test_pnl INTEGER,
+  test_book_size INTEGER,
+  test_long_count INTEGER,
+  test_short_count INTEGER,
+  test_turnover NUMERIC,
+  test_returns NUMERIC,
+  test_drawdown NUMERIC,
+  test_margin NUMERIC,
+  test_sharpe NUMERIC,
+  test_fitness NUMERIC,
+  test_start_date DATE,
+
+  -- 其他字段
+  prod TEXT,
+  competitions TEXT,
+  themes TEXT,
+  pyramids TEXT,
+  pyramid_themes TEXT,
+  team TEXT,
+  osmosis_points TEXT,
+
+  -- 三个时间字段（用于记录数据更新时间）
+  create_time DATETIME DEFAULT CURRENT_TIMESTAMP,
+  create_date DATE DEFAULT CURRENT_DATE,
+  create_month VARCHAR(7)
+);
+CREATE INDEX IF NOT EXISTS idx_alpha_type ON active_alpha_list (type);
+CREATE INDEX IF NOT EXISTS idx_alpha_author ON active_alpha_list (author);
+CREATE INDEX IF NOT EXISTS idx_alpha_region ON active_alpha_list (region);
+CREATE INDEX IF NOT EXISTS idx_alpha_universe ON active_alpha_list (universe);
+CREATE INDEX IF NOT EXISTS idx_alpha_status ON active_alpha_list (status);
+CREATE INDEX IF NOT EXISTS idx_alpha_stage ON active_alpha_list (stage);
+CREATE INDEX IF NOT EXISTS idx_alpha_favorite ON active_alpha_list (favorite);
+CREATE INDEX IF NOT EXISTS idx_alpha_sharpe ON active_alpha_list (is_sharpe);
+CREATE INDEX IF NOT EXISTS idx_alpha_fitness ON active_alpha_list (is_fitness);
+CREATE INDEX IF NOT EXISTS idx_alpha_date_created ON active_alpha_list (date_created);
+CREATE INDEX IF NOT EXISTS idx_alpha_date_submitted ON active_alpha_list (date_submitted);
+CREATE INDEX IF NOT EXISTS idx_alpha_create_time ON active_alpha_list (create_time);
+CREATE INDEX IF NOT EXISTS idx_alpha_create_date ON active_alpha_list (create_date);
+CREATE INDEX IF NOT EXISTS idx_alpha_create_month ON active_alpha_list (create_month);
+CREATE INDEX IF NOT EXISTS idx_alpha_type_author ON active_alpha_list (type, author);
+CREATE INDEX IF NOT EXISTS idx_alpha_region_universe ON active_alpha_list (region, universe);
+CREATE INDEX IF NOT EXISTS idx_alpha_stage_status ON active_alpha_list (stage, status);
+CREATE INDEX IF NOT EXISTS idx_alpha_sharpe_fitness ON active_alpha_list (is_sharpe, is_fitness);
+
+-- 顾问 weight | value_factor 数据表
+CREATE TABLE IF NOT EXISTS weight_value_factor (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id VARCHAR(50) NOT NULL,
+  stat_date DATE NOT NULL,
+
+  weight_factor NUMERIC,
+  value_factor NUMERIC,
+  weight_factor_change NUMERIC,
+  value_factor_change NUMERIC,
+  weight_factor_change_rate NUMERIC,
+  value_factor_change_rate NUMERIC,
+
+  data_fields_used INTEGER DEFAULT 0,
+  submissions_count INTEGER DEFAULT 0,
+  super_alpha_submissions_count INTEGER DEFAULT 0,
+  mean_prod_correlation NUMERIC,
+  mean_self_correlation NUMERIC,
+  super_alpha_mean_prod_correlation NUMERIC,
+  super_alpha_mean_self_correlation NUMERIC,
+  university VARCHAR(255),
+  country VARCHAR(100),
+  date_started DATE,
+
+  -- SQLite 没有 ON UPDATE，update_time 由 GORM 在更新时写入
+  create_time DATETIME DEFAULT CURRENT_TIMESTAMP,
+  update_time DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_user_date ON weight_value_factor (user_id, stat_date);
+CREATE INDEX IF NOT EXISTS idx_wvf_user_id ON weight_value_factor (user_id);
+CREATE INDEX IF NOT EXISTS idx_wvf_stat_date ON weight_value_factor (stat_date);
+CREATE INDEX IF NOT EXISTS idx_wvf_weight_factor ON weight_value_factor (weight_factor);
+CREATE INDEX IF NOT EXISTS idx_wvf_value_factor ON weight_value_factor (value_factor);
+CREATE INDEX IF NOT EXISTS idx_wvf_create_time ON weight_value_factor (create_time);
+
+-- 金字塔优先推塔表
+CREATE TABLE IF NOT EXISTS pyramid_alphas (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id VARCHAR(100) NOT NULL,
+  category_id VARCHAR(50),
+  category_name VARCHAR(255),
+  region VARCHAR(100),
+  delay INTEGER DEFAULT 0,
+  alpha_count INTEGER DEFAULT 0,
+  quarter_tag VARCHAR(10),
+
+  create_time DATETIME DEFAULT CURRENT_TIMESTAMP,
+  create_date DATE DEFAULT CURRENT_DATE,
+  create_month VARCHAR(7) GENERATED ALWAYS AS (strftime('%Y-%m', create_time)) STORED,
+
+  stat_start_date DATE,
+  stat_end_date DATE
+);
+CREATE INDEX IF NOT EXISTS idx_pyramid_user_id ON pyramid_alphas (user_id);
+CREATE INDEX IF NOT EXISTS idx_pyramid_category_id ON pyramid_alphas (category_id);
+CREATE INDEX IF NOT EXISTS idx_pyramid_region ON pyramid_alphas (region);
+CREATE INDEX IF NOT EXISTS idx_pyramid_quarter_tag ON pyramid_alphas (quarter_tag);
+CREATE INDEX IF NOT EXISTS idx_pyramid_user_quarter ON pyramid_alphas (user_id, quarter_tag);
+CREATE INDEX IF NOT EXISTS idx_pyramid_create_time ON pyramid_alphas (create_time);
+CREATE INDEX IF NOT EXISTS idx_pyramid_create_date ON pyramid_alphas (create_date);
+CREATE INDEX IF NOT EXISTS idx_pyramid_create_month ON pyramid_alphas (create_month);
+CREATE INDEX IF NOT EXISTS idx_pyramid_stat_date_range ON pyramid_alphas (stat_start_date, stat_end_date);
+
+-- alpha的Combin表现表
+CREATE TABLE IF NOT EXISTS combined_alpha_performance (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  value_factor NUMERIC,
+  combined_alpha_performance NUMERIC,
+  combined_selected_alpha_performance NUMERIC,
+  combined_power_pool_alpha_performance NUMERIC,
+  genius_level VARCHAR(20),
+  calculation_date DATE NOT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
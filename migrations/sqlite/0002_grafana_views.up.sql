@@ -0,0 +1,23 @@
+-- Grafana 使用的提交统计视图，与 mysql/0002_grafana_views.up.sql 的视图名和列名一致
+-- SQLite 中 date_submitted 保存 BRAIN 返回的原始文本（带美国东部时间偏移），直接截取即为东部时间
+
+CREATE VIEW IF NOT EXISTS v_alpha_submissions AS
+SELECT
+  a.*,
+  REPLACE(SUBSTR(a.date_submitted, 1, 19), 'T', ' ') AS submitted_et,
+  SUBSTR(a.date_submitted, 1, 10) AS submission_date,
+  SUBSTR(a.date_submitted, 1, 7) AS submission_month
+FROM active_alpha_list a
+WHERE a.date_submitted IS NOT NULL;
+
+-- 每日提交数（按类型、地区）
+CREATE VIEW IF NOT EXISTS v_alpha_daily_submissions AS
+SELECT submission_date, type, region, COUNT(*) AS submitted_alphas
+FROM v_alpha_submissions
+GROUP BY submission_date, type, region;
+
+-- 每月提交数（按类型、地区）
+CREATE VIEW IF NOT EXISTS v_alpha_monthly_submissions AS
+SELECT submission_month, type, region, COUNT(*) AS submitted_alphas
+FROM v_alpha_submissions
+GROUP BY submission_month, type, region;
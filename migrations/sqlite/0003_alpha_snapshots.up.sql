@@ -0,0 +1,26 @@
+-- alpha 指标快照：每次同步记录一次关键指标，同一 alpha 同一天只保留最后一次
+CREATE TABLE IF NOT EXISTS alpha_snapshots (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  alpha_id VARCHAR(50) NOT NULL,
+  snapshot_date DATE NOT NULL,
+
+  status VARCHAR(20),
+  stage VARCHAR(20),
+
+  is_sharpe NUMERIC,
+  is_fitness NUMERIC,
+  is_returns NUMERIC,
+  is_turnover NUMERIC,
+  is_drawdown NUMERIC,
+  is_self_correlation NUMERIC,
+  is_prod_correlation NUMERIC,
+
+  os_is_sharpe_ratio NUMERIC,
+  os_pre_close_sharpe_ratio NUMERIC,
+
+  pyramid_themes TEXT,
+
+  snapshot_time DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_alpha_snapshots_alpha_date ON alpha_snapshots (alpha_id, snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_alpha_snapshots_snapshot_date ON alpha_snapshots (snapshot_date);
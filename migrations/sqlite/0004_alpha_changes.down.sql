@@ -0,0 +1 @@
+DROP TABLE IF EXISTS alpha_changes;
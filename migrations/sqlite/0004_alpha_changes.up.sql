@@ -0,0 +1,12 @@
+-- alpha 字段变化审计：更新模式重新拉取 alpha 时，记录与数据库中已有数据不同的字段
+CREATE TABLE IF NOT EXISTS alpha_changes (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  alpha_id VARCHAR(50) NOT NULL,
+  field VARCHAR(64) NOT NULL,
+  old_value TEXT,
+  new_value TEXT,
+  detected_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_alpha_changes_alpha_id ON alpha_changes (alpha_id);
+CREATE INDEX IF NOT EXISTS idx_alpha_changes_field ON alpha_changes (field);
+CREATE INDEX IF NOT EXISTS idx_alpha_changes_detected_at ON alpha_changes (detected_at);
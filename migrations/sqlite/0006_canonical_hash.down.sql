@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_active_alpha_list_canonical_hash;
+ALTER TABLE active_alpha_list DROP COLUMN canonical_hash;
@@ -0,0 +1,3 @@
+-- 规范化表达式哈希：regular_code 忽略空白、注释、变量名、可交换参数顺序和数字写法后的 SHA-256，用于查找重复 alpha
+ALTER TABLE active_alpha_list ADD COLUMN canonical_hash CHAR(64);
+CREATE INDEX IF NOT EXISTS idx_active_alpha_list_canonical_hash ON active_alpha_list (canonical_hash);
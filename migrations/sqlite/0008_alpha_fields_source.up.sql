@@ -0,0 +1,2 @@
+-- 字段索引记录所在的代码：regular、combo 或 selection（SUPER alpha）；已有记录为 regular，运行 fields --reindex 补全 SUPER alpha
+ALTER TABLE alpha_fields ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'regular';
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+
+	"program-collection/i18n"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// DriverName 返回小写的驱动名，未配置时为 mysql；postgresql 视为 postgres
+func (d Database) DriverName() string {
+	driver := strings.ToLower(strings.TrimSpace(d.Driver))
+	switch driver {
+	case "":
+		return DriverMySQL
+	case "postgresql":
+		return DriverPostgres
+	}
+	return driver
+}
+
+// DSNPassword 返回 DSN 中的密码，解析失败或没有密码时为空
+func (d Database) DSNPassword() string {
+	switch d.DriverName() {
+	case DriverMySQL:
+		if cfg, err := mysql.ParseDSN(d.DSN); err == nil {
+			return cfg.Passwd
+		}
+	case DriverPostgres:
+		if cfg, err := pgconn.ParseConfig(d.DSN); err == nil {
+			return cfg.Password
+		}
+	}
+	return ""
+}
+
+// 把 DSN 切换到指定的库：MySQL 替换库名，Postgres 设置 search_path
+func (d Database) withSchema(schema string) (string, error) {
+	switch d.DriverName() {
+	case DriverMySQL:
+		dsn, err := mysql.ParseDSN(d.DSN)
+		if err != nil {
+			return "", err
+		}
+		dsn.DBName = schema
+		return dsn.FormatDSN(), nil
+	case DriverPostgres:
+		if _, err := pgconn.ParseConfig(d.DSN); err != nil {
+			return "", err
+		}
+		if isPostgresURL(d.DSN) {
+			u, err := url.Parse(d.DSN)
+			if err != nil {
+				return "", err
+			}
+			query := u.Query()
+			query.Set("search_path", schema)
+			u.RawQuery = query.Encode()
+			return u.String(), nil
+		}
+		return d.DSN + " search_path=" + schema, nil
+	default:
+		return "", i18n.Errorf("config.check.schema_driver", d.DriverName())
+	}
+}
+
+func isPostgresURL(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
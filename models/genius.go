@@ -0,0 +1,35 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GeniusLevels 可选的 Genius 等级，由低到高
+var GeniusLevels = []string{"Gold", "Expert", "Master", "Grand Master"}
+
+var geniusQuarterRegex = regexp.MustCompile(`^\d{4}-Q[1-4]$`)
+
+// GeniusRank 返回等级在 GeniusLevels 中的位置，忽略大小写、空格和下划线（GRAND_MASTER 也能识别）；
+// 不是 Genius 等级时返回 -1
+func GeniusRank(level string) int {
+	normalized := normalizeGeniusLevel(level)
+	if normalized == "" {
+		return -1
+	}
+	for i, l := range GeniusLevels {
+		if normalizeGeniusLevel(l) == normalized {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalizeGeniusLevel(level string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(level))
+}
+
+// IsGeniusQuarter 是否为 YYYY-Q[1-4] 格式的季度
+func IsGeniusQuarter(quarter string) bool {
+	return geniusQuarterRegex.MatchString(quarter)
+}
@@ -4,10 +4,22 @@ import "time"
 
 // -------------------------------------- 配置文件结构体 -------------------------------------- //
 type Config struct {
+	Language string   `yaml:"language"` // 界面语言: zh | en，默认 zh
 	Login    Login    `yaml:"login"`
 	Third    Third    `yaml:"third"`
 	Paths    Paths    `yaml:"path"`
 	Database Database `yaml:"database"`
+	Server   Server   `yaml:"server"`
+	Metrics  Metrics  `yaml:"metrics"`
+	Sync     Sync     `yaml:"sync"`
+	Genius   Genius   `yaml:"genius"`
+	Log      Log      `yaml:"log"`
+
+	FieldCheck FieldCheck `yaml:"fieldCheck"` // 字段检查的默认范围
+
+	Profiles       map[string]Profile `yaml:"profiles"`       // 多账户配置，通过 --profile 选择
+	DefaultProfile string             `yaml:"defaultProfile"` // 不带 --profile 时使用的账户，顶层 login 为空时必填
+	Credentials    Credentials        `yaml:"credentials"`    // 加密的本地凭据库
 }
 
 type Third struct {
@@ -15,9 +27,11 @@ type Third struct {
 	Addr string `yaml:"addr"`
 }
 
+// Login 登录信息；password_file 不为空时从该文件读取密码
 type Login struct {
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password" secret:"true"`
+	PasswordFile string `yaml:"password_file"`
 }
 
 type Paths struct {
@@ -29,12 +43,100 @@ type Paths struct {
 	Consultant string `yaml:"consultant"`
 }
 
+// Database 数据库配置；dsn_file 不为空时从该文件读取 DSN。
+// driver 为 mysql（默认）、postgres 或 sqlite，sqlite 时 DSN 为数据库文件路径
 type Database struct {
-	DSN          string `yaml:"dsn"`
+	Driver       string `yaml:"driver"`
+	DSN          string `yaml:"dsn" secret:"true"`
+	DSNFile      string `yaml:"dsn_file"`
 	MaxOpenConns int    `yaml:"maxOpenConns"`
 	MaxIdleConns int    `yaml:"maxIdleConns"`
 }
 
+// Server HTTP API 服务配置，token 与 username/password 至少配置一种
+type Server struct {
+	Addr     string `yaml:"addr"`
+	Token    string `yaml:"token" secret:"true"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password" secret:"true"`
+}
+
+// Credentials 加密凭据库：store 为文件路径，口令从 WQB_CREDENTIALS_PASSPHRASE 或终端读取
+type Credentials struct {
+	Store string `yaml:"store"`
+}
+
+// Metrics Prometheus 指标服务配置，syncInterval 为空时不做定时同步（格式如 "1h"）
+type Metrics struct {
+	Addr         string `yaml:"addr"`
+	SyncInterval string `yaml:"syncInterval"`
+}
+
+// Sync 逐个重新拉取 alpha（修复模式）的并发设置：concurrency 为并发数，requestsPerSecond 为每秒最多请求数，
+// retryFile 为失败 alpha ID 的保存位置；为 0 或空时使用默认值
+type Sync struct {
+	Concurrency       int    `yaml:"concurrency"`
+	RequestsPerSecond int    `yaml:"requestsPerSecond"`
+	RetryFile         string `yaml:"retryFile"`
+}
+
+// Genius 账户的 Genius 等级（Gold、Expert、Master、Grand Master），用于检查表达式中的操作符是否可用；
+// quarter 为空时使用 operators 表中最新的季度
+type Genius struct {
+	Level   string `yaml:"level"`
+	Quarter string `yaml:"quarter"`
+}
+
+// FieldCheck 字段检查只在这个范围内的 alpha 中查找：from/to 为提交日期（YYYY-MM-DD，含两端），
+// types 为 REGULAR、SUPER；各项为空时不限
+type FieldCheck struct {
+	From      string   `yaml:"from" json:"from,omitempty"`
+	To        string   `yaml:"to" json:"to,omitempty"`
+	Types     []string `yaml:"types" json:"types,omitempty"`
+	Regions   []string `yaml:"regions" json:"regions,omitempty"`
+	Universes []string `yaml:"universes" json:"universes,omitempty"`
+	Delay     *int     `yaml:"delay" json:"delay,omitempty"`
+}
+
+// FieldCheckDateLayout 字段检查日期范围的格式
+const FieldCheckDateLayout = "2006-01-02"
+
+// DateRange 解析 from/to，为空的一端返回零值；to 为当天结束（次日零点，不含）
+func (f FieldCheck) DateRange() (from, to time.Time, err error) {
+	if f.From != "" {
+		if from, err = time.ParseInLocation(FieldCheckDateLayout, f.From, time.Local); err != nil {
+			return
+		}
+	}
+	if f.To != "" {
+		if to, err = time.ParseInLocation(FieldCheckDateLayout, f.To, time.Local); err != nil {
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return
+}
+
+// Log 日志配置：level 为 debug|info|warn|error，format 为 text|json，
+// file 为空时只输出到终端；programs 可按程序名单独设置级别
+type Log struct {
+	Level      string            `yaml:"level"`
+	Format     string            `yaml:"format"`
+	File       string            `yaml:"file"`
+	MaxSizeMB  int               `yaml:"maxSizeMB"`
+	MaxBackups int               `yaml:"maxBackups"`
+	Programs   map[string]string `yaml:"programs"`
+}
+
+// Profile 单个账户的配置：login 必填；database.dsn 为空时沿用全局数据库，
+// schema 不为空时在该连接上改用指定的数据库（MySQL 为库名，Postgres 为 search_path），genius 不为空的项覆盖全局设置
+type Profile struct {
+	Login    Login    `yaml:"login"`
+	Database Database `yaml:"database"`
+	Schema   string   `yaml:"schema"`
+	Genius   Genius   `yaml:"genius"`
+}
+
 // -------------------------------------- 登录返回结构体 -------------------------------------- //
 type AuthResponse struct {
 	User struct {
@@ -193,6 +295,7 @@ type GetAlphasRequest struct {
 	StatusFilter string    // 例如: "UNSUBMITTED,IS-FAIL"
 	DateFrom     time.Time // 开始日期
 	DateTo       time.Time // 结束日期
+	ModifiedFrom time.Time // 修改时间下限（不含）
 	Order        string    // 排序字段，如: "-dateSubmitted"
 	Type         string    // alpha类型
 	Hidden       *bool
@@ -200,7 +303,7 @@ type GetAlphasRequest struct {
 
 // PnLResponse 完整的响应结构
 type PnLResponse struct {
-	Schema  Schema      `json:"schema"`
+	Schema  Schema          `json:"schema"`
 	Records [][]interface{} `json:"records"`
 }
 
@@ -219,9 +322,9 @@ type Property struct {
 
 // PnLRecord 单条记录
 type PnLRecord struct {
-	Date    time.Time         `json:"date"`
-	Values  map[string]float64 `json:"values"`
-	Schema  Schema            `json:"schema,omitempty"`
+	Date   time.Time          `json:"date"`
+	Values map[string]float64 `json:"values"`
+	Schema Schema             `json:"schema,omitempty"`
 }
 
 // --------------------------------------- Operator操作符函数结构体 -------------------------------------- //
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"sort"
+
+	"program-collection/i18n"
+)
+
+// -------------------------------------- 多账户配置 -------------------------------------- //
+
+// ProfileNames 返回按名称排序的全部账户名
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile 返回使用指定账户登录信息和数据库的配置副本；name 为空时使用 defaultProfile，两者都为空时原样返回
+func (c Config) WithProfile(name string) (Config, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return c, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return c, i18n.Errorf("config.profile.not_found", name)
+	}
+
+	resolved := c
+	resolved.Login = profile.Login
+
+	if profile.Database.Driver != "" {
+		resolved.Database.Driver = profile.Database.Driver
+	}
+	if profile.Database.DSN != "" {
+		resolved.Database.DSN = profile.Database.DSN
+	}
+	if profile.Database.MaxOpenConns > 0 {
+		resolved.Database.MaxOpenConns = profile.Database.MaxOpenConns
+	}
+	if profile.Database.MaxIdleConns > 0 {
+		resolved.Database.MaxIdleConns = profile.Database.MaxIdleConns
+	}
+
+	if profile.Genius.Level != "" {
+		resolved.Genius.Level = profile.Genius.Level
+	}
+	if profile.Genius.Quarter != "" {
+		resolved.Genius.Quarter = profile.Genius.Quarter
+	}
+
+	if profile.Schema != "" {
+		dsn, err := resolved.Database.withSchema(profile.Schema)
+		if err != nil {
+			return c, i18n.Errorf("config.profile.bad_dsn", name, err)
+		}
+		resolved.Database.DSN = dsn
+	}
+
+	return resolved, nil
+}
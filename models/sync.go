@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// 修复模式的默认并发设置
+const (
+	DefaultSyncConcurrency       = 4
+	DefaultSyncRequestsPerSecond = 5
+	DefaultSyncRetryFile         = "data/alpha_update_retry.txt"
+)
+
+// Workers 返回并发数，未配置时为 DefaultSyncConcurrency
+func (s Sync) Workers() int {
+	if s.Concurrency <= 0 {
+		return DefaultSyncConcurrency
+	}
+	return s.Concurrency
+}
+
+// RequestInterval 返回两次请求之间的最小间隔，未配置时按 DefaultSyncRequestsPerSecond 计算
+func (s Sync) RequestInterval() time.Duration {
+	rate := s.RequestsPerSecond
+	if rate <= 0 {
+		rate = DefaultSyncRequestsPerSecond
+	}
+	return time.Second / time.Duration(rate)
+}
+
+// RetryPath 返回失败 alpha ID 的保存位置，未配置时为 DefaultSyncRetryFile
+func (s Sync) RetryPath() string {
+	if s.RetryFile == "" {
+		return DefaultSyncRetryFile
+	}
+	return s.RetryFile
+}
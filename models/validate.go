@@ -0,0 +1,268 @@
+package models
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"program-collection/i18n"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// -------------------------------------- 配置校验 -------------------------------------- //
+
+// FieldError 单个配置项的问题，Field 为 config.yaml 中的路径，如 database.dsn
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError 配置校验发现的全部问题
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	lines := make([]string, len(e))
+	for i, fe := range e {
+		lines[i] = fe.Field + ": " + fe.Message
+	}
+	return i18n.T("config.invalid", len(e)) + "\n  " + strings.Join(lines, "\n  ")
+}
+
+// Validate 检查配置中的 URL、必填路径、连接池大小等，一次返回所有问题；没有问题时返回 nil
+func (c Config) Validate() error {
+	var problems ValidationError
+	add := func(field, key string, args ...any) {
+		problems = append(problems, FieldError{Field: field, Message: i18n.T(key, args...)})
+	}
+
+	// 语言
+	switch strings.ToLower(strings.TrimSpace(c.Language)) {
+	case "", i18n.Chinese, i18n.English:
+	default:
+		add("language", "config.check.language", c.Language)
+	}
+
+	// BRAIN 地址与接口路径
+	if c.Third.Addr == "" {
+		add("third.addr", "config.check.required")
+	} else if u, err := url.Parse(c.Third.Addr); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("third.addr", "config.check.url", c.Third.Addr)
+	} else if strings.HasSuffix(c.Third.Addr, "/") {
+		add("third.addr", "config.check.trailing_slash", c.Third.Addr)
+	}
+
+	required := []struct{ field, value string }{
+		{"path.auth", c.Paths.Auth},
+		{"path.alpha", c.Paths.Alpha},
+		{"path.alphaList", c.Paths.AlphaList},
+		{"path.operator", c.Paths.Operator},
+		{"path.consultant", c.Paths.Consultant},
+	}
+	for _, p := range required {
+		if p.value == "" {
+			add(p.field, "config.check.required")
+		} else if !strings.HasPrefix(p.value, "/") {
+			add(p.field, "config.check.path", p.value)
+		}
+	}
+	if c.Paths.Pnl != "" && !strings.HasPrefix(c.Paths.Pnl, "/") {
+		add("path.pnl", "config.check.path", c.Paths.Pnl)
+	}
+
+	// 登录信息：配置了 profiles 时顶层 login 可以为空，但需要 defaultProfile，否则不带 --profile 时会以空账户登录
+	switch {
+	case len(c.Profiles) == 0 || c.Login.Username != "" || c.Login.Password != "":
+		validateLogin(c.Login, "login", add)
+	case c.DefaultProfile == "":
+		add("defaultProfile", "config.check.no_profile")
+	}
+	if _, ok := c.Profiles[c.DefaultProfile]; c.DefaultProfile != "" && !ok {
+		add("defaultProfile", "config.profile.not_found", c.DefaultProfile)
+	}
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		prefix := "profiles." + name
+		validateLogin(profile.Login, prefix+".login", add)
+		// profile 未配置 driver 时沿用全局 driver
+		database := profile.Database
+		if database.Driver == "" {
+			database.Driver = c.Database.Driver
+		}
+		validateDriver(database, prefix+".database.driver", add)
+		if database.DSN != "" {
+			validateDSN(database, prefix+".database.dsn", add)
+		}
+		validatePool(database, prefix+".database", add)
+		validateGenius(profile.Genius, prefix+".genius", add)
+		if profile.Schema != "" {
+			if database.DriverName() == DriverSQLite {
+				add(prefix+".schema", "config.check.schema_driver", database.DriverName())
+			} else if strings.ContainsAny(profile.Schema, "/?` ") {
+				add(prefix+".schema", "config.check.schema", profile.Schema)
+			}
+		}
+	}
+
+	// 数据库
+	validateDriver(c.Database, "database.driver", add)
+	if c.Database.DSN == "" {
+		add("database.dsn", "config.check.required")
+	} else {
+		validateDSN(c.Database, "database.dsn", add)
+	}
+	validatePool(c.Database, "database", add)
+
+	// HTTP API 与指标服务
+	validateAddr(c.Server.Addr, "server.addr", add)
+	if (c.Server.Username == "") != (c.Server.Password == "") {
+		add("server.username", "config.check.server_basic")
+	}
+	validateAddr(c.Metrics.Addr, "metrics.addr", add)
+	if c.Metrics.SyncInterval != "" {
+		if d, err := time.ParseDuration(c.Metrics.SyncInterval); err != nil || d <= 0 {
+			add("metrics.syncInterval", "config.check.duration", c.Metrics.SyncInterval)
+		}
+	}
+
+	// 修复模式并发
+	if c.Sync.Concurrency < 0 {
+		add("sync.concurrency", "config.check.non_negative", c.Sync.Concurrency)
+	}
+	if c.Sync.RequestsPerSecond < 0 {
+		add("sync.requestsPerSecond", "config.check.non_negative", c.Sync.RequestsPerSecond)
+	}
+
+	// Genius 等级
+	validateGenius(c.Genius, "genius", add)
+	validateFieldCheck(c.FieldCheck, "fieldCheck", add)
+
+	// 日志
+	if !isLogLevel(c.Log.Level) {
+		add("log.level", "config.check.log_level", c.Log.Level)
+	}
+	for program, level := range c.Log.Programs {
+		if !isLogLevel(level) {
+			add("log.programs."+program, "config.check.log_level", level)
+		}
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "", "text", "json":
+	default:
+		add("log.format", "config.check.log_format", c.Log.Format)
+	}
+	if c.Log.MaxSizeMB < 0 {
+		add("log.maxSizeMB", "config.check.non_negative", c.Log.MaxSizeMB)
+	}
+	if c.Log.MaxBackups < 0 {
+		add("log.maxBackups", "config.check.non_negative", c.Log.MaxBackups)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+func validateLogin(login Login, field string, add func(field, key string, args ...any)) {
+	if login.Username == "" {
+		add(field+".username", "config.check.required")
+	}
+	if login.Password == "" {
+		add(field+".password", "config.check.password")
+	}
+}
+
+func validateGenius(genius Genius, field string, add func(field, key string, args ...any)) {
+	if genius.Level != "" && GeniusRank(genius.Level) < 0 {
+		add(field+".level", "config.check.genius_level", genius.Level, strings.Join(GeniusLevels, ", "))
+	}
+	if genius.Quarter != "" && !IsGeniusQuarter(genius.Quarter) {
+		add(field+".quarter", "config.check.genius_quarter", genius.Quarter)
+	}
+}
+
+func validateFieldCheck(check FieldCheck, field string, add func(field, key string, args ...any)) {
+	for _, date := range []struct{ name, value string }{{"from", check.From}, {"to", check.To}} {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.Parse(FieldCheckDateLayout, date.value); err != nil {
+			add(field+"."+date.name, "config.check.date", date.value)
+		}
+	}
+	if from, to, err := check.DateRange(); err == nil && !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		add(field+".to", "config.check.date_range", check.From, check.To)
+	}
+	for _, alphaType := range check.Types {
+		if alphaType != "REGULAR" && alphaType != "SUPER" {
+			add(field+".types", "config.check.alpha_type", alphaType)
+		}
+	}
+	if check.Delay != nil && *check.Delay != 0 && *check.Delay != 1 {
+		add(field+".delay", "config.check.delay", *check.Delay)
+	}
+}
+
+func validateDriver(db Database, field string, add func(field, key string, args ...any)) {
+	switch db.DriverName() {
+	case DriverMySQL, DriverSQLite, DriverPostgres:
+	default:
+		add(field, "config.check.driver", db.Driver)
+	}
+}
+
+// sqlite 的 DSN 是文件路径，不做格式检查
+func validateDSN(db Database, field string, add func(field, key string, args ...any)) {
+	switch db.DriverName() {
+	case DriverMySQL:
+		parsed, err := mysql.ParseDSN(db.DSN)
+		if err != nil {
+			add(field, "config.check.dsn", err)
+			return
+		}
+		if parsed.DBName == "" {
+			add(field, "config.check.dsn_db")
+		}
+	case DriverPostgres:
+		parsed, err := pgconn.ParseConfig(db.DSN)
+		if err != nil {
+			add(field, "config.check.dsn", err)
+			return
+		}
+		if parsed.Database == "" {
+			add(field, "config.check.dsn_db")
+		}
+	}
+}
+
+func validatePool(db Database, field string, add func(field, key string, args ...any)) {
+	if db.MaxOpenConns < 0 {
+		add(field+".maxOpenConns", "config.check.non_negative", db.MaxOpenConns)
+	}
+	if db.MaxIdleConns < 0 {
+		add(field+".maxIdleConns", "config.check.non_negative", db.MaxIdleConns)
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		add(field+".maxIdleConns", "config.check.idle_gt_open", db.MaxIdleConns, db.MaxOpenConns)
+	}
+}
+
+func validateAddr(addr, field string, add func(field, key string, args ...any)) {
+	if addr == "" {
+		return
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		add(field, "config.check.addr", addr)
+	}
+}
+
+func isLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "", "debug", "info", "warn", "warning", "error":
+		return true
+	}
+	return false
+}
@@ -2,17 +2,15 @@ package small_program
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"time"
 
+	"program-collection/i18n"
 	"program-collection/models"
-
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 const (
@@ -61,6 +59,7 @@ type ActiveAlphaList struct {
 	RegularCode          *string `json:"regular_code,omitempty" gorm:"column:regular_code;type:text;comment:常规代码"`
 	RegularDescription   *string `json:"regular_description,omitempty" gorm:"column:regular_description;type:text;comment:常规描述"`
 	RegularOperatorCount *int    `json:"regular_operator_count,omitempty" gorm:"column:regular_operator_count;comment:常规运算符数量"`
+	CanonicalHash        *string `json:"canonical_hash,omitempty" gorm:"column:canonical_hash;size:64;index:idx_canonical_hash;comment:规范化表达式哈希"`
 
 	// 基础信息
 	DateCreated   *string `json:"date_created,omitempty" gorm:"column:date_created;type:datetime;index:idx_date_created;comment:创建时间"`
@@ -158,27 +157,21 @@ func getUserInput() string {
 // 显示 ActiveAlpha 管理子菜单
 func showActiveAlphaMenu() {
 	fmt.Println("\n--------------------------------------------")
-	fmt.Println("         ActiveAlpha 管理")
+	fmt.Println(i18n.T("alpha.menu.title"))
 	fmt.Println("--------------------------------------------")
-	fmt.Println("1. 获取新的 Alpha")
-	fmt.Println("2. 更新现有 Alpha")
-	fmt.Println("3. 返回主菜单")
+	fmt.Println(i18n.T("alpha.menu.fetch"))
+	fmt.Println(i18n.T("alpha.menu.update"))
+	fmt.Println(i18n.T("alpha.menu.sync"))
+	fmt.Println(i18n.T("alpha.menu.retry"))
+	fmt.Println(i18n.T("alpha.menu.back"))
 	fmt.Println("--------------------------------------------")
-	fmt.Print("请选择操作 (1-3): ")
+	fmt.Print(i18n.T("alpha.menu.prompt"))
 }
 
 // 10. 运行 ActiveAlpha 管理
-func RunActiveAlphaManagement(config models.Config, token string) {
-
-	// 1. 连接数据库
-	db, err := ConnectDB(config)
-	if err != nil {
-		log.Fatal("数据库连接失败:", err)
-	}
-	defer func() {
-		sqlDB, _ := db.DB()
-		sqlDB.Close()
-	}()
+func RunActiveAlphaManagement(deps Deps) error {
+	logger := programLogger("ActiveAlpha")
+	ctx := context.Background()
 
 	for {
 		showActiveAlphaMenu()
@@ -186,134 +179,102 @@ func RunActiveAlphaManagement(config models.Config, token string) {
 
 		switch choice {
 		case "1":
-			err := FetchNewAlphas(config, token, db)
+			err := FetchNewAlphas(ctx, deps)
 			if err != nil {
-				log.Printf("获取新的 Alpha 失败: %v", err)
+				logger.Error(i18n.T("alpha.fetch_failed"), "error", err)
 			} else {
-				fmt.Println("获取新的 Alpha 成功！")
+				fmt.Println(i18n.T("alpha.fetch_done"))
 			}
 		case "2":
-			err := UpdateExistingAlphas(config, token, db)
+			_, err := UpdateExistingAlphas(ctx, deps)
 			if err != nil {
-				log.Printf("更新现有 Alpha 失败: %v", err)
+				logger.Error(i18n.T("alpha.update_failed"), "error", err)
 			} else {
-				fmt.Println("更新现有 Alpha 成功！")
+				fmt.Println(i18n.T("alpha.update_done"))
 			}
-
 		case "3":
-			return
+			_, err := SyncModifiedAlphas(ctx, deps)
+			if err != nil {
+				logger.Error(i18n.T("alpha.sync_failed"), "error", err)
+			} else {
+				fmt.Println(i18n.T("alpha.sync_done"))
+			}
+		case "4":
+			_, err := RetryFailedAlphas(ctx, deps)
+			if err != nil {
+				logger.Error(i18n.T("alpha.retry_failed"), "error", err)
+			} else {
+				fmt.Println(i18n.T("alpha.retry_done"))
+			}
+
+		case "5":
+			return nil
 		default:
-			fmt.Println("无效的选择，请输入 1-3 之间的数字！")
+			fmt.Println(i18n.T("alpha.menu.invalid"))
 		}
 	}
 }
 
-// 1. 更新模式：重新拉取数据库中已有数据
-func UpdateExistingAlphas(config models.Config, token string, db *gorm.DB) error {
-	log.Println("=== 开始更新模式：重新拉取数据库中已有数据 ===")
+// 1. 更新模式（修复模式）：并发重新拉取数据库中已有数据，失败的 ID 写入重试文件；日常同步使用 SyncModifiedAlphas
+func UpdateExistingAlphas(ctx context.Context, deps Deps) (*AlphaUpdateResult, error) {
+	logger := programLogger("ActiveAlpha")
+	logger.Info(i18n.T("alpha.update.start"))
 
 	// 获取数据库中所有Alpha的ID
-	var alphaIDs []string
-	result := db.Model(&ActiveAlphaList{}).Pluck("id", &alphaIDs)
-	if result.Error != nil {
-		return fmt.Errorf("failed to get alpha IDs: %v", result.Error)
+	alphaIDs, err := deps.Alphas.IDs(ctx)
+	if err != nil {
+		return nil, i18n.Errorf("alpha.update.ids_failed", err)
 	}
 
-	log.Printf("数据库中共有 %d 条Alpha数据需要更新", len(alphaIDs))
-
-	// 分批处理，避免一次性请求过多
-	batchSize := 20
-	totalUpdated := 0
+	logger.Info(i18n.T("alpha.update.total"), "total", len(alphaIDs))
 
-	for i := 0; i < len(alphaIDs); i += batchSize {
-		end := i + batchSize
-		if end > len(alphaIDs) {
-			end = len(alphaIDs)
-		}
+	result := updateAlphasConcurrently(ctx, deps, alphaIDs)
 
-		batchIDs := alphaIDs[i:end]
-		log.Printf("处理批次 %d-%d", i+1, end)
-
-		// 1.1 为这批ID获取最新数据
-		updatedCount, err := updateBatchAlphas(config, token, db, batchIDs)
-		if err != nil {
-			log.Printf("批次 %d-%d 更新失败: %v", i+1, end, err)
-			continue
-		}
-
-		totalUpdated += updatedCount
-		log.Printf("批次 %d-%d 更新完成，更新了 %d 条", i+1, end, updatedCount)
-
-		// 避免请求过于频繁
-		time.Sleep(500 * time.Millisecond)
-	}
-
-	log.Printf("=== 更新模式完成，总共更新了 %d 条数据 ===", totalUpdated)
-	return nil
+	logger.Info(i18n.T("alpha.update.done"), "updated", result.Updated, "failed", len(result.Failed), "elapsed", result.Elapsed)
+	logger.Info(result.Changes.String())
+	return result, ctx.Err()
 }
 
-// 1.1 更新一批Alpha数据
-func updateBatchAlphas(config models.Config, token string, db *gorm.DB, alphaIDs []string) (int, error) {
-	updatedCount := 0
+// 1.1 重试模式：只重新拉取上次写入重试文件的 alpha
+func RetryFailedAlphas(ctx context.Context, deps Deps) (*AlphaUpdateResult, error) {
+	logger := programLogger("ActiveAlpha")
+	path := deps.Config.Sync.RetryPath()
 
-	// 逐个更新
-	for _, alphaID := range alphaIDs {
-		alpha, err := GetAlphaByID(config, token, alphaID)
-		if err != nil {
-			log.Printf("获取Alpha %s 失败: %v", alphaID, err)
-			continue
-		}
-
-		// 转换为数据库结构
-		dbAlpha := convertAlphaToDB(alpha)
+	alphaIDs, err := readRetryFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("alpha.retry.read_failed", err)
+	}
+	if len(alphaIDs) == 0 {
+		logger.Info(i18n.T("alpha.retry.empty"), "path", path)
+		return &AlphaUpdateResult{}, nil
+	}
 
-		// 更新数据库（只更新，不创建）
-		result := db.Model(&ActiveAlphaList{}).Where("id = ?", alphaID).Updates(dbAlpha)
-		if result.Error != nil {
-			log.Printf("更新Alpha %s 到数据库失败: %v", alphaID, result.Error)
-			continue
-		}
+	logger.Info(i18n.T("alpha.retry.start"), "path", path, "total", len(alphaIDs))
 
-		if result.RowsAffected > 0 {
-			updatedCount++
-			// log.Printf("已更新Alpha: %s", alphaID)
-		}
-	}
+	result := updateAlphasConcurrently(ctx, deps, alphaIDs)
 
-	return updatedCount, nil
+	logger.Info(i18n.T("alpha.update.done"), "updated", result.Updated, "failed", len(result.Failed), "elapsed", result.Elapsed)
+	logger.Info(result.Changes.String())
+	return result, ctx.Err()
 }
 
 // 2. 获取模式：从数据库最大日期拉到今天当前，获取新数据
-func FetchNewAlphas(config models.Config, token string, db *gorm.DB) error {
-	log.Println("=== 开始获取模式：拉取新数据 ===")
+func FetchNewAlphas(ctx context.Context, deps Deps) error {
+	logger := programLogger("ActiveAlpha")
+	logger.Info(i18n.T("alpha.fetch.start"))
 
 	// 获取数据库中最大的日期
-	// 使用指针来处理 NULL 值
-	var maxDate *string
-	result := db.Model(&ActiveAlphaList{}).Select("MAX(date_submitted) as max_date").Scan(&maxDate)
-	if result.Error != nil {
-		return fmt.Errorf("failed to get max date: %v", result.Error)
+	dateFrom, ok, err := deps.Alphas.LatestSubmitted(ctx, "")
+	if err != nil {
+		return i18n.Errorf("alpha.fetch.max_date_failed", err)
 	}
 
-	var startDateStr string
-	// 检查指针是否为 nil（表示数据库返回 NULL）
-	if maxDate == nil {
+	if !ok {
 		// 数据库为空，设置一个较远的开始日期，比如5年前
-		fiveYearsAgo := time.Now().AddDate(-5, 0, 0)
-		startDateStr = fiveYearsAgo.Format("2006-01-02 15:04:05")
-		log.Printf("数据库为空，从 %s 开始获取", startDateStr)
+		dateFrom = time.Now().AddDate(-5, 0, 0)
+		logger.Info(i18n.T("alpha.fetch.empty_db"), "date_from", dateFrom.Format("2006-01-02 15:04:05"))
 	} else {
-		startDateStr = *maxDate
-		log.Printf("数据库中最新日期: %s", startDateStr)
-	}
-
-	// 转换为time.Time
-	dateFrom, err := time.Parse("2006-01-02T15:04:05Z07:00", startDateStr)
-	if err != nil {
-		dateFrom, err = time.Parse("2006-01-02T15:04:05-07:00", startDateStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse max date: %v", err)
-		}
+		logger.Info(i18n.T("alpha.fetch.latest"), "date_from", dateFrom.Format(time.RFC3339))
 	}
 
 	// 今天的现在日期
@@ -321,10 +282,13 @@ func FetchNewAlphas(config models.Config, token string, db *gorm.DB) error {
 
 	// 重要：确保 dateFrom 在 now 之前
 	if dateFrom.After(now) || dateFrom.Equal(now) {
-		log.Println("数据已是最新，无需获取")
+		logger.Info(i18n.T("alpha.fetch.up_to_date"))
 		return nil
 	}
 
+	// 新插入的 alpha 同时写入字段索引
+	indexer := newAlphaFieldIndexer(ctx, deps)
+
 	// 分页获取数据
 	limit := 50
 	offset := 0
@@ -335,48 +299,55 @@ func FetchNewAlphas(config models.Config, token string, db *gorm.DB) error {
 
 	// 只尝试一次，使用正确的格式
 	for {
-		// log.Printf("获取数据: %s 到 %s, 偏移量: %d", dateFrom.Format("2006-01-02 15:04:05"), endDate.Format("2006-01-02 15:04:05"), offset)
+		logger.Debug(i18n.T("alpha.fetch.page"), "date_from", dateFrom.Format("2006-01-02 15:04:05"), "date_to", endDate.Format("2006-01-02 15:04:05"), "offset", offset)
 
 		beginISO, _ := ConvertToUTCPlus5(dateFrom.Format("2006-01-02 15:04:05"))
 		endISO, _ := ConvertToUTCPlus5(endDate.Format("2006-01-02 15:04:05"))
 
 		// 调用API获取数据
-		alphaLists, _ := GetAllAlphas(config, token, models.GetAlphasRequest{
+		alphaLists, err := GetAllAlphas(deps.Config, deps.Token, models.GetAlphasRequest{
 			Limit:    limit,
 			Offset:   offset,
 			DateFrom: beginISO,
 			DateTo:   endISO,
 			Order:    "dateSubmitted", // 按提交日期升序，确保获取完整
 		})
+		if err != nil {
+			return i18n.Errorf("common.fetch_alphas_failed", err)
+		}
 
 		if len(alphaLists) == 0 {
-			log.Println("没有更多数据")
+			logger.Debug(i18n.T("alpha.fetch.no_more"))
 			break
 		}
 
-		// 转换并保存数据
+		// 转换并保存数据，新数据设置创建时间
+		createdAt := time.Now()
 		var dbAlphas []ActiveAlphaList
 		for _, alpha := range alphaLists {
 			dbAlpha := convertAlphaToDB(alpha)
+			setCreateTime(&dbAlpha, createdAt)
 			dbAlphas = append(dbAlphas, dbAlpha)
 		}
 
 		// 批量插入（使用FirstOrCreate避免重复）
-		insertedCount, err := batchInsertOrIgnore(db, dbAlphas)
+		insertedCount, err := deps.Alphas.InsertIgnore(ctx, dbAlphas)
 		if err != nil {
-			log.Printf("批量插入失败: %v，尝试逐个插入", err)
+			logger.Warn(i18n.T("alpha.fetch.batch_failed"), "error", err)
 			// 逐个插入
 			insertedCount = 0
 			for _, dbAlpha := range dbAlphas {
-				result := db.Where("id = ?", dbAlpha.ID).FirstOrCreate(&dbAlpha)
-				if result.Error == nil && result.RowsAffected > 0 {
+				if inserted, err := deps.Alphas.CreateIfMissing(ctx, dbAlpha); err == nil && inserted {
 					insertedCount++
 				}
 			}
 		}
 
+		recordAlphaSnapshots(ctx, deps.Snapshots, dbAlphas)
+		indexer.Index(ctx, dbAlphas)
+
 		totalFetched += insertedCount
-		log.Printf("批次获取 %d 条，插入 %d 条，累计 %d 条", len(alphaLists), insertedCount, totalFetched)
+		logger.Info(i18n.T("alpha.fetch.batch_done"), "fetched", len(alphaLists), "inserted", insertedCount, "total", totalFetched)
 
 		offset += limit
 
@@ -389,7 +360,7 @@ func FetchNewAlphas(config models.Config, token string, db *gorm.DB) error {
 		time.Sleep(300 * time.Millisecond)
 	}
 
-	log.Printf("=== 获取模式完成，总共获取了 %d 条新数据 ===", totalFetched)
+	logger.Info(i18n.T("alpha.fetch.done"), "inserted", totalFetched)
 	return nil
 }
 
@@ -410,24 +381,6 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-// 批量插入，忽略重复（使用INSERT IGNORE）
-func batchInsertOrIgnore(db *gorm.DB, alphas []ActiveAlphaList) (int, error) {
-	if len(alphas) == 0 {
-		return 0, nil
-	}
-
-	// 方法3A: 使用CreateInBatches
-	result := db.Clauses(clause.OnConflict{
-		DoNothing: true, // MySQL中相当于INSERT IGNORE
-	}).CreateInBatches(alphas, 100)
-
-	if result.Error != nil {
-		return 0, result.Error
-	}
-
-	return int(result.RowsAffected), nil
-}
-
 // 辅助函数：拼接字符串
 func join(strs []string, sep string) string {
 	result := ""
@@ -440,12 +393,8 @@ func join(strs []string, sep string) string {
 	return result
 }
 
-// 转换函数
+// 转换函数，不设置创建时间：更新模式用非零字段更新，因此不会覆盖已有的 create_time
 func convertAlphaToDB(alpha models.Alpha) ActiveAlphaList {
-
-	// 如果是更新模式，不修改create_time
-	// 如果是获取模式，设置新的create_time
-
 	dbAlpha := ActiveAlphaList{
 		// 主键和核心字段
 		ID:     alpha.ID,
@@ -505,6 +454,7 @@ func convertAlphaToDB(alpha models.Alpha) ActiveAlphaList {
 			dbAlpha.RegularCode = stringPtr(alpha.Regular.Code)
 			dbAlpha.RegularDescription = stringPtr(alpha.Regular.Description)
 			dbAlpha.RegularOperatorCount = alpha.Regular.OperatorCount
+			dbAlpha.CanonicalHash = canonicalHash(alpha.Regular.Code)
 		}
 	}
 
@@ -637,13 +587,14 @@ func convertAlphaToDB(alpha models.Alpha) ActiveAlphaList {
 		dbAlpha.OsmosisPoints = stringPtr(string(pointsJSON))
 	}
 
-	// 设置创建时间为当前时间（只对新数据）
-	now := time.Now()
+	return dbAlpha
+}
+
+// 设置创建时间（只对新数据）
+func setCreateTime(dbAlpha *ActiveAlphaList, now time.Time) {
 	dbAlpha.CreateTime = &now
 	dateStr := now.Format("2006-01-02")
 	dbAlpha.CreateDate = &dateStr
 	monthStr := now.Format("2006-01")
 	dbAlpha.CreateMonth = &monthStr
-
-	return dbAlpha
 }
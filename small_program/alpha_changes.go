@@ -0,0 +1,230 @@
+package small_program
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ alpha 字段变化审计 -----------------------------------------------
+
+// AlphaChange 更新模式中发现的一个字段变化
+type AlphaChange struct {
+	ID         int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	AlphaID    string    `json:"alpha_id" gorm:"column:alpha_id;size:50;not null;index:idx_alpha_id"`
+	Field      string    `json:"field" gorm:"column:field;size:64;not null;index:idx_field"`
+	OldValue   *string   `json:"old_value" gorm:"column:old_value;type:text"`
+	NewValue   *string   `json:"new_value" gorm:"column:new_value;type:text"`
+	DetectedAt time.Time `json:"detected_at" gorm:"column:detected_at;index:idx_detected_at"`
+}
+
+// TableName 指定表名
+func (AlphaChange) TableName() string {
+	return "alpha_changes"
+}
+
+// 不参与比较的列：创建时间只在获取模式写入，canonical_hash 由 regular_code 计算
+var ignoredChangeColumns = map[string]bool{
+	"id":             true,
+	"create_time":    true,
+	"create_date":    true,
+	"create_month":   true,
+	"canonical_hash": true,
+}
+
+// diffAlpha 比较数据库中已有的记录和重新拉取转换后的记录。
+// 与 Update 一致，fresh 中的零值字段不会写入数据库，因此不算变化；
+// 值先按列类型归一化（JSON 重新序列化、decimal 按精度舍入、时间按时刻比较），避免数据库回读格式不同造成误报
+func diffAlpha(stored, fresh ActiveAlphaList, detectedAt time.Time) []AlphaChange {
+	var changes []AlphaChange
+
+	sv := reflect.ValueOf(stored)
+	fv := reflect.ValueOf(fresh)
+	t := sv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		column, columnType := gormColumn(t.Field(i).Tag.Get("gorm"))
+		if column == "" || ignoredChangeColumns[column] || fv.Field(i).IsZero() {
+			continue
+		}
+
+		oldValue, oldOK := normalizeColumnValue(sv.Field(i), columnType)
+		newValue, _ := normalizeColumnValue(fv.Field(i), columnType)
+		if oldOK && sameColumnValue(oldValue, newValue, columnType) {
+			continue
+		}
+
+		change := AlphaChange{AlphaID: fresh.ID, Field: column, NewValue: stringPtr(newValue), DetectedAt: detectedAt}
+		if oldOK {
+			change.OldValue = stringPtr(oldValue)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// 从 gorm 标签中取出列名和列类型
+func gormColumn(tag string) (column, columnType string) {
+	for _, part := range strings.Split(tag, ";") {
+		if value, ok := strings.CutPrefix(part, "column:"); ok {
+			column = value
+		}
+		if value, ok := strings.CutPrefix(part, "type:"); ok {
+			columnType = value
+		}
+	}
+	return column, columnType
+}
+
+// 把字段值转为便于比较和保存的文本，nil 指针返回 false
+func normalizeColumnValue(v reflect.Value, columnType string) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case columnType == "json":
+		var parsed any
+		if err := json.Unmarshal([]byte(v.String()), &parsed); err != nil {
+			return v.String(), true
+		}
+		// 重新序列化后对象的键有序，与数据库回读时的空格、键顺序无关
+		canonical, _ := json.Marshal(parsed)
+		return string(canonical), true
+
+	case strings.HasPrefix(columnType, "decimal("):
+		value := v.Float()
+		if scale, ok := decimalScale(columnType); ok {
+			pow := math.Pow(10, float64(scale))
+			value = math.Round(value*pow) / pow
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	}
+
+	return fmt.Sprint(v.Interface()), true
+}
+
+// decimal(10,4) 返回 4
+func decimalScale(columnType string) (int, bool) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(columnType, "decimal("), ")")
+	_, scale, ok := strings.Cut(inner, ",")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(scale))
+	return n, err == nil
+}
+
+func sameColumnValue(a, b, columnType string) bool {
+	if a == b {
+		return true
+	}
+
+	switch columnType {
+	case "date":
+		// 数据库可能回读为 2025-01-02 或 2025-01-02T00:00:00Z
+		return len(a) >= 10 && len(b) >= 10 && a[:10] == b[:10]
+	case "datetime", "timestamp":
+		at, aok := parseDBTime(a)
+		bt, bok := parseDBTime(b)
+		return aok && bok && at.Equal(bt)
+	}
+	return false
+}
+
+// ------------------------------------------------ 变化汇总 -----------------------------------------------
+
+// AlphaChangeSummary 一次更新中发现的变化汇总
+type AlphaChangeSummary struct {
+	ChangedAlphas  int            `json:"changed_alphas"`
+	StatusChanged  int            `json:"status_changed"`
+	NewOSChecks    int            `json:"new_os_checks"` // 出现了新检查名称的 alpha 数
+	Decommissioned int            `json:"decommissioned"`
+	Fields         map[string]int `json:"fields"`
+}
+
+// Add 累加一个 alpha 的变化
+func (s *AlphaChangeSummary) Add(changes []AlphaChange) {
+	if len(changes) == 0 {
+		return
+	}
+	if s.Fields == nil {
+		s.Fields = map[string]int{}
+	}
+
+	s.ChangedAlphas++
+	for _, change := range changes {
+		s.Fields[change.Field]++
+		switch change.Field {
+		case "status":
+			s.StatusChanged++
+			if change.NewValue != nil && *change.NewValue == "DECOMMISSIONED" {
+				s.Decommissioned++
+			}
+		case "os_checks":
+			if hasNewCheck(change.OldValue, change.NewValue) {
+				s.NewOSChecks++
+			}
+		}
+	}
+}
+
+// 新的检查项列表中是否有旧列表中没有的检查名称；已有检查项的结果、数值变化不算新检查项
+func hasNewCheck(oldValue, newValue *string) bool {
+	if newValue == nil {
+		return false
+	}
+	var oldChecks, newChecks []struct {
+		Name string `json:"name"`
+	}
+	if oldValue != nil {
+		json.Unmarshal([]byte(*oldValue), &oldChecks)
+	}
+	if err := json.Unmarshal([]byte(*newValue), &newChecks); err != nil {
+		return false
+	}
+
+	seen := make(map[string]bool, len(oldChecks))
+	for _, check := range oldChecks {
+		seen[check.Name] = true
+	}
+	for _, check := range newChecks {
+		if !seen[check.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// String 如 "3 个 alpha 状态变化，12 个有新的 OS 检查项，1 个已下线"，并列出变化最多的列
+func (s AlphaChangeSummary) String() string {
+	if s.ChangedAlphas == 0 {
+		return i18n.T("alpha.changes.none")
+	}
+
+	fields := make([]string, 0, len(s.Fields))
+	for field := range s.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if s.Fields[fields[i]] != s.Fields[fields[j]] {
+			return s.Fields[fields[i]] > s.Fields[fields[j]]
+		}
+		return fields[i] < fields[j]
+	})
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = fmt.Sprintf("%s=%d", field, s.Fields[field])
+	}
+	return i18n.T("alpha.changes.summary", s.ChangedAlphas, s.StatusChanged, s.NewOSChecks, s.Decommissioned, strings.Join(parts, ", "))
+}
@@ -0,0 +1,24 @@
+package small_program
+
+import "testing"
+
+func TestAlphaChangeSummaryOSChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new *string
+		want     int
+	}{
+		{"first checks", nil, ptr(`[{"name":"LOW_SHARPE","result":"PASS"}]`), 1},
+		{"check added", ptr(`[{"name":"LOW_SHARPE","result":"PASS"}]`), ptr(`[{"name":"LOW_SHARPE","result":"PASS"},{"name":"IS_LADDER_SHARPE","result":"FAIL"}]`), 1},
+		{"result changed", ptr(`[{"name":"LOW_SHARPE","result":"PASS","value":1.2}]`), ptr(`[{"name":"LOW_SHARPE","result":"FAIL","value":0.9}]`), 0},
+		{"check removed", ptr(`[{"name":"LOW_SHARPE"},{"name":"IS_LADDER_SHARPE"}]`), ptr(`[{"name":"LOW_SHARPE"}]`), 0},
+		{"invalid json", ptr(`[]`), ptr(`not json`), 0},
+	}
+	for _, tt := range tests {
+		var summary AlphaChangeSummary
+		summary.Add([]AlphaChange{{AlphaID: "AAA111", Field: "os_checks", OldValue: tt.old, NewValue: tt.new}})
+		if summary.NewOSChecks != tt.want || summary.Fields["os_checks"] != 1 {
+			t.Errorf("%s: NewOSChecks = %d, os_checks = %d; want %d, 1", tt.name, summary.NewOSChecks, summary.Fields["os_checks"], tt.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 重复表达式查找 -----------------------------------------------
+// active_alpha_list.canonical_hash 为 regular_code 规范形式的哈希（见 fastexpr.Canonical），
+// 变量改名、可交换参数换序、空白和数字写法不同的表达式哈希相同
+
+// 表达式无法解析时返回 nil，此时不参与重复查找
+func canonicalHash(code string) *string {
+	if strings.TrimSpace(code) == "" {
+		return nil
+	}
+	hash, err := fastexpr.CanonicalHash(code)
+	if err != nil {
+		return nil
+	}
+	return &hash
+}
+
+// BackfillCanonicalHashes 为已有的 alpha 计算 canonical_hash，只更新与已保存的值不同的记录；
+// 返回更新的数量和无法解析的 alpha ID
+func BackfillCanonicalHashes(ctx context.Context, repo AlphaRepo) (int, []string, error) {
+	alphas, _, err := repo.List(ctx, AlphaFilter{})
+	if err != nil {
+		return 0, nil, i18n.Errorf("dupes.load_alphas_failed", err)
+	}
+
+	updated := 0
+	var unparsable []string
+	for _, alpha := range alphas {
+		if alpha.RegularCode == nil || strings.TrimSpace(*alpha.RegularCode) == "" {
+			continue
+		}
+		hash := canonicalHash(*alpha.RegularCode)
+		if hash == nil {
+			unparsable = append(unparsable, alpha.ID)
+			continue
+		}
+		if alpha.CanonicalHash != nil && *alpha.CanonicalHash == *hash {
+			continue
+		}
+		if _, err := repo.Update(ctx, ActiveAlphaList{ID: alpha.ID, CanonicalHash: hash}); err != nil {
+			return updated, unparsable, i18n.Errorf("dupes.update_failed", alpha.ID, err)
+		}
+		updated++
+	}
+
+	programLogger("Duplicates").Info(i18n.T("dupes.backfilled"), "updated", updated, "unparsable", len(unparsable))
+	return updated, unparsable, nil
+}
+
+// DuplicateGroup 规范形式相同的一组 alpha
+type DuplicateGroup struct {
+	Hash   string            `json:"hash"`
+	Alphas []ActiveAlphaList `json:"alphas"`
+}
+
+// FindDuplicateAlphas 按 canonical_hash 把已保存的 alpha 分组，返回有两个及以上成员的组，成员多的组在前；
+// sameSettings 为 true 时还要求地区、股票池、延迟和中性化方式相同
+func FindDuplicateAlphas(ctx context.Context, repo AlphaRepo, sameSettings bool) ([]DuplicateGroup, error) {
+	alphas, _, err := repo.List(ctx, AlphaFilter{})
+	if err != nil {
+		return nil, i18n.Errorf("dupes.load_alphas_failed", err)
+	}
+
+	groups := map[string]*DuplicateGroup{}
+	var keys []string
+	for _, alpha := range alphas {
+		if alpha.CanonicalHash == nil || *alpha.CanonicalHash == "" {
+			continue
+		}
+		key := *alpha.CanonicalHash
+		if sameSettings {
+			key += "|" + settingsKey(alpha)
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &DuplicateGroup{Hash: *alpha.CanonicalHash}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Alphas = append(group.Alphas, alpha)
+	}
+
+	var result []DuplicateGroup
+	for _, key := range keys {
+		if len(groups[key].Alphas) > 1 {
+			result = append(result, *groups[key])
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].Alphas) > len(result[j].Alphas)
+	})
+	return result, nil
+}
+
+// 地区/股票池/延迟/中性化方式
+func settingsKey(alpha ActiveAlphaList) string {
+	delay := "-"
+	if alpha.Delay != nil {
+		delay = fmt.Sprint(*alpha.Delay)
+	}
+	return strings.Join([]string{derefOr(alpha.Region, "-"), derefOr(alpha.Universe, "-"), delay, derefOr(alpha.Neutralization, "-")}, "/")
+}
+
+func derefOr(value *string, fallback string) string {
+	if value == nil || *value == "" {
+		return fallback
+	}
+	return *value
+}
+
+// ---- 候选表达式 ----
+
+// DuplicateCandidate 待检查的表达式，Name 为 文件:行号 或 <arg>、<stdin>
+type DuplicateCandidate struct {
+	Name string
+	Code string
+}
+
+// CandidatesFromText 读取文件中的多个表达式，分隔规则见 SplitExpressions
+func CandidatesFromText(name, text string) []DuplicateCandidate {
+	var candidates []DuplicateCandidate
+	for _, expr := range SplitExpressions(text) {
+		candidates = append(candidates, DuplicateCandidate{Name: fmt.Sprintf("%s:%d", name, expr.Line), Code: expr.Text})
+	}
+	return candidates
+}
+
+// CandidateMatch 候选表达式与已保存 alpha 的比对结果
+type CandidateMatch struct {
+	Name    string            `json:"name"`
+	Hash    string            `json:"hash,omitempty"`
+	Err     error             `json:"-"`
+	Matches []ActiveAlphaList `json:"matches"`
+}
+
+// MatchCandidates 查找与候选表达式规范形式相同的已保存 alpha；候选之间互相重复时也会列出
+func MatchCandidates(ctx context.Context, repo AlphaRepo, candidates []DuplicateCandidate) ([]CandidateMatch, error) {
+	results := make([]CandidateMatch, len(candidates))
+	firstByHash := map[string]string{}
+	for i, candidate := range candidates {
+		results[i].Name = candidate.Name
+		hash, err := fastexpr.CanonicalHash(candidate.Code)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Hash = hash
+
+		matches, err := repo.ByCanonicalHash(ctx, hash)
+		if err != nil {
+			return nil, i18n.Errorf("dupes.query_failed", err)
+		}
+		results[i].Matches = matches
+
+		// 同一批候选中前面已有相同的表达式
+		if first, ok := firstByHash[hash]; ok {
+			results[i].Matches = append(results[i].Matches, ActiveAlphaList{ID: first})
+		} else {
+			firstByHash[hash] = candidate.Name
+		}
+	}
+	return results, nil
+}
+
+// PrintDuplicateGroups 输出已保存 alpha 中的重复组
+func PrintDuplicateGroups(groups []DuplicateGroup) {
+	if len(groups) == 0 {
+		fmt.Println(i18n.T("dupes.none"))
+		return
+	}
+
+	total := 0
+	for i, group := range groups {
+		fmt.Println(i18n.T("dupes.group", i+1, len(group.Alphas), group.Hash[:12]))
+		for _, alpha := range group.Alphas {
+			printDuplicateAlpha(alpha)
+		}
+		total += len(group.Alphas)
+	}
+	fmt.Println(i18n.T("dupes.summary", len(groups), total))
+}
+
+// PrintCandidateMatches 输出候选表达式的比对结果，有重复或解析失败时返回 false
+func PrintCandidateMatches(results []CandidateMatch) bool {
+	ok := true
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			ok = false
+			fmt.Println(i18n.T("dupes.parse_failed", result.Name, result.Err))
+		case len(result.Matches) == 0:
+			fmt.Println(i18n.T("dupes.unique", result.Name))
+		default:
+			ok = false
+			fmt.Println(i18n.T("dupes.duplicate", result.Name, len(result.Matches)))
+			for _, alpha := range result.Matches {
+				printDuplicateAlpha(alpha)
+			}
+		}
+	}
+	return ok
+}
+
+// 只有 ID 时为同一批中的其他候选表达式
+func printDuplicateAlpha(alpha ActiveAlphaList) {
+	if alpha.Type == "" {
+		fmt.Printf("   - %s\n", alpha.ID)
+		return
+	}
+	fmt.Printf("   - %-10s  %-8s  %-10s  %s  %s\n", alpha.ID, alpha.Status, derefOr(alpha.DateSubmitted, "-"), settingsKey(alpha), strings.Join(strings.Fields(derefOr(alpha.RegularCode, "")), " "))
+}
@@ -0,0 +1,354 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+// ------------------------------------------------ alpha 字段倒排索引 -----------------------------------------------
+// alpha_fields 保存每个 alpha 的表达式用到的数据字段，同步 alpha 时由保存的代码生成，
+// 字段检查时按字段一次查出使用这些字段的全部 alpha，不再每次重新拉取操作符和 alpha 列表
+
+// AlphaField 一个 alpha 在某个操作符路径下使用的一个字段
+type AlphaField struct {
+	ID           int64   `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	AlphaID      string  `json:"alpha_id" gorm:"column:alpha_id;size:50;not null;index:idx_alpha_id"`
+	Field        string  `json:"field" gorm:"column:field;size:255;not null;index:idx_field"`
+	OperatorPath string  `json:"operator_path" gorm:"column:operator_path;size:255;not null"`
+	Source       string  `json:"source" gorm:"column:source;size:20;not null;default:regular"` // regular、combo 或 selection
+	Region       *string `json:"region,omitempty" gorm:"column:region;size:50"`
+	Delay        *int    `json:"delay,omitempty" gorm:"column:delay"`
+}
+
+// TableName 指定表名
+func (AlphaField) TableName() string {
+	return "alpha_fields"
+}
+
+// operator_path 列的长度
+const maxOperatorPathLen = 255
+
+// 每批重建索引的 alpha 数量
+const alphaFieldBatchSize = 200
+
+// alphaCode 一个 alpha 保存的一段代码，source 为 regular、combo 或 selection
+type alphaCode struct {
+	source string
+	code   string
+}
+
+// combo 代码中代表各个组成 alpha 的变量，不是数据字段
+var comboPlaceholders = map[string]bool{"alpha": true}
+
+// 代码中的字段及其所在的操作符路径
+func (c alphaCode) fieldUses(extractor *FieldExtractor) []FieldUse {
+	uses := extractor.Uses(c.code)
+	if c.source == "combo" {
+		uses = slices.DeleteFunc(uses, func(use FieldUse) bool { return comboPlaceholders[use.Field] })
+	}
+	return uses
+}
+
+// REGULAR alpha 只有 regular_code，SUPER alpha 有 combo_code 和 selection_code；空代码不返回
+func storedAlphaCodes(alpha ActiveAlphaList) []alphaCode {
+	var codes []alphaCode
+	for _, code := range []struct {
+		source string
+		value  *string
+	}{
+		{"regular", alpha.RegularCode},
+		{"combo", alpha.ComboCode},
+		{"selection", alpha.SelectionCode},
+	} {
+		if code.value != nil && strings.TrimSpace(*code.value) != "" {
+			codes = append(codes, alphaCode{source: code.source, code: *code.value})
+		}
+	}
+	return codes
+}
+
+// 由 alpha 保存的代码生成索引记录，没有代码时返回 nil
+func alphaFieldRows(extractor *FieldExtractor, alpha ActiveAlphaList) []AlphaField {
+	var rows []AlphaField
+	for _, code := range storedAlphaCodes(alpha) {
+		for _, use := range code.fieldUses(extractor) {
+			path := use.Path
+			if len(path) > maxOperatorPathLen {
+				path = path[:maxOperatorPathLen]
+			}
+			rows = append(rows, AlphaField{
+				AlphaID:      alpha.ID,
+				Field:        use.Field,
+				OperatorPath: path,
+				Source:       code.source,
+				Region:       alpha.Region,
+				Delay:        alpha.Delay,
+			})
+		}
+	}
+	return rows
+}
+
+// loadFieldExtractor 用 operators 表中的操作符创建字段提取器；表为空且已登录时改为从接口获取
+func loadFieldExtractor(ctx context.Context, deps *Deps) (*FieldExtractor, error) {
+	records, err := deps.Operators.List(ctx)
+	if err != nil {
+		return nil, i18n.Errorf("field.load_operators_failed", err)
+	}
+
+	// 同一操作符在各等级、各季度都有记录，只保留一条
+	var operators []models.Operator
+	seen := map[string]bool{}
+	for _, record := range records {
+		if seen[record.Name] {
+			continue
+		}
+		seen[record.Name] = true
+		operators = append(operators, models.Operator{Name: record.Name, Definition: record.Definition})
+	}
+
+	if len(operators) == 0 && deps.Token != "" {
+		operators, err = retryOnUnauthorized(deps, func(token string) ([]models.Operator, error) {
+			return FetchOperators(deps.Config, token)
+		})
+		if err != nil {
+			return nil, i18n.Errorf("common.fetch_operators_failed", err)
+		}
+	}
+	if len(operators) == 0 {
+		return nil, i18n.Errorf("field.no_operators")
+	}
+	return NewFieldExtractor(operators), nil
+}
+
+// ---- 同步时更新索引 ----
+
+// 同步过程中更新字段索引，操作符只在创建时加载一次；加载失败时不更新索引
+type alphaFieldIndexer struct {
+	repo      AlphaFieldRepo
+	extractor *FieldExtractor
+}
+
+func newAlphaFieldIndexer(ctx context.Context, deps Deps) *alphaFieldIndexer {
+	if deps.AlphaFields == nil {
+		return &alphaFieldIndexer{}
+	}
+	extractor, err := loadFieldExtractor(ctx, &deps)
+	if err != nil {
+		programLogger("ActiveAlpha").Warn(i18n.T("field.index_disabled"), "error", err)
+		return &alphaFieldIndexer{}
+	}
+	return &alphaFieldIndexer{repo: deps.AlphaFields, extractor: extractor}
+}
+
+// Index 用 alphas 当前的代码替换它们的索引记录，失败只记录日志，不影响同步结果
+func (x *alphaFieldIndexer) Index(ctx context.Context, alphas []ActiveAlphaList) {
+	if x.repo == nil || len(alphas) == 0 {
+		return
+	}
+
+	ids := make([]string, len(alphas))
+	var rows []AlphaField
+	for i, alpha := range alphas {
+		ids[i] = alpha.ID
+		rows = append(rows, alphaFieldRows(x.extractor, alpha)...)
+	}
+	if err := x.repo.Replace(ctx, ids, rows); err != nil {
+		programLogger("ActiveAlpha").Warn(i18n.T("field.index_failed"), "alphas", len(alphas), "error", err)
+	}
+}
+
+// RebuildAlphaFieldIndex 由 active_alpha_list 中全部 alpha 的代码重建字段索引，返回 alpha 数量和索引记录数
+func RebuildAlphaFieldIndex(ctx context.Context, deps Deps) (int, int, error) {
+	extractor, err := loadFieldExtractor(ctx, &deps)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	alphas, _, err := deps.Alphas.List(ctx, AlphaFilter{})
+	if err != nil {
+		return 0, 0, i18n.Errorf("field.load_alphas_failed", err)
+	}
+
+	total := 0
+	for start := 0; start < len(alphas); start += alphaFieldBatchSize {
+		batch := alphas[start:min(start+alphaFieldBatchSize, len(alphas))]
+		ids := make([]string, len(batch))
+		var rows []AlphaField
+		for i, alpha := range batch {
+			ids[i] = alpha.ID
+			rows = append(rows, alphaFieldRows(extractor, alpha)...)
+		}
+		if err := deps.AlphaFields.Replace(ctx, ids, rows); err != nil {
+			return start, total, i18n.Errorf("field.index_write_failed", err)
+		}
+		total += len(rows)
+	}
+
+	programLogger("FieldCheck").Info(i18n.T("field.reindexed"), "alphas", len(alphas), "rows", total)
+	return len(alphas), total, nil
+}
+
+// ---- 按字段查询 ----
+
+// FieldCheckScope 字段检查的查找范围，零值或空切片表示不限
+type FieldCheckScope struct {
+	From      time.Time // 提交时间下限（含）
+	To        time.Time // 提交时间上限（不含）
+	Types     []string
+	Regions   []string
+	Universes []string
+	Delay     *int
+}
+
+// NewFieldCheckScope 由配置（或接口请求中的 scope）创建查找范围
+func NewFieldCheckScope(check models.FieldCheck) (FieldCheckScope, error) {
+	from, to, err := check.DateRange()
+	if err != nil {
+		return FieldCheckScope{}, i18n.Errorf("field.bad_date", err)
+	}
+	return FieldCheckScope{
+		From:      from,
+		To:        to,
+		Types:     check.Types,
+		Regions:   check.Regions,
+		Universes: check.Universes,
+		Delay:     check.Delay,
+	}, nil
+}
+
+// 提交时间是否在范围内；限定了日期时，没有提交时间的 alpha 不在范围内
+func (s FieldCheckScope) submittedIn(alpha ActiveAlphaList) bool {
+	if s.From.IsZero() && s.To.IsZero() {
+		return true
+	}
+	submitted, ok := submittedAt(alpha)
+	if !ok {
+		return false
+	}
+	return (s.From.IsZero() || !submitted.Before(s.From)) && (s.To.IsZero() || submitted.Before(s.To))
+}
+
+// FieldMatch 使用了给定字段中至少一个的 alpha
+type FieldMatch struct {
+	AlphaID       string   `json:"alpha_id"`
+	Type          string   `json:"type"`
+	Settings      string   `json:"settings"` // 地区/股票池/延迟/中性化方式
+	DateSubmitted string   `json:"date_submitted,omitempty"`
+	Overlap       int      `json:"overlap"` // 重叠的字段数
+	Fields        []string `json:"fields"`  // 重叠的字段，按给定字段的顺序
+	Paths         []string `json:"paths"`   // 这些字段所在的操作符路径
+	Sources       []string `json:"sources"` // 字段出现在哪些代码中：regular、combo、selection
+}
+
+// 按 ID 读取 alpha 时每次查询的数量
+const fieldMatchQuerySize = 500
+
+// FindAlphasByFields 在字段索引中查找使用了 fields 的 alpha，只保留 scope 范围内的，重叠字段多的在前；
+// excludeID 不参与结果
+func FindAlphasByFields(ctx context.Context, repos Repos, fields []string, excludeID string, scope FieldCheckScope) ([]FieldMatch, error) {
+	if len(fields) == 0 {
+		return []FieldMatch{}, nil
+	}
+
+	// 1. 一次查出使用这些字段的全部索引记录
+	rows, err := repos.AlphaFields.ByFields(ctx, fields)
+	if err != nil {
+		return nil, i18n.Errorf("field.query_failed", err)
+	}
+
+	order := map[string]int{}
+	for i, field := range fields {
+		order[field] = i
+	}
+
+	matches := map[string]*FieldMatch{}
+	var ids []string
+	for _, row := range rows {
+		if row.AlphaID == excludeID {
+			continue
+		}
+		match, ok := matches[row.AlphaID]
+		if !ok {
+			match = &FieldMatch{AlphaID: row.AlphaID}
+			matches[row.AlphaID] = match
+			ids = append(ids, row.AlphaID)
+		}
+		if !contains(match.Fields, row.Field) {
+			match.Fields = append(match.Fields, row.Field)
+		}
+		if row.OperatorPath != "" && !contains(match.Paths, row.OperatorPath) {
+			match.Paths = append(match.Paths, row.OperatorPath)
+		}
+		if !contains(match.Sources, row.Source) {
+			match.Sources = append(match.Sources, row.Source)
+		}
+	}
+
+	// 2. 读取匹配的 alpha，按类型、设置和提交时间过滤，并补充设置和提交时间
+	result := []FieldMatch{}
+	for start := 0; start < len(ids); start += fieldMatchQuerySize {
+		alphas, _, err := repos.Alphas.List(ctx, AlphaFilter{
+			IDs:       ids[start:min(start+fieldMatchQuerySize, len(ids))],
+			Types:     scope.Types,
+			Regions:   scope.Regions,
+			Universes: scope.Universes,
+			Delay:     scope.Delay,
+		})
+		if err != nil {
+			return nil, i18n.Errorf("field.load_alphas_failed", err)
+		}
+		for _, alpha := range alphas {
+			if !scope.submittedIn(alpha) {
+				continue
+			}
+			match := matches[alpha.ID]
+			match.Type = alpha.Type
+			match.Settings = settingsKey(alpha)
+			match.DateSubmitted = derefOr(alpha.DateSubmitted, "")
+			sort.SliceStable(match.Fields, func(i, j int) bool { return order[match.Fields[i]] < order[match.Fields[j]] })
+			match.Overlap = len(match.Fields)
+			result = append(result, *match)
+		}
+	}
+
+	// 3. 重叠字段多的在前，相同时提交晚的在前
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Overlap != result[j].Overlap {
+			return result[i].Overlap > result[j].Overlap
+		}
+		return result[i].DateSubmitted > result[j].DateSubmitted
+	})
+	return result, nil
+}
+
+// PrintFieldMatches 输出按字段查询的结果
+func PrintFieldMatches(fields []string, matches []FieldMatch) {
+	if len(matches) == 0 {
+		fmt.Println(i18n.T("field.none"))
+		return
+	}
+	fmt.Println(i18n.T("field.index_header", strings.Join(fields, ", "), len(matches)))
+	for _, match := range matches {
+		fmt.Printf("   - %-10s  %-8s  %-10s  %s  %d/%d  %s  [%s]\n", match.AlphaID, match.Type, dateOnly(match.DateSubmitted), match.Settings,
+			match.Overlap, len(fields), strings.Join(match.Fields, ", "), strings.Join(match.Paths, "; "))
+	}
+}
+
+// 提交时间只显示日期，没有时显示 -
+func dateOnly(value string) string {
+	if value == "" {
+		return "-"
+	}
+	if t, ok := parseDBTime(value); ok {
+		return t.Format(models.FieldCheckDateLayout)
+	}
+	return value
+}
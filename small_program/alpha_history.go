@@ -0,0 +1,256 @@
+package small_program
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+// ------------------------------------------------ alpha 指标快照 -----------------------------------------------
+
+// AlphaSnapshot 每次同步时记录的 alpha 关键指标，同一 alpha 同一天只保留最后一次
+type AlphaSnapshot struct {
+	ID           int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	AlphaID      string    `json:"alpha_id" gorm:"column:alpha_id;size:50;not null;uniqueIndex:uk_alpha_date,priority:1"`
+	SnapshotDate time.Time `json:"snapshot_date" gorm:"column:snapshot_date;type:date;not null;uniqueIndex:uk_alpha_date,priority:2"`
+
+	Status string `json:"status" gorm:"column:status;size:20"`
+	Stage  string `json:"stage" gorm:"column:stage;size:20"`
+
+	IsSharpe          *float64 `json:"is_sharpe,omitempty" gorm:"column:is_sharpe;type:decimal(10,2)"`
+	IsFitness         *float64 `json:"is_fitness,omitempty" gorm:"column:is_fitness;type:decimal(10,2)"`
+	IsReturns         *float64 `json:"is_returns,omitempty" gorm:"column:is_returns;type:decimal(10,4)"`
+	IsTurnover        *float64 `json:"is_turnover,omitempty" gorm:"column:is_turnover;type:decimal(10,4)"`
+	IsDrawdown        *float64 `json:"is_drawdown,omitempty" gorm:"column:is_drawdown;type:decimal(10,4)"`
+	IsSelfCorrelation *float64 `json:"is_self_correlation,omitempty" gorm:"column:is_self_correlation;type:decimal(10,4)"`
+	IsProdCorrelation *float64 `json:"is_prod_correlation,omitempty" gorm:"column:is_prod_correlation;type:decimal(10,4)"`
+
+	OsIsSharpeRatio       *float64 `json:"os_is_sharpe_ratio,omitempty" gorm:"column:os_is_sharpe_ratio;type:decimal(10,4)"`
+	OsPreCloseSharpeRatio *float64 `json:"os_pre_close_sharpe_ratio,omitempty" gorm:"column:os_pre_close_sharpe_ratio;type:decimal(10,4)"`
+
+	PyramidThemes *string `json:"pyramid_themes,omitempty" gorm:"column:pyramid_themes;type:json"`
+
+	SnapshotTime time.Time `json:"snapshot_time" gorm:"column:snapshot_time"`
+}
+
+// TableName 指定表名
+func (AlphaSnapshot) TableName() string {
+	return "alpha_snapshots"
+}
+
+// 从转换后的数据库记录生成快照，快照日期取本地日期
+func newAlphaSnapshot(alpha ActiveAlphaList, now time.Time) AlphaSnapshot {
+	return AlphaSnapshot{
+		AlphaID:               alpha.ID,
+		SnapshotDate:          time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		Status:                alpha.Status,
+		Stage:                 alpha.Stage,
+		IsSharpe:              alpha.IsSharpe,
+		IsFitness:             alpha.IsFitness,
+		IsReturns:             alpha.IsReturns,
+		IsTurnover:            alpha.IsTurnover,
+		IsDrawdown:            alpha.IsDrawdown,
+		IsSelfCorrelation:     alpha.IsSelfCorrelation,
+		IsProdCorrelation:     alpha.IsProdCorrelation,
+		OsIsSharpeRatio:       jsonNumber(alpha.OsIsSharpeRatio),
+		OsPreCloseSharpeRatio: jsonNumber(alpha.OsPreCloseSharpeRatio),
+		PyramidThemes:         alpha.PyramidThemes,
+		SnapshotTime:          now,
+	}
+}
+
+// OS 夏普比率在 active_alpha_list 中按 JSON 保存，是数字时才记录
+func jsonNumber(value *string) *float64 {
+	if value == nil {
+		return nil
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(*value), 64)
+	if err != nil {
+		return nil
+	}
+	return &number
+}
+
+// 同步后记录快照，失败只记录日志，不影响同步结果
+func recordAlphaSnapshots(ctx context.Context, repo SnapshotRepo, alphas []ActiveAlphaList) {
+	if repo == nil || len(alphas) == 0 {
+		return
+	}
+
+	now := time.Now()
+	snapshots := make([]AlphaSnapshot, len(alphas))
+	for i, alpha := range alphas {
+		snapshots[i] = newAlphaSnapshot(alpha, now)
+	}
+	if err := repo.Record(ctx, snapshots); err != nil {
+		programLogger("ActiveAlpha").Warn(i18n.T("history.record_failed"), "alphas", len(alphas), "error", err)
+	}
+}
+
+// ------------------------------------------------ 历史与变化排行 -----------------------------------------------
+
+// 可用于变化排行的指标
+var snapshotMetrics = map[string]func(AlphaSnapshot) *float64{
+	"os_sharpe":        func(s AlphaSnapshot) *float64 { return s.OsIsSharpeRatio },
+	"pre_close_sharpe": func(s AlphaSnapshot) *float64 { return s.OsPreCloseSharpeRatio },
+	"is_sharpe":        func(s AlphaSnapshot) *float64 { return s.IsSharpe },
+	"is_fitness":       func(s AlphaSnapshot) *float64 { return s.IsFitness },
+	"is_returns":       func(s AlphaSnapshot) *float64 { return s.IsReturns },
+	"is_turnover":      func(s AlphaSnapshot) *float64 { return s.IsTurnover },
+	"is_drawdown":      func(s AlphaSnapshot) *float64 { return s.IsDrawdown },
+	"prod_correlation": func(s AlphaSnapshot) *float64 { return s.IsProdCorrelation },
+	"self_correlation": func(s AlphaSnapshot) *float64 { return s.IsSelfCorrelation },
+}
+
+// SnapshotMetricNames 返回可用于变化排行的指标名
+func SnapshotMetricNames() []string {
+	names := make([]string, 0, len(snapshotMetrics))
+	for name := range snapshotMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AlphaMover 两个日期之间某个指标的变化
+type AlphaMover struct {
+	AlphaID    string  `json:"alpha_id"`
+	From       float64 `json:"from"`
+	To         float64 `json:"to"`
+	Change     float64 `json:"change"`
+	FromStatus string  `json:"from_status"`
+	ToStatus   string  `json:"to_status"`
+}
+
+// AlphaHistory 返回一个 alpha 的全部快照，按日期升序
+func AlphaHistory(ctx context.Context, repo SnapshotRepo, alphaID string) ([]AlphaSnapshot, error) {
+	snapshots, err := repo.History(ctx, alphaID)
+	if err != nil {
+		return nil, i18n.Errorf("history.query_failed", err)
+	}
+	return snapshots, nil
+}
+
+// AlphaMovers 比较每个 alpha 在 from、to 两天（或之前最近一次）的快照，按指标变化的绝对值倒序返回前 limit 个
+func AlphaMovers(ctx context.Context, repo SnapshotRepo, from, to time.Time, metric string, limit int) ([]AlphaMover, error) {
+	value, ok := snapshotMetrics[metric]
+	if !ok {
+		return nil, i18n.Errorf("history.bad_metric", metric, strings.Join(SnapshotMetricNames(), ", "))
+	}
+	if !from.Before(to) {
+		return nil, i18n.Errorf("history.bad_range", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	before, err := repo.AsOf(ctx, from)
+	if err != nil {
+		return nil, i18n.Errorf("history.query_failed", err)
+	}
+	after, err := repo.AsOf(ctx, to)
+	if err != nil {
+		return nil, i18n.Errorf("history.query_failed", err)
+	}
+
+	start := make(map[string]AlphaSnapshot, len(before))
+	for _, snapshot := range before {
+		start[snapshot.AlphaID] = snapshot
+	}
+
+	var movers []AlphaMover
+	for _, end := range after {
+		begin, ok := start[end.AlphaID]
+		if !ok {
+			continue
+		}
+		fromValue, toValue := value(begin), value(end)
+		if fromValue == nil || toValue == nil {
+			continue
+		}
+		movers = append(movers, AlphaMover{
+			AlphaID:    end.AlphaID,
+			From:       *fromValue,
+			To:         *toValue,
+			Change:     *toValue - *fromValue,
+			FromStatus: begin.Status,
+			ToStatus:   end.Status,
+		})
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		if a, b := math.Abs(movers[i].Change), math.Abs(movers[j].Change); a != b {
+			return a > b
+		}
+		return movers[i].AlphaID < movers[j].AlphaID
+	})
+	if limit > 0 && len(movers) > limit {
+		movers = movers[:limit]
+	}
+	return movers, nil
+}
+
+// PrintAlphaHistory 输出一个 alpha 的指标历史
+func PrintAlphaHistory(alphaID string, snapshots []AlphaSnapshot) {
+	if len(snapshots) == 0 {
+		fmt.Println(i18n.T("history.empty", alphaID))
+		return
+	}
+
+	fmt.Println(i18n.T("history.title", alphaID))
+	fmt.Println(i18n.T("history.header"))
+	for _, s := range snapshots {
+		fmt.Printf("%-10s  %-15s  %10s  %10s  %10s  %10s  %10s  %s\n",
+			s.SnapshotDate.Format("2006-01-02"), s.Status,
+			formatMetric(s.IsSharpe), formatMetric(s.IsFitness),
+			formatMetric(s.OsIsSharpeRatio), formatMetric(s.OsPreCloseSharpeRatio),
+			formatMetric(s.IsProdCorrelation), pyramidThemesSummary(s.PyramidThemes))
+	}
+}
+
+// PrintAlphaMovers 输出变化排行
+func PrintAlphaMovers(metric string, from, to time.Time, movers []AlphaMover) {
+	fmt.Println(i18n.T("history.movers_title", metric, from.Format("2006-01-02"), to.Format("2006-01-02")))
+	if len(movers) == 0 {
+		fmt.Println(i18n.T("history.movers_empty"))
+		return
+	}
+
+	fmt.Println(i18n.T("history.movers_header"))
+	for i, m := range movers {
+		status := m.ToStatus
+		if m.FromStatus != m.ToStatus {
+			status = m.FromStatus + " -> " + m.ToStatus
+		}
+		fmt.Printf("%3d  %-10s  %9.4f  %9.4f  %+9.4f  %s\n", i+1, m.AlphaID, m.From, m.To, m.Change, status)
+	}
+}
+
+func formatMetric(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*value, 'f', 4, 64)
+}
+
+// 金字塔主题只显示 effective 和各金字塔名，完整 JSON 太长
+func pyramidThemesSummary(themes *string) string {
+	if themes == nil {
+		return "-"
+	}
+	var parsed models.PyramidThemes
+	if err := json.Unmarshal([]byte(*themes), &parsed); err != nil {
+		return *themes
+	}
+
+	names := make([]string, len(parsed.Pyramids))
+	for i, pyramid := range parsed.Pyramids {
+		names[i] = pyramid.Name
+	}
+	return fmt.Sprintf("%d [%s]", parsed.Effective, strings.Join(names, ", "))
+}
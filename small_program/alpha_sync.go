@@ -0,0 +1,193 @@
+package small_program
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+// ------------------------------------------------ 增量同步：按修改时间 -----------------------------------------------
+
+const (
+	// 保存在 sync_state 中的水位名称
+	alphaSyncName = "active_alpha_list"
+	// 列表接口每页最多 50 条
+	alphaSyncPageSize = 50
+	// 从水位往前多取一段，覆盖上次结束时修改时间相同但未返回的 alpha；重复的行比较后不会写入
+	alphaSyncOverlap = time.Minute
+	// 未提交的 alpha 不进入 active_alpha_list
+	alphaSyncStatusFilter = "UNSUBMITTED,IS-FAIL"
+)
+
+// SyncState 增量同步水位
+type SyncState struct {
+	Name      string     `json:"name" gorm:"column:name;primaryKey;size:64"`
+	Watermark *time.Time `json:"watermark" gorm:"column:watermark"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName 指定表名
+func (SyncState) TableName() string {
+	return "sync_state"
+}
+
+// AlphaSyncResult 一次增量同步的结果
+type AlphaSyncResult struct {
+	Fetched   int                `json:"fetched"`
+	Inserted  int                `json:"inserted"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	Watermark time.Time          `json:"watermark"`
+	Changes   AlphaChangeSummary `json:"changes"`
+}
+
+// SyncModifiedAlphas 增量同步：从保存的水位开始按 dateModified 升序拉取列表，
+// 只插入新的 alpha 和更新有字段变化的 alpha，每页处理完后推进水位。
+// 没有水位时从五年前开始；需要逐个重新拉取全部 alpha 时使用 UpdateExistingAlphas（修复模式）
+func SyncModifiedAlphas(ctx context.Context, deps Deps) (*AlphaSyncResult, error) {
+	logger := programLogger("ActiveAlpha")
+
+	// 1. 读取水位
+	watermark, ok, err := deps.SyncState.Watermark(ctx, alphaSyncName)
+	if err != nil {
+		return nil, i18n.Errorf("alpha.sync.watermark_failed", err)
+	}
+
+	from := watermark.Add(-alphaSyncOverlap)
+	if !ok {
+		from = time.Now().AddDate(-5, 0, 0)
+		logger.Info(i18n.T("alpha.sync.no_watermark"), "date_from", from.Format("2006-01-02 15:04:05"))
+	} else {
+		logger.Info(i18n.T("alpha.sync.start"), "watermark", watermark.Format(time.RFC3339))
+	}
+
+	result := &AlphaSyncResult{Watermark: watermark}
+	indexer := newAlphaFieldIndexer(ctx, deps)
+	// 出现失败后水位不再前进，下次从失败的 alpha 之前重新开始
+	stalled := false
+	offset := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		// 2. 按修改时间升序取一页
+		response, err := GetAlphas(deps.Config, deps.Token, models.GetAlphasRequest{
+			Limit:        alphaSyncPageSize,
+			Offset:       offset,
+			StatusFilter: alphaSyncStatusFilter,
+			ModifiedFrom: from,
+			Order:        "dateModified",
+		})
+		if err != nil {
+			return result, i18n.Errorf("common.fetch_alphas_failed", err)
+		}
+		if len(response.Results) == 0 {
+			break
+		}
+
+		// 3. 逐个与数据库比较，只写入新的或有变化的
+		now := time.Now()
+		pageLatest := from
+		var written []ActiveAlphaList
+		var changes []AlphaChange
+
+		for _, alpha := range response.Results {
+			result.Fetched++
+			modified, parsed := parseDBTime(alpha.DateModified)
+			if parsed && modified.After(pageLatest) {
+				pageLatest = modified
+			}
+
+			dbAlpha, alphaChanges, err := syncAlpha(ctx, deps, convertAlphaToDB(alpha), now)
+			switch {
+			case err != nil:
+				logger.Warn(i18n.T("alpha.sync.save_failed"), "alpha_id", alpha.ID, "error", err)
+				result.Failed++
+				stalled = true
+				continue
+			case dbAlpha == nil:
+				result.Unchanged++
+			case alphaChanges == nil:
+				result.Inserted++
+				written = append(written, *dbAlpha)
+			default:
+				result.Updated++
+				result.Changes.Add(alphaChanges)
+				changes = append(changes, alphaChanges...)
+				written = append(written, *dbAlpha)
+			}
+
+			if !stalled && parsed && modified.After(result.Watermark) {
+				result.Watermark = modified
+			}
+		}
+
+		// 记录字段变化和快照，失败只记录日志
+		if deps.Changes != nil && len(changes) > 0 {
+			if err := deps.Changes.Record(ctx, changes); err != nil {
+				logger.Warn(i18n.T("alpha.changes.record_failed"), "changes", len(changes), "error", err)
+			}
+		}
+		recordAlphaSnapshots(ctx, deps.Snapshots, written)
+		indexer.Index(ctx, written)
+
+		// 4. 每页处理完保存水位，中断后下次从这里继续
+		if result.Watermark.After(watermark) {
+			if err := deps.SyncState.SetWatermark(ctx, alphaSyncName, result.Watermark); err != nil {
+				return result, i18n.Errorf("alpha.sync.save_watermark_failed", err)
+			}
+			watermark = result.Watermark
+		}
+		logger.Info(i18n.T("alpha.sync.page_done"), "fetched", len(response.Results), "written", len(written), "watermark", result.Watermark.Format(time.RFC3339))
+
+		if len(response.Results) < alphaSyncPageSize {
+			break
+		}
+
+		// 5. 下一页从本页最大修改时间继续（含同一时刻的行）；
+		// 同一时刻的行超过一页时时间不会前进，改用 offset 翻页
+		if next := pageLatest.Add(-time.Millisecond); next.After(from) {
+			from = next
+			offset = 0
+		} else {
+			offset += alphaSyncPageSize
+		}
+
+		// 避免请求过于频繁
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	logger.Info(i18n.T("alpha.sync.done"), "fetched", result.Fetched, "inserted", result.Inserted, "updated", result.Updated, "unchanged", result.Unchanged, "failed", result.Failed)
+	logger.Info(result.Changes.String())
+	return result, nil
+}
+
+// 写入一个 alpha：数据库中没有时插入（返回的变化为 nil），有变化时更新并返回变化，没有变化时返回 nil 记录
+func syncAlpha(ctx context.Context, deps Deps, dbAlpha ActiveAlphaList, now time.Time) (*ActiveAlphaList, []AlphaChange, error) {
+	stored, err := deps.Alphas.Get(ctx, dbAlpha.ID)
+	if errors.Is(err, ErrNotFound) {
+		setCreateTime(&dbAlpha, now)
+		if _, err := deps.Alphas.CreateIfMissing(ctx, dbAlpha); err != nil {
+			return nil, nil, err
+		}
+		return &dbAlpha, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := diffAlpha(*stored, dbAlpha, now)
+	if len(changes) == 0 {
+		return nil, nil, nil
+	}
+	if _, err := deps.Alphas.Update(ctx, dbAlpha); err != nil {
+		return nil, nil, err
+	}
+	return &dbAlpha, changes, nil
+}
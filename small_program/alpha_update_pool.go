@@ -0,0 +1,283 @@
+package small_program
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 修复模式：并发重新拉取 -----------------------------------------------
+
+const (
+	// 每处理这么多个 alpha 批量写入一次字段变化和快照
+	alphaUpdateFlushSize = 20
+	// 进度日志的最小间隔
+	alphaUpdateProgressEvery = 5 * time.Second
+)
+
+// AlphaUpdateResult 一次修复模式更新的结果
+type AlphaUpdateResult struct {
+	Total     int                `json:"total"`
+	Updated   int                `json:"updated"`
+	Failed    []string           `json:"failed"`
+	RetryFile string             `json:"retry_file,omitempty"`
+	Elapsed   string             `json:"elapsed"`
+	Changes   AlphaChangeSummary `json:"changes"`
+}
+
+// 一个 alpha 的处理结果，alpha 为 nil 表示数据库中已没有该记录
+type alphaUpdateOutcome struct {
+	id      string
+	alpha   *ActiveAlphaList
+	changes []AlphaChange
+	err     error
+}
+
+// 并发拉取并更新 alphaIDs，结束后把失败的 ID 写入重试文件（全部成功时删除旧的重试文件）
+func updateAlphasConcurrently(ctx context.Context, deps Deps, alphaIDs []string) *AlphaUpdateResult {
+	logger := programLogger("ActiveAlpha")
+	workers := deps.Config.Sync.Workers()
+	interval := deps.Config.Sync.RequestInterval()
+	logger.Info(i18n.T("alpha.update.workers"), "workers", workers, "interval", interval.String())
+
+	start := time.Now()
+	result := &AlphaUpdateResult{Total: len(alphaIDs)}
+
+	// 1. 所有 worker 共用一个限速器，整体请求速率不超过配置
+	limiter := time.NewTicker(interval)
+	defer limiter.Stop()
+
+	jobs := make(chan string)
+	outcomes := make(chan alphaUpdateOutcome, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				select {
+				case <-limiter.C:
+					outcomes <- updateAlpha(ctx, deps, id)
+				case <-ctx.Done():
+					outcomes <- alphaUpdateOutcome{id: id, err: ctx.Err()}
+				}
+			}
+		}()
+	}
+
+	// 2. 分发任务，取消后不再分发
+	go func() {
+		defer close(jobs)
+		for _, id := range alphaIDs {
+			select {
+			case jobs <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	// 3. 汇总结果，按批写入字段变化、快照和字段索引，并定期输出进度
+	progress := newProgressCounter(len(alphaIDs))
+	processed := make(map[string]bool, len(alphaIDs))
+	var pendingAlphas []ActiveAlphaList
+	var pendingChanges []AlphaChange
+
+	// 已完成的更新在取消后也要记录变化、快照和字段索引
+	flushCtx := context.WithoutCancel(ctx)
+	indexer := newAlphaFieldIndexer(flushCtx, deps)
+	flush := func() {
+		if deps.Changes != nil && len(pendingChanges) > 0 {
+			if err := deps.Changes.Record(flushCtx, pendingChanges); err != nil {
+				logger.Warn(i18n.T("alpha.changes.record_failed"), "changes", len(pendingChanges), "error", err)
+			}
+		}
+		recordAlphaSnapshots(flushCtx, deps.Snapshots, pendingAlphas)
+		indexer.Index(flushCtx, pendingAlphas)
+		pendingAlphas, pendingChanges = nil, nil
+	}
+
+	for outcome := range outcomes {
+		processed[outcome.id] = true
+		switch {
+		case outcome.err != nil:
+			result.Failed = append(result.Failed, outcome.id)
+		case outcome.alpha != nil:
+			result.Updated++
+			result.Changes.Add(outcome.changes)
+			pendingAlphas = append(pendingAlphas, *outcome.alpha)
+			pendingChanges = append(pendingChanges, outcome.changes...)
+		}
+
+		progress.Add(outcome.err != nil)
+		if len(pendingAlphas) >= alphaUpdateFlushSize {
+			flush()
+		}
+		if progress.Due(alphaUpdateProgressEvery) {
+			logger.Info(i18n.T("alpha.update.progress"), progress.Attrs()...)
+		}
+	}
+	flush()
+	logger.Info(i18n.T("alpha.update.progress"), progress.Attrs()...)
+
+	// 取消时尚未分发的 ID 同样需要重试
+	for _, id := range alphaIDs {
+		if !processed[id] {
+			result.Failed = append(result.Failed, id)
+		}
+	}
+
+	// 4. 保存重试列表
+	path := deps.Config.Sync.RetryPath()
+	if err := writeRetryFile(path, result.Failed); err != nil {
+		logger.Warn(i18n.T("alpha.update.retry_write_failed"), "path", path, "error", err)
+	} else if len(result.Failed) > 0 {
+		result.RetryFile = path
+		logger.Warn(i18n.T("alpha.update.retry_written"), "path", path, "failed", len(result.Failed))
+	}
+
+	result.Elapsed = time.Since(start).Round(time.Second).String()
+	return result
+}
+
+// 拉取一个 alpha 并更新数据库，返回与更新前相比的字段变化
+func updateAlpha(ctx context.Context, deps Deps, alphaID string) alphaUpdateOutcome {
+	logger := programLogger("ActiveAlpha")
+
+	alpha, err := GetAlphaByID(deps.Config, deps.Token, alphaID)
+	if err != nil {
+		logger.Warn(i18n.T("alpha.update.fetch_failed"), "alpha_id", alphaID, "error", err)
+		return alphaUpdateOutcome{id: alphaID, err: err}
+	}
+
+	// 转换为数据库结构
+	dbAlpha := convertAlphaToDB(alpha)
+
+	// 读取更新前的数据用于比较
+	stored, err := deps.Alphas.Get(ctx, alphaID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		logger.Warn(i18n.T("alpha.changes.load_failed"), "alpha_id", alphaID, "error", err)
+	}
+
+	// 更新数据库（只更新，不创建）
+	updated, err := deps.Alphas.Update(ctx, dbAlpha)
+	if err != nil {
+		logger.Warn(i18n.T("alpha.update.save_failed"), "alpha_id", alphaID, "error", err)
+		return alphaUpdateOutcome{id: alphaID, err: err}
+	}
+	if !updated {
+		return alphaUpdateOutcome{id: alphaID}
+	}
+	logger.Debug(i18n.T("alpha.update.updated"), "alpha_id", alphaID)
+
+	outcome := alphaUpdateOutcome{id: alphaID, alpha: &dbAlpha}
+	if stored != nil {
+		outcome.changes = diffAlpha(*stored, dbAlpha, time.Now())
+	}
+	return outcome
+}
+
+// ---- 进度 ----
+
+// 已处理数量、失败数量和按平均速度估算的剩余时间
+type progressCounter struct {
+	total   int
+	done    int
+	failed  int
+	start   time.Time
+	lastLog time.Time
+}
+
+func newProgressCounter(total int) *progressCounter {
+	now := time.Now()
+	return &progressCounter{total: total, start: now, lastLog: now}
+}
+
+func (p *progressCounter) Add(failed bool) {
+	p.done++
+	if failed {
+		p.failed++
+	}
+}
+
+// Due 距上次输出超过 every 时返回 true 并重新计时
+func (p *progressCounter) Due(every time.Duration) bool {
+	if time.Since(p.lastLog) < every {
+		return false
+	}
+	p.lastLog = time.Now()
+	return true
+}
+
+// ETA 按已处理的平均速度估算剩余时间
+func (p *progressCounter) ETA() time.Duration {
+	if p.done == 0 {
+		return 0
+	}
+	elapsed := time.Since(p.start)
+	return elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)
+}
+
+// Attrs 进度日志的字段
+func (p *progressCounter) Attrs() []any {
+	rate := float64(p.done) / time.Since(p.start).Seconds()
+	return []any{
+		"done", p.done,
+		"total", p.total,
+		"failed", p.failed,
+		"rate", fmt.Sprintf("%.1f/s", rate),
+		"eta", p.ETA().Round(time.Second).String(),
+	}
+}
+
+// ---- 重试列表 ----
+
+// 每行一个 alpha ID；ids 为空时删除已有的文件
+func writeRetryFile(path string, ids []string) error {
+	if len(ids) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(ids, "\n")+"\n"), 0o644)
+}
+
+// 读取重试文件，跳过空行和 # 开头的注释；文件不存在时返回空列表
+func readRetryFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, scanner.Err()
+}
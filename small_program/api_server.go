@@ -0,0 +1,697 @@
+package small_program
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"program-collection/credentials"
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+// ------------------------------------------------ HTTP API 服务 -----------------------------------------------
+
+// APIServer 对外提供 JSON 接口，所有请求共用同一组数据访问接口和 BRAIN token
+type APIServer struct {
+	deps   Deps
+	config models.Config
+
+	// BRAIN 会话会过期，配置了 deps.SignIn 时每次触发程序前重新登录；
+	// 字段检查等接口在 BRAIN 返回 401 时通过 deps.SignIn 重新登录，新的 token 留给之后的请求
+	tokenMu sync.Mutex
+	token   string
+
+	// 每个程序同一时间只允许运行一个
+	jobMu   sync.Mutex
+	running map[string]bool
+
+	// /api/similar 按过滤条件缓存的结构索引，程序运行后清空，超过 similarIndexTTL 重建
+	similarMu      sync.Mutex
+	similarIndexes map[string]cachedSimilarityIndex
+}
+
+// 命令行或定时任务也会修改 active_alpha_list，缓存的索引最多使用这么久
+const similarIndexTTL = 10 * time.Minute
+
+type cachedSimilarityIndex struct {
+	index *SimilarityIndex
+	built time.Time
+}
+
+// NewAPIServer 创建 API 服务，deps 中的数据库连接由调用方负责关闭
+func NewAPIServer(deps Deps) (*APIServer, error) {
+	config := deps.Config
+	if config.Server.Token == "" && (config.Server.Username == "" || config.Server.Password == "") {
+		return nil, i18n.Errorf("server.auth_required")
+	}
+
+	s := &APIServer{
+		deps:    deps,
+		config:  config,
+		token:   deps.Token,
+		running: make(map[string]bool),
+
+		similarIndexes: make(map[string]cachedSimilarityIndex),
+	}
+	if signIn := deps.SignIn; signIn != nil {
+		s.deps.SignIn = func() (string, error) {
+			token, err := signIn()
+			if err == nil {
+				s.tokenMu.Lock()
+				s.token = token
+				s.tokenMu.Unlock()
+			}
+			return token, err
+		}
+	}
+	return s, nil
+}
+
+// RunAPIServer 启动 HTTP API 服务，阻塞直到服务退出
+func RunAPIServer(deps Deps) error {
+	fmt.Println(i18n.T("server.banner"))
+
+	server, err := NewAPIServer(deps)
+	if err != nil {
+		return err
+	}
+
+	addr := deps.Config.Server.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// 收到 Ctrl+C 或 SIGTERM 后不再接受新请求，等正在运行的程序写完数据库再退出；再次 Ctrl+C 立即退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		i18n.Printf("server.listening", addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	programLogger("APIServer").Info(i18n.T("server.shutting_down"))
+	if err := httpServer.Shutdown(context.Background()); err != nil {
+		return i18n.Errorf("server.shutdown_failed", err)
+	}
+	programLogger("APIServer").Info(i18n.T("server.stopped"))
+	return nil
+}
+
+// 当前 token 对应的依赖
+func (s *APIServer) brainDeps() Deps {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	deps := s.deps
+	deps.Token = s.token
+	return deps
+}
+
+// 重新登录并替换 token，没有配置 SignIn 时沿用启动时的 token
+func (s *APIServer) refreshToken() (Deps, error) {
+	if s.deps.SignIn == nil {
+		return s.brainDeps(), nil
+	}
+	if _, err := s.deps.SignIn(); err != nil {
+		return Deps{}, i18n.Errorf("server.signin_failed", err)
+	}
+	return s.brainDeps(), nil
+}
+
+// Handler 返回注册好全部路由的 http.Handler
+func (s *APIServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.Handle("GET /metrics", s.auth(MetricsHandler(&s.deps.Repos).ServeHTTP))
+
+	// 数据查询
+	mux.Handle("GET /api/alphas", s.auth(s.handleListAlphas))
+	mux.Handle("GET /api/alphas/{id}", s.auth(s.handleGetAlpha))
+	mux.Handle("GET /api/alphas/{id}/history", s.auth(s.handleAlphaHistory))
+	mux.Handle("GET /api/alphas/{id}/changes", s.auth(s.handleAlphaChanges))
+	mux.Handle("GET /api/factors/latest", s.auth(s.handleLatestFactor))
+	mux.Handle("GET /api/pyramids", s.auth(s.handleListPyramids))
+	mux.Handle("GET /api/operator-stats", s.auth(s.handleOperatorStats))
+
+	// 字段检查
+	mux.Handle("POST /api/field-check", s.auth(s.handleFieldCheck))
+	mux.Handle("POST /api/field-check/batch", s.auth(s.handleFieldCheckBatch))
+	mux.Handle("POST /api/similar", s.auth(s.handleSimilar))
+
+	// 触发各个程序
+	mux.Handle("POST /api/programs/fetch-alphas", s.auth(s.job("fetch-alphas", s.runFetchAlphas)))
+	mux.Handle("POST /api/programs/update-alphas", s.auth(s.job("update-alphas", s.runUpdateAlphas)))
+	mux.Handle("POST /api/programs/retry-alphas", s.auth(s.job("retry-alphas", s.runRetryAlphas)))
+	mux.Handle("POST /api/programs/sync-alphas", s.auth(s.job("sync-alphas", s.runSyncAlphas)))
+	mux.Handle("POST /api/programs/weight-value-factor", s.auth(s.job("weight-value-factor", s.runWeightValueFactor)))
+	mux.Handle("POST /api/programs/pyramid", s.auth(s.job("pyramid", s.runPyramid)))
+	mux.Handle("POST /api/programs/operators", s.auth(s.job("operators", s.runOperators)))
+	mux.Handle("POST /api/programs/prod-corr", s.auth(s.job("prod-corr", s.runProdCorr)))
+
+	return mux
+}
+
+// ------------------------------------------------ 认证与通用处理 -----------------------------------------------
+
+// 校验 API token（Authorization: Bearer / X-API-Token）或 Basic Auth
+func (s *APIServer) auth(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.authorized(r) {
+			next(w, r)
+			return
+		}
+
+		if s.config.Server.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="program-collection"`)
+		}
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+	})
+}
+
+func (s *APIServer) authorized(r *http.Request) bool {
+	if expected := s.config.Server.Token; expected != "" {
+		token := r.Header.Get("X-API-Token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+		if token != "" && secureEqual(token, expected) {
+			return true
+		}
+	}
+
+	if s.config.Server.Username != "" && s.config.Server.Password != "" {
+		username, password, ok := r.BasicAuth()
+		if ok && secureEqual(username, s.config.Server.Username) && secureEqual(password, s.config.Server.Password) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// 包装一个程序触发接口：同名程序运行中时返回 409
+func (s *APIServer) job(name string, run func(ctx context.Context, deps Deps, r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.jobMu.Lock()
+		if s.running[name] {
+			s.jobMu.Unlock()
+			writeError(w, http.StatusConflict, fmt.Sprintf("%s is already running", name))
+			return
+		}
+		s.running[name] = true
+		s.jobMu.Unlock()
+
+		defer func() {
+			s.jobMu.Lock()
+			delete(s.running, name)
+			s.jobMu.Unlock()
+			s.clearSimilarIndexes()
+		}()
+
+		// 程序运行期间客户端断开时不中断任务
+		ctx := context.WithoutCancel(r.Context())
+
+		start := time.Now()
+		var result any
+		err := ObserveJob(name, func() error {
+			// 启动时的会话可能已经过期，每次运行前重新登录
+			deps, err := s.refreshToken()
+			if err != nil {
+				return err
+			}
+			result, err = run(ctx, deps, r)
+			return err
+		})
+		if err != nil {
+			var badRequest badRequestError
+			if errors.As(err, &badRequest) {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			programLogger("APIServer").Error(i18n.T("server.job_failed"), "job", name, "error", err)
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"program":     name,
+			"duration_ms": time.Since(start).Milliseconds(),
+			"result":      result,
+		})
+	}
+}
+
+// 参数错误，返回 400
+type badRequestError struct{ msg string }
+
+func (e badRequestError) Error() string { return e.msg }
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		programLogger("APIServer").Warn(i18n.T("server.write_failed"), "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": credentials.Redact(msg)})
+}
+
+// 解析请求体 JSON，请求体为空时保持 v 不变
+func decodeBody(r *http.Request, v any) error {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequestError{msg: fmt.Sprintf("invalid JSON body: %v", err)}
+	}
+	return nil
+}
+
+// ------------------------------------------------ 查询接口 -----------------------------------------------
+
+func (s *APIServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if ping := s.deps.Ping; ping != nil {
+		if err := ping(r.Context()); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// GET /api/alphas?type=&status=&stage=&region=&universe=&delay=&author=&limit=&offset=
+func (s *APIServer) handleListAlphas(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit, err := queryInt(q.Get("limit"), 50)
+	if err != nil || limit <= 0 || limit > 500 {
+		writeError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+		return
+	}
+	offset, err := queryInt(q.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	list := func(column string) []string {
+		if value := q.Get(column); value != "" {
+			return strings.Split(value, ",")
+		}
+		return nil
+	}
+	filter := AlphaFilter{
+		Types:     list("type"),
+		Statuses:  list("status"),
+		Stages:    list("stage"),
+		Regions:   list("region"),
+		Universes: list("universe"),
+		Authors:   list("author"),
+		Limit:     limit,
+		Offset:    offset,
+	}
+	if value := q.Get("delay"); value != "" {
+		delay, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "delay must be an integer")
+			return
+		}
+		filter.Delay = &delay
+	}
+
+	alphas, total, err := s.deps.Alphas.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"count":   total,
+		"limit":   limit,
+		"offset":  offset,
+		"results": alphas,
+	})
+}
+
+// GET /api/alphas/{id}
+func (s *APIServer) handleGetAlpha(w http.ResponseWriter, r *http.Request) {
+	alpha, err := s.deps.Alphas.Get(r.Context(), r.PathValue("id"))
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, "alpha not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, alpha)
+}
+
+// GET /api/alphas/{id}/history
+func (s *APIServer) handleAlphaHistory(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := AlphaHistory(r.Context(), s.deps.Snapshots, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"alpha_id": r.PathValue("id"),
+		"results":  snapshots,
+	})
+}
+
+// GET /api/alphas/{id}/changes
+func (s *APIServer) handleAlphaChanges(w http.ResponseWriter, r *http.Request) {
+	changes, err := s.deps.Changes.ForAlpha(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"alpha_id": r.PathValue("id"),
+		"results":  changes,
+	})
+}
+
+// GET /api/factors/latest?user_id=
+func (s *APIServer) handleLatestFactor(w http.ResponseWriter, r *http.Request) {
+	latest, err := s.deps.Factors.Latest(r.Context(), r.URL.Query().Get("user_id"))
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, "no weight/value factor recorded yet")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, latest)
+}
+
+// GET /api/pyramids?quarter=&user_id=
+func (s *APIServer) handleListPyramids(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	quarter := q.Get("quarter")
+	if quarter != "" && !isValidQuarterFormat(quarter) {
+		writeError(w, http.StatusBadRequest, "quarter must look like 2025-Q3")
+		return
+	}
+
+	records, err := s.deps.Pyramids.List(r.Context(), q.Get("user_id"), quarter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	total := 0
+	for _, record := range records {
+		total += record.AlphaCount
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_alpha_count": total,
+		"results":           records,
+	})
+}
+
+// GET /api/operator-stats?type=&region=&level=&quarter=
+// level、quarter 为空时使用配置中的 genius 设置
+func (s *APIServer) handleOperatorStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var filter AlphaFilter
+	if value := q.Get("type"); value != "" {
+		filter.Types = strings.Split(value, ",")
+	}
+	if value := q.Get("region"); value != "" {
+		filter.Regions = strings.Split(value, ",")
+	}
+
+	stats, err := OperatorUsageStats(r.Context(), s.deps, filter, cmp.Or(q.Get("level"), s.config.Genius.Level), cmp.Or(q.Get("quarter"), s.config.Genius.Quarter))
+	if err != nil {
+		// 等级、季度写错是请求的问题，读取数据库失败是服务端的问题
+		status := http.StatusInternalServerError
+		if isGeniusScopeError(err) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// POST /api/field-check {"input": "...", "scope": {"from": "2025-09-01", "to": "2025-09-30", "types": ["REGULAR"], "regions": ["USA"], "delay": 1}}
+// scope 为空时使用配置中的 fieldCheck
+func (s *APIServer) handleFieldCheck(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Input string             `json:"input"`
+		Scope *models.FieldCheck `json:"scope"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(body.Input) == "" {
+		writeError(w, http.StatusBadRequest, "input is required")
+		return
+	}
+
+	check := s.config.FieldCheck
+	if body.Scope != nil {
+		check = *body.Scope
+	}
+	scope, err := NewFieldCheckScope(check)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := CheckFieldUsage(r.Context(), s.brainDeps(), body.Input, scope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// POST /api/field-check/batch {"inputs": ["...", "..."], "scope": {...}}，返回每个输入的结果
+func (s *APIServer) handleFieldCheckBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Inputs []string           `json:"inputs"`
+		Scope  *models.FieldCheck `json:"scope"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	inputs := slices.DeleteFunc(body.Inputs, func(input string) bool { return strings.TrimSpace(input) == "" })
+	if len(inputs) == 0 {
+		writeError(w, http.StatusBadRequest, "inputs is required")
+		return
+	}
+
+	check := s.config.FieldCheck
+	if body.Scope != nil {
+		check = *body.Scope
+	}
+	scope, err := NewFieldCheckScope(check)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := BatchFieldCheck(r.Context(), s.brainDeps(), inputs, scope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(items), "results": items})
+}
+
+// POST /api/similar {"input": "...", "top": 10, "min_score": 0.2, "type": "REGULAR", "region": "USA"}
+// 返回结构最相似的已保存 alpha，top 为 0 时默认 10；type、region 可选，只与该类型、地区的 alpha 比较
+func (s *APIServer) handleSimilar(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Input    string  `json:"input"`
+		Top      int     `json:"top"`
+		MinScore float64 `json:"min_score"`
+		Type     string  `json:"type"`
+		Region   string  `json:"region"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(body.Input) == "" {
+		writeError(w, http.StatusBadRequest, "input is required")
+		return
+	}
+	if body.Top <= 0 {
+		body.Top = 10
+	}
+
+	var filter AlphaFilter
+	if body.Type != "" {
+		filter.Types = []string{strings.ToUpper(body.Type)}
+	}
+	if body.Region != "" {
+		filter.Regions = []string{strings.ToUpper(body.Region)}
+	}
+	index, err := s.similarityIndex(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	similar, err := index.Search(body.Input, body.Top, body.MinScore)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"input": body.Input, "similar": similar})
+}
+
+// 返回 filter（只使用 Types、Regions）对应的结构索引，没有缓存或已过期时重新读取数据库；
+// 构建期间持有锁，同时到达的相同请求只构建一次
+func (s *APIServer) similarityIndex(ctx context.Context, filter AlphaFilter) (*SimilarityIndex, error) {
+	key := strings.Join(filter.Types, ",") + "|" + strings.Join(filter.Regions, ",")
+
+	s.similarMu.Lock()
+	defer s.similarMu.Unlock()
+	if cached, ok := s.similarIndexes[key]; ok && time.Since(cached.built) < similarIndexTTL {
+		return cached.index, nil
+	}
+
+	index, err := NewSimilarityIndex(ctx, s.deps.Alphas, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.similarIndexes[key] = cachedSimilarityIndex{index: index, built: time.Now()}
+	return index, nil
+}
+
+// 程序可能改变了 active_alpha_list，清空缓存的结构索引
+func (s *APIServer) clearSimilarIndexes() {
+	s.similarMu.Lock()
+	clear(s.similarIndexes)
+	s.similarMu.Unlock()
+}
+
+// ------------------------------------------------ 程序触发接口 -----------------------------------------------
+
+func (s *APIServer) runFetchAlphas(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	return nil, FetchNewAlphas(ctx, deps)
+}
+
+func (s *APIServer) runUpdateAlphas(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	return UpdateExistingAlphas(ctx, deps)
+}
+
+func (s *APIServer) runRetryAlphas(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	return RetryFailedAlphas(ctx, deps)
+}
+
+func (s *APIServer) runSyncAlphas(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	return SyncModifiedAlphas(ctx, deps)
+}
+
+func (s *APIServer) runWeightValueFactor(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	return RecordWeightValueFactor(ctx, deps)
+}
+
+// {"quarter": "2025-Q3", "user_id": "XX12345"}
+func (s *APIServer) runPyramid(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	var body struct {
+		Quarter string `json:"quarter"`
+		UserID  string `json:"user_id"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	if !isValidQuarterFormat(body.Quarter) || body.UserID == "" {
+		return nil, badRequestError{msg: "quarter (e.g. 2025-Q3) and user_id are required"}
+	}
+
+	return SnapshotPyramidAlphas(ctx, deps, body.Quarter, body.UserID)
+}
+
+// {"genius_level": "Gold", "genius_quarter": "2025-Q3"}
+func (s *APIServer) runOperators(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	var body struct {
+		GeniusLevel   string `json:"genius_level"`
+		GeniusQuarter string `json:"genius_quarter"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	if body.GeniusLevel == "" || body.GeniusQuarter == "" {
+		return nil, badRequestError{msg: "genius_level and genius_quarter are required"}
+	}
+
+	count, err := ReloadOperators(ctx, deps, body.GeniusLevel, body.GeniusQuarter)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"operator_count": count}, nil
+}
+
+// {"date_from": "2025-10-01", "date_to": "2025-11-01"}，日期按 BRAIN 前端规则（UTC-5）解释
+func (s *APIServer) runProdCorr(ctx context.Context, deps Deps, r *http.Request) (any, error) {
+	var body struct {
+		DateFrom string `json:"date_from"`
+		DateTo   string `json:"date_to"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	dateFrom, err := ConvertToUTCPlus5(body.DateFrom + " 00:00:00")
+	if err != nil {
+		return nil, badRequestError{msg: "date_from must look like 2025-10-01"}
+	}
+	dateTo, err := ConvertToUTCPlus5(body.DateTo + " 00:00:00")
+	if err != nil {
+		return nil, badRequestError{msg: "date_to must look like 2025-11-01"}
+	}
+
+	return GetProdCorrStats(s.config, deps.Token, dateFrom, dateTo)
+}
+
+func queryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
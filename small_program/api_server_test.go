@@ -0,0 +1,143 @@
+package small_program
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"program-collection/models"
+)
+
+func TestHandleSimilar(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemoryRepos()
+	addAlpha := func(id, region, code string) {
+		if _, err := repos.Alphas.CreateIfMissing(ctx, ActiveAlphaList{ID: id, Type: "REGULAR", Author: "XX1", Region: ptr(region), RegularCode: ptr(code)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addAlpha("USA1", "USA", "rank(ts_mean(close, 20))")
+	addAlpha("CHN1", "CHN", "rank(ts_mean(close, 60))")
+
+	server, err := NewAPIServer(Deps{Config: models.Config{Server: models.Server{Token: "t"}}, Repos: repos})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search := func(body string) []string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		server.handleSimilar(w, httptest.NewRequest(http.MethodPost, "/api/similar", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST /api/similar %s = %d %s", body, w.Code, w.Body)
+		}
+		var resp struct {
+			Similar []SimilarAlpha `json:"similar"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, alpha := range resp.Similar {
+			ids = append(ids, alpha.AlphaID)
+		}
+		return ids
+	}
+
+	const input = `{"input": "rank(ts_mean(close, 20))"}`
+	if ids := search(input); len(ids) != 2 || ids[0] != "USA1" {
+		t.Errorf("similar = %v, want [USA1 CHN1]", ids)
+	}
+	if ids := search(`{"input": "rank(ts_mean(close, 20))", "region": "chn"}`); len(ids) != 1 || ids[0] != "CHN1" {
+		t.Errorf("similar in CHN = %v, want [CHN1]", ids)
+	}
+
+	// 索引已缓存，新增的 alpha 在程序运行后（清空缓存）才参与比较
+	addAlpha("USA2", "USA", "rank(ts_mean(close, 20)) * 2")
+	if ids := search(input); len(ids) != 2 {
+		t.Errorf("similar with cached index = %v, want 2 alphas", ids)
+	}
+	server.clearSimilarIndexes()
+	if ids := search(input); len(ids) != 3 {
+		t.Errorf("similar after clear = %v, want 3 alphas", ids)
+	}
+}
+
+// BRAIN 会话过期后，字段检查获取未保存的 alpha 时重新登录一次，新的 token 留给之后的请求
+func TestHandleFieldCheckRefreshesToken(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	brain.Put(brainAlpha("ab12XYz", "ts_mean(returns, 20)", time.Hour, time.Hour, 1.5))
+	brain.token = "fresh-token"
+
+	signIns := 0
+	deps.Config.Server.Token = "t"
+	deps.SignIn = func() (string, error) {
+		signIns++
+		return "fresh-token", nil
+	}
+	server, err := NewAPIServer(deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		server.handleFieldCheck(w, httptest.NewRequest(http.MethodPost, "/api/field-check", strings.NewReader(`{"input": "ab12XYz"}`)))
+		var result FieldCheckResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("POST /api/field-check = %d %s", w.Code, w.Body)
+		}
+		if result.AlphaID != "ab12XYz" || result.FetchError != "" {
+			t.Errorf("request %d: alpha %q, fetch error %q", i+1, result.AlphaID, result.FetchError)
+		}
+	}
+	if signIns != 1 {
+		t.Errorf("signed in %d times, want 1", signIns)
+	}
+}
+
+// 读取 operators 表失败的仓库
+type failingOperatorRepo struct{ OperatorRepo }
+
+func (failingOperatorRepo) List(context.Context) ([]Operators, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestHandleOperatorStatsStatus(t *testing.T) {
+	repos := NewMemoryRepos()
+	operators := []Operators{{Name: "rank", Category: "Cross Sectional", GeniusLevel: "GOLD", GeniusQuarter: "2025-Q3"}}
+	if err := repos.Operators.Replace(context.Background(), "GOLD", "2025-Q3", operators); err != nil {
+		t.Fatal(err)
+	}
+	broken := NewMemoryRepos()
+	broken.Operators = failingOperatorRepo{broken.Operators}
+
+	tests := []struct {
+		name  string
+		repos Repos
+		query string
+		want  int
+	}{
+		{"ok", repos, "", http.StatusOK},
+		{"bad level", repos, "?level=DIAMOND", http.StatusBadRequest},
+		{"missing quarter", repos, "?quarter=2024-Q1", http.StatusBadRequest},
+		{"repository error", broken, "", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := NewAPIServer(Deps{Config: models.Config{Server: models.Server{Token: "t"}}, Repos: tt.repos})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			server.handleOperatorStats(w, httptest.NewRequest(http.MethodGet, "/api/operator-stats"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Errorf("GET /api/operator-stats%s = %d %s, want %d", tt.query, w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
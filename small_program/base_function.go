@@ -1,8 +1,9 @@
 package small_program
 
 import (
-	"fmt"
 	"time"
+
+	"program-collection/i18n"
 )
 
 // --------------------------------- worldquantbrain 同前端页面 alpha 提交时间规则 ------------------------------
@@ -14,7 +15,7 @@ func ConvertToUTCPlus5(input string) (time.Time, error) {
 	// 直接解析为完整时间格式
 	t, err := time.Parse("2006-01-02 15:04:05", input)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("无法解析时间格式: %s", input)
+		return time.Time{}, i18n.Errorf("common.time_parse_failed", input)
 	}
 
 	// UTC-5时区
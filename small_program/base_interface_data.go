@@ -2,12 +2,14 @@ package small_program
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 
+	"program-collection/i18n"
 	"program-collection/models"
 )
 
@@ -34,6 +36,10 @@ func GetAlphas(config models.Config, token string, req models.GetAlphasRequest)
 		params.Add("dateSubmitted<", req.DateTo.UTC().Format("2006-01-02T15:04:05.000Z"))
 	}
 
+	if !req.ModifiedFrom.IsZero() {
+		params.Add("dateModified>", req.ModifiedFrom.UTC().Format("2006-01-02T15:04:05.000Z"))
+	}
+
 	if req.Order != "" {
 		params.Add("order", req.Order)
 	}
@@ -56,7 +62,7 @@ func GetAlphas(config models.Config, token string, req models.GetAlphasRequest)
 	// 创建请求
 	httpReq, err := http.NewRequest("GET", urL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %v", err)
+		return nil, i18n.Errorf("common.create_request_failed", err)
 	}
 
 	// 设置认证头
@@ -64,27 +70,26 @@ func GetAlphas(config models.Config, token string, req models.GetAlphasRequest)
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(httpReq)
+	resp, err := doBrainRequest("alpha_list", httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		return nil, i18n.Errorf("common.request_failed", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+		return nil, i18n.Errorf("common.read_response_failed", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API返回错误: %s, 状态码: %d", string(body), resp.StatusCode)
+		return nil, i18n.Errorf("brain.api_error", string(body), resp.StatusCode)
 	}
 
 	// 解析JSON
 	var alphaResponse models.AlphaListResponse
 	if err := json.Unmarshal(body, &alphaResponse); err != nil {
-		return nil, fmt.Errorf("解析JSON失败: %v", err)
+		return nil, i18n.Errorf("brain.decode_json_failed", err)
 	}
 
 	return &alphaResponse, nil
@@ -121,39 +126,63 @@ func GetAllAlphas(config models.Config, token string, req models.GetAlphasReques
 	return allAlphas, nil
 }
 
+// BRAIN 返回的非 200 状态码，调用方据此区分会话过期（401）和 alpha 不存在（404）
+type brainStatusError struct {
+	StatusCode int
+	err        error
+}
+
+func (e brainStatusError) Error() string { return e.err.Error() }
+
+// 用 deps.Token 调用 BRAIN 接口；会话过期（401）且配置了 deps.SignIn 时重新登录后再试一次，
+// 新的 token 写回 deps，之后的调用不必再次登录
+func retryOnUnauthorized[T any](deps *Deps, call func(token string) (T, error)) (T, error) {
+	result, err := call(deps.Token)
+	var statusErr brainStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized || deps.SignIn == nil {
+		return result, err
+	}
+
+	token, signInErr := deps.SignIn()
+	if signInErr != nil {
+		return result, err
+	}
+	deps.Token = token
+	return call(token)
+}
+
 // 1.3 按照 alpha_id 获取 alpha信息
 func GetAlphaByID(config models.Config, token, alphaID string) (alpha models.Alpha, err error) {
 
 	url := fmt.Sprintf("%s%s/%s", config.Third.Addr, config.Paths.Alpha, alphaID)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return models.Alpha{}, fmt.Errorf("create request failed: %v", err)
+		return models.Alpha{}, i18n.Errorf("common.create_request_failed", err)
 	}
 
 	// 设置认证头（使用Cookie方式）
 	req.Header.Set("Cookie", fmt.Sprintf("t=%s", token))
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doBrainRequest("alpha", req)
 	if err != nil {
-		return models.Alpha{}, fmt.Errorf("request failed: %v", err)
+		return models.Alpha{}, i18n.Errorf("common.request_failed", err)
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return models.Alpha{}, fmt.Errorf("read response body failed: %v", err)
+		return models.Alpha{}, i18n.Errorf("common.read_response_failed", err)
 	}
 
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
-		return models.Alpha{}, fmt.Errorf("fetch alpha failed: status %d, response: %s", resp.StatusCode, string(bodyBytes))
+		return models.Alpha{}, brainStatusError{StatusCode: resp.StatusCode, err: i18n.Errorf("brain.fetch_alpha_failed", resp.StatusCode, string(bodyBytes))}
 	}
 
 	var alphaInfo models.Alpha
 	if err := json.Unmarshal(bodyBytes, &alphaInfo); err != nil {
-		return models.Alpha{}, fmt.Errorf("decode failed: %v, response: %s", err, string(bodyBytes))
+		return models.Alpha{}, i18n.Errorf("brain.decode_failed_body", err, string(bodyBytes))
 	}
 
 	return alphaInfo, nil
@@ -165,33 +194,32 @@ func FetchOperators(config models.Config, token string) ([]models.Operator, erro
 	url := fmt.Sprintf("%s%s", config.Third.Addr, config.Paths.Operator)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request failed: %v", err)
+		return nil, i18n.Errorf("common.create_request_failed", err)
 	}
 
 	// 设置认证头（使用Cookie方式）
 	req.Header.Set("Cookie", fmt.Sprintf("t=%s", token))
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doBrainRequest("operators", req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, i18n.Errorf("common.request_failed", err)
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response body failed: %v", err)
+		return nil, i18n.Errorf("common.read_response_failed", err)
 	}
 
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetch operators failed: status %d, response: %s", resp.StatusCode, string(bodyBytes))
+		return nil, brainStatusError{StatusCode: resp.StatusCode, err: i18n.Errorf("brain.fetch_operators_failed", resp.StatusCode, string(bodyBytes))}
 	}
 
 	var operators []models.Operator
 	if err := json.Unmarshal(bodyBytes, &operators); err != nil {
-		return nil, fmt.Errorf("decode failed: %v, response: %s", err, string(bodyBytes))
+		return nil, i18n.Errorf("brain.decode_failed_body", err, string(bodyBytes))
 	}
 
 	return operators, nil
@@ -205,7 +233,7 @@ func FetchConsultant(config models.Config, token string) (*models.ConsultantResp
 	// 创建 HTTP 请求
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request failed: %v", err)
+		return nil, i18n.Errorf("common.create_request_failed", err)
 	}
 
 	// 设置认证头（使用Cookie方式）
@@ -213,29 +241,28 @@ func FetchConsultant(config models.Config, token string) (*models.ConsultantResp
 	req.Header.Set("Content-Type", "application/json")
 
 	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doBrainRequest("consultant", req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, i18n.Errorf("common.request_failed", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取响应体
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response body failed: %v", err)
+		return nil, i18n.Errorf("common.read_response_failed", err)
 	}
 
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetch consultant failed: status %d, response: %s",
+		return nil, i18n.Errorf("brain.fetch_consultant_failed",
 			resp.StatusCode, string(bodyBytes))
 	}
 
 	// 解析JSON响应
 	var consultantResp models.ConsultantResponse
 	if err := json.Unmarshal(bodyBytes, &consultantResp); err != nil {
-		return nil, fmt.Errorf("decode failed: %v, response: %s", err, string(bodyBytes))
+		return nil, i18n.Errorf("brain.decode_failed_body", err, string(bodyBytes))
 	}
 
 	return &consultantResp, nil
@@ -247,25 +274,24 @@ func PyramidInfo(config models.Config, token string, beginDate, endDate string)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request failed: %v", err)
+		return nil, i18n.Errorf("common.create_request_failed", err)
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doBrainRequest("pyramid_alphas", req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, i18n.Errorf("common.request_failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("fetch pyramid failed: %s\n%s", resp.Status, string(bodyBytes))
+		return nil, i18n.Errorf("brain.fetch_pyramid_failed", resp.Status, string(bodyBytes))
 	}
 
 	var alphaInfo models.PyramidsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&alphaInfo); err != nil {
-		return nil, fmt.Errorf("decode failed: %v", err)
+		return nil, i18n.Errorf("brain.decode_failed", err)
 	}
 
 	return alphaInfo.Pyramids, nil
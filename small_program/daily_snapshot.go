@@ -0,0 +1,53 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+
+	"program-collection/i18n"
+)
+
+// ------------------------------------------ 每日快照：wf/vf 与金字塔 ------------------------------------------
+
+// DailySnapshotResult 单个账户一次快照的结果
+type DailySnapshotResult struct {
+	UserID       string
+	WeightFactor float64
+	ValueFactor  float64
+	Quarter      string
+	PyramidCount int
+}
+
+// RunDailySnapshot 非交互式地记录当前账户当天的 wf/vf，并刷新指定季度的金字塔数据。
+// 金字塔数据使用研究顾问接口返回的用户ID
+func RunDailySnapshot(ctx context.Context, deps Deps, quarter string) (*DailySnapshotResult, error) {
+	logger := programLogger("DailySnapshot")
+
+	factor, err := RecordWeightValueFactor(ctx, deps)
+	if err != nil {
+		return nil, i18n.Errorf("snapshot.factor_failed", err)
+	}
+	logger.Info(i18n.T("snapshot.factor_done"), "user_id", factor.UserID, "weight_factor", factor.WeightFactor, "value_factor", factor.ValueFactor)
+
+	records, err := SnapshotPyramidAlphas(ctx, deps, quarter, factor.UserID)
+	if err != nil {
+		return nil, i18n.Errorf("snapshot.pyramid_failed", err)
+	}
+	logger.Info(i18n.T("snapshot.pyramid_done"), "user_id", factor.UserID, "quarter", quarter, "rows", len(records))
+
+	return &DailySnapshotResult{
+		UserID:       factor.UserID,
+		WeightFactor: factor.WeightFactor,
+		ValueFactor:  factor.ValueFactor,
+		Quarter:      quarter,
+		PyramidCount: len(records),
+	}, nil
+}
+
+// PrintDailySnapshotResult 输出一个账户的快照结果
+func PrintDailySnapshotResult(profile string, result *DailySnapshotResult) {
+	if profile == "" {
+		profile = "-"
+	}
+	fmt.Println(i18n.T("snapshot.summary", profile, result.UserID, result.WeightFactor, result.ValueFactor, result.Quarter, result.PyramidCount))
+}
@@ -0,0 +1,56 @@
+package small_program
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"program-collection/i18n"
+	"program-collection/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// ------------------------------------------------ 数据库驱动 -----------------------------------------------
+
+// SQLite 默认参数：等待写锁 5 秒，使用 WAL 以便读写并发
+const sqlitePragmas = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+// 根据 database.driver 选择 GORM 方言
+func openDialector(data models.Database) (gorm.Dialector, error) {
+	switch data.DriverName() {
+	case models.DriverMySQL:
+		return mysql.Open(data.DSN), nil
+	case models.DriverPostgres:
+		return postgres.Open(data.DSN), nil
+	case models.DriverSQLite:
+		dsn, err := sqliteDSN(data.DSN)
+		if err != nil {
+			return nil, err
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, i18n.Errorf("common.db_unknown_driver", data.Driver)
+	}
+}
+
+// 自动创建数据库文件所在目录，未指定 _pragma 时补上默认参数
+func sqliteDSN(dsn string) (string, error) {
+	file, query, _ := strings.Cut(dsn, "?")
+	file = strings.TrimPrefix(file, "file:")
+	if dir := filepath.Dir(file); file != ":memory:" && dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", i18n.Errorf("common.db_mkdir_failed", dir, err)
+		}
+	}
+	if strings.Contains(query, "_pragma=") {
+		return dsn, nil
+	}
+	if query == "" {
+		return dsn + "?" + sqlitePragmas, nil
+	}
+	return dsn + "&" + sqlitePragmas, nil
+}
@@ -0,0 +1,131 @@
+package small_program
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"program-collection/migrations"
+	"program-collection/models"
+
+	"gorm.io/gorm"
+)
+
+// 在临时目录中打开 SQLite 数据库
+func openTestSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	config := models.Config{Database: models.Database{Driver: models.DriverSQLite, DSN: filepath.Join(t.TempDir(), "data", "wqb.db")}}
+	db, err := ConnectDB(config)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{":memory:", ":memory:?" + sqlitePragmas},
+		{dir + "/a.db", dir + "/a.db?" + sqlitePragmas},
+		{"file:" + dir + "/a.db?cache=shared", "file:" + dir + "/a.db?cache=shared&" + sqlitePragmas},
+		{dir + "/a.db?_pragma=foreign_keys(1)", dir + "/a.db?_pragma=foreign_keys(1)"},
+	}
+	for _, tt := range tests {
+		if got, err := sqliteDSN(tt.dsn); err != nil || got != tt.want {
+			t.Errorf("sqliteDSN(%q) = %q, %v, want %q", tt.dsn, got, err, tt.want)
+		}
+	}
+
+	// 目录无法创建时（父路径是文件）返回错误，而不是留给 sqlite 报一个难懂的错误
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqliteDSN(blocker + "/data/a.db"); err == nil {
+		t.Errorf("sqliteDSN under a file: want error")
+	}
+}
+
+func TestSQLiteMigrationsAndRepos(t *testing.T) {
+	db := openTestSQLite(t)
+	ctx := context.Background()
+
+	all, err := migrations.Load(models.DriverSQLite)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// 1. 执行全部迁移
+	up, err := migrations.Up(db, models.DriverSQLite, 0)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(up) != len(all) {
+		t.Fatalf("Up applied %d migrations, want %d", len(up), len(all))
+	}
+	pending, err := migrations.Pending(db, models.DriverSQLite)
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("Pending = %v, %v; want none", pending, err)
+	}
+
+	// 2. 数据读写
+	repos := NewGormRepos(db)
+	code := "rank(ts_mean(close, 5))"
+	submitted := "2025-09-02 10:00:00"
+	alpha := ActiveAlphaList{ID: "AAA111", Type: "REGULAR", Author: "XX1", Stage: "OS", Status: "ACTIVE", Region: ptr("USA"), RegularCode: &code, DateSubmitted: &submitted}
+	if inserted, err := repos.Alphas.InsertIgnore(ctx, []ActiveAlphaList{alpha, alpha}); err != nil || inserted != 1 {
+		t.Fatalf("InsertIgnore = %d, %v; want 1", inserted, err)
+	}
+	sharpe := 1.5
+	if updated, err := repos.Alphas.Update(ctx, ActiveAlphaList{ID: "AAA111", IsSharpe: &sharpe}); err != nil || !updated {
+		t.Fatalf("Update = %v, %v", updated, err)
+	}
+	got, err := repos.Alphas.Get(ctx, "AAA111")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Type != "REGULAR" || derefOr(got.RegularCode, "") != code || got.IsSharpe == nil || *got.IsSharpe != sharpe {
+		t.Errorf("Get = %+v", got)
+	}
+	if _, err := repos.Alphas.Get(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	list, total, err := repos.Alphas.List(ctx, AlphaFilter{Regions: []string{"USA"}})
+	if err != nil || total != 1 || len(list) != 1 {
+		t.Errorf("List = %d rows, total %d, %v", len(list), total, err)
+	}
+
+	fields := []AlphaField{{AlphaID: "AAA111", Field: "close", OperatorPath: "rank/ts_mean", Source: "regular"}}
+	if err := repos.AlphaFields.Replace(ctx, []string{"AAA111"}, fields); err != nil {
+		t.Fatalf("AlphaFields.Replace: %v", err)
+	}
+	rows, err := repos.AlphaFields.ByFields(ctx, []string{"close", "volume"})
+	if err != nil || len(rows) != 1 || rows[0].OperatorPath != "rank/ts_mean" {
+		t.Errorf("ByFields = %+v, %v", rows, err)
+	}
+
+	// 3. 全部回滚后表不再存在
+	down, err := migrations.Down(db, models.DriverSQLite, len(all))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(down) != len(all) {
+		t.Fatalf("Down rolled back %d migrations, want %d", len(down), len(all))
+	}
+	for _, table := range []string{"active_alpha_list", "alpha_fields", "operators"} {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("table %s still exists after Down", table)
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,222 @@
+package small_program
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 表达式格式化 -----------------------------------------------
+
+// FormatResult 一段表达式的格式化结果，Name 为 文件:行号、alpha ID 或 <stdin>
+type FormatResult struct {
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	Formatted string `json:"formatted,omitempty"`
+	Err       error  `json:"-"`
+}
+
+// Changed 格式化后与原文不同（忽略结尾的换行）
+func (r FormatResult) Changed() bool {
+	return r.Err == nil && r.Formatted != strings.TrimRight(r.Source, "\r\n")
+}
+
+// FormatSource 格式化单个表达式
+func FormatSource(name, src string) FormatResult {
+	formatted, err := fastexpr.Format(src)
+	return FormatResult{Name: name, Source: src, Formatted: formatted, Err: err}
+}
+
+// FormatExpressions 格式化一个文件中的多个表达式，分隔规则见 SplitExpressions
+func FormatExpressions(name, text string) []FormatResult {
+	var results []FormatResult
+	for _, expr := range SplitExpressions(text) {
+		results = append(results, FormatSource(fmt.Sprintf("%s:%d", name, expr.Line), expr.Text))
+	}
+	return results
+}
+
+// SourceExpression 文件中的一个表达式，Line 为开始的行号
+type SourceExpression struct {
+	Line int
+	Text string
+}
+
+// SplitExpressions 把文件内容分成多个表达式，lint、fmt、dupes、similar 的 --file 和 fields --batch 共用：
+// 表达式在使它完整的行尾结束，即语法完整、最后一条语句不是赋值，且下一行不以二元运算符、) 等接续；
+// 因此每行一个表达式和以空行分隔的多行表达式都可以，表达式内部也可以有空行。
+// 无法解析的部分在出错的行尾结束，不影响之后的表达式；文件末尾只有注释时并入最后一个表达式
+func SplitExpressions(text string) []SourceExpression {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var exprs []SourceExpression
+	var current []string
+	start := 0
+	for i, line := range lines {
+		if len(current) == 0 {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			start = i + 1
+		}
+		current = append(current, line)
+		if expressionEnds(strings.Join(current, "\n"), lines[i+1:]) {
+			exprs = append(exprs, SourceExpression{Line: start, Text: strings.TrimRight(strings.Join(current, "\n"), " \t\n")})
+			current = nil
+		}
+	}
+
+	if len(current) > 0 {
+		rest := strings.TrimRight(strings.Join(current, "\n"), " \t\n")
+		if program, err := fastexpr.Parse(rest); err == nil && len(program.Stmts) == 0 && len(exprs) > 0 {
+			last := &exprs[len(exprs)-1]
+			last.Text += strings.Repeat("\n", start-last.Line-strings.Count(last.Text, "\n")) + rest
+		} else {
+			exprs = append(exprs, SourceExpression{Line: start, Text: rest})
+		}
+	}
+	return exprs
+}
+
+// 表达式是否在 src 的末尾结束，rest 为之后的各行
+func expressionEnds(src string, rest []string) bool {
+	program, err := fastexpr.Parse(src)
+	if err != nil {
+		// 错误在末尾（括号未闭合、以运算符结尾）或注释 /* 未结束时继续读下一行，其他错误到此结束
+		var syntaxErr *fastexpr.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			return true
+		}
+		return syntaxErr.Pos.Offset < len(src) && !strings.HasPrefix(src[syntaxErr.Pos.Offset:], "/*")
+	}
+	if len(program.Stmts) == 0 {
+		return false
+	}
+	if _, ok := program.Stmts[len(program.Stmts)-1].(*fastexpr.Assign); ok {
+		return false
+	}
+	return !continuesExpression(rest)
+}
+
+// 之后第一个非空行是否以不能开始表达式的词法单元开头，如 * 2、) 或 ? a : b
+func continuesExpression(rest []string) bool {
+	for _, line := range rest {
+		tokens, _ := fastexpr.Tokenize(line)
+		tokens = slices.DeleteFunc(tokens, func(tok fastexpr.Token) bool { return tok.Kind == fastexpr.COMMENT })
+		if len(tokens) == 0 {
+			return false
+		}
+		// 空行和只有注释的行跳过
+		switch kind := tokens[0].Kind; kind {
+		case fastexpr.EOF:
+			continue
+		case fastexpr.ADD, fastexpr.SUB, fastexpr.NOT:
+			return false
+		case fastexpr.RPAREN, fastexpr.COMMA, fastexpr.SEMICOLON, fastexpr.ASSIGN, fastexpr.QUESTION, fastexpr.COLON:
+			return true
+		default:
+			return fastexpr.Precedence(kind) > 0
+		}
+	}
+	return false
+}
+
+// JoinExpressions 把格式化后的表达式写回文件内容，表达式之间空一行，有解析失败的表达式时保留原文；
+// 结果重新分隔后与原来的表达式不一一对应时返回错误，避免写回后改变表达式的边界
+func JoinExpressions(results []FormatResult) (string, error) {
+	blocks := make([]string, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			blocks[i] = strings.TrimRight(result.Source, "\r\n")
+		} else {
+			blocks[i] = result.Formatted
+		}
+	}
+	text := strings.Join(blocks, "\n\n") + "\n"
+
+	exprs := SplitExpressions(text)
+	if len(exprs) != len(blocks) {
+		return "", i18n.Errorf("fmt.boundary_changed", len(blocks), len(exprs))
+	}
+	for i, expr := range exprs {
+		if expr.Text != strings.TrimRight(blocks[i], " \t\n") {
+			return "", i18n.Errorf("fmt.boundary_changed", len(blocks), len(exprs))
+		}
+	}
+	return text, nil
+}
+
+// FormatStoredAlphas 格式化 active_alpha_list 中保存的表达式，SUPER alpha 的 combo 和 selection 分别处理；
+// 只读取，不修改数据库
+func FormatStoredAlphas(ctx context.Context, repo AlphaRepo, filter AlphaFilter) ([]FormatResult, error) {
+	alphas, _, err := repo.List(ctx, filter)
+	if err != nil {
+		return nil, i18n.Errorf("fmt.load_alphas_failed", err)
+	}
+
+	var results []FormatResult
+	for _, alpha := range alphas {
+		for _, code := range []struct {
+			suffix string
+			value  *string
+		}{
+			{"", alpha.RegularCode},
+			{"/combo", alpha.ComboCode},
+			{"/selection", alpha.SelectionCode},
+		} {
+			if code.value == nil || strings.TrimSpace(*code.value) == "" {
+				continue
+			}
+			results = append(results, FormatSource(alpha.ID+code.suffix, *code.value))
+		}
+	}
+	return results, nil
+}
+
+// PrintFormatCheck 检查模式：输出解析失败和需要格式化的表达式名称，全部已格式化时返回 true
+func PrintFormatCheck(results []FormatResult) bool {
+	unformatted, failed := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Printf("%s: %v\n", result.Name, result.Err)
+		case result.Changed():
+			unformatted++
+			fmt.Println(result.Name)
+		}
+	}
+
+	if unformatted == 0 && failed == 0 {
+		fmt.Println(i18n.T("fmt.check_ok", len(results)))
+		return true
+	}
+	fmt.Println(i18n.T("fmt.check_summary", unformatted, failed, len(results)))
+	return false
+}
+
+// PrintFormatted 输出格式化结果，withNames 为 true 时每个表达式前加一行 // 名称 注释；
+// 有解析失败的表达式时返回 false
+func PrintFormatted(results []FormatResult, withNames bool) bool {
+	ok := true
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		if result.Err != nil {
+			ok = false
+			fmt.Fprintln(os.Stderr, i18n.T("fmt.parse_failed", result.Name, result.Err))
+			continue
+		}
+		if withNames {
+			fmt.Println("// " + result.Name)
+		}
+		fmt.Println(result.Formatted)
+	}
+	return ok
+}
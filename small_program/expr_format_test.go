@@ -0,0 +1,98 @@
+package small_program
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitExpressions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []SourceExpression
+	}{
+		{
+			name: "one per line",
+			text: "rank(close)\nts_mean(open, 5)\n",
+			want: []SourceExpression{{1, "rank(close)"}, {2, "ts_mean(open, 5)"}},
+		},
+		{
+			name: "blank lines",
+			text: "\nrank(close)\n\n\nts_mean(open, 5)",
+			want: []SourceExpression{{2, "rank(close)"}, {5, "ts_mean(open, 5)"}},
+		},
+		{
+			name: "statements across blank lines",
+			text: "a = ts_mean(close, 5);\n\nb = a - open;\n\nrank(b);\n\nrank(volume)",
+			want: []SourceExpression{{1, "a = ts_mean(close, 5);\n\nb = a - open;\n\nrank(b);"}, {7, "rank(volume)"}},
+		},
+		{
+			name: "unclosed call",
+			text: "ts_mean(\n    close,\n\n    5\n)\nrank(close)",
+			want: []SourceExpression{{1, "ts_mean(\n    close,\n\n    5\n)"}, {6, "rank(close)"}},
+		},
+		{
+			name: "continuation line",
+			text: "rank(close)\n    * 2\n? a\n: b\nrank(open)",
+			want: []SourceExpression{{1, "rank(close)\n    * 2\n? a\n: b"}, {5, "rank(open)"}},
+		},
+		{
+			name: "unary starts a new expression",
+			text: "rank(close)\n-returns",
+			want: []SourceExpression{{1, "rank(close)"}, {2, "-returns"}},
+		},
+		{
+			name: "comments",
+			text: "# 动量\n/* 多行\n注释 */\nrank(close) // c\n\n// 结尾",
+			want: []SourceExpression{{1, "# 动量\n/* 多行\n注释 */\nrank(close) // c\n\n// 结尾"}},
+		},
+		{
+			name: "syntax error ends at its line",
+			text: "rank(close))\n1Y5Nj28K\nhttps://platform.worldquantbrain.com/alpha/ab12XYz\nrank(open)",
+			want: []SourceExpression{
+				{1, "rank(close))"},
+				{2, "1Y5Nj28K"},
+				{3, "https://platform.worldquantbrain.com/alpha/ab12XYz"},
+				{4, "rank(open)"},
+			},
+		},
+		{
+			name: "crlf",
+			text: "rank(close)\r\nrank(open)\r\n",
+			want: []SourceExpression{{1, "rank(close)"}, {2, "rank(open)"}},
+		},
+		{
+			name: "empty",
+			text: "\n\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		if got := SplitExpressions(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: SplitExpressions(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+// fmt --file -w：写回的内容重新格式化后不变，且表达式的边界不变
+func TestFormatExpressionsRoundTrip(t *testing.T) {
+	text := "a=ts_mean(close,5);\n\nrank(a);\n\nrank( open )\n\nrank(close\n"
+	results := FormatExpressions("f", text)
+	if len(results) != 3 || results[2].Err == nil {
+		t.Fatalf("FormatExpressions = %+v, want 3 results, the last one failed", results)
+	}
+
+	joined, err := JoinExpressions(results)
+	if err != nil {
+		t.Fatalf("JoinExpressions: %v", err)
+	}
+	want := "a = ts_mean(close, 5);\nrank(a);\n\nrank(open)\n\nrank(close\n"
+	if joined != want {
+		t.Errorf("JoinExpressions =\n%s\nwant\n%s", joined, want)
+	}
+
+	again, err := JoinExpressions(FormatExpressions("f", joined))
+	if err != nil || again != joined {
+		t.Errorf("JoinExpressions not stable: %v\n%s", err, again)
+	}
+}
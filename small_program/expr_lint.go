@@ -0,0 +1,60 @@
+package small_program
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 表达式静态检查 -----------------------------------------------
+
+// OperatorCatalog 用 operators 表中的记录创建操作符目录，同名操作符以最新写入的为准
+func OperatorCatalog(ctx context.Context, repo OperatorRepo) (*fastexpr.Catalog, error) {
+	records, err := repo.List(ctx)
+	if err != nil {
+		return nil, i18n.Errorf("lint.load_operators_failed", err)
+	}
+	if len(records) == 0 {
+		return nil, i18n.Errorf("lint.no_operators")
+	}
+
+	operators := make([]fastexpr.Operator, len(records))
+	for i, record := range records {
+		operators[i] = toCatalogOperator(record)
+	}
+	return fastexpr.NewCatalog(operators), nil
+}
+
+// Scope 在表中按 JSON 数组保存
+func toCatalogOperator(record Operators) fastexpr.Operator {
+	var scope []string
+	_ = json.Unmarshal([]byte(record.Scope), &scope)
+	return fastexpr.Operator{
+		Name:       record.Name,
+		Definition: record.Definition,
+		Scope:      scope,
+		Level:      record.Level,
+	}
+}
+
+// PrintLintFindings 按 行:列 输出检查结果，没有问题时输出操作符数量
+func PrintLintFindings(findings []fastexpr.Finding, catalog *fastexpr.Catalog) {
+	if len(findings) == 0 {
+		fmt.Println(i18n.T("lint.ok", catalog.Len()))
+		return
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, finding := range findings {
+		fmt.Println(finding)
+		if finding.Severity == fastexpr.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+	fmt.Println(i18n.T("lint.summary", errorCount, warningCount))
+}
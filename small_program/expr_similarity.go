@@ -0,0 +1,152 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 表达式结构相似度 -----------------------------------------------
+// 比较新表达式与已保存 alpha 的语法树结构（见 fastexpr.Shape），在模拟之前粗略估计自相关：
+// 只共用 close 这类字段的 alpha 得分很低，换了参数或变量名、交换了参数顺序的 alpha 得分接近 1
+
+// SimilarityIndex 已保存 alpha 各段代码的结构特征，创建一次后可以比较多个表达式
+type SimilarityIndex struct {
+	entries    []similarityEntry
+	Unparsable []string // 无法解析的代码，alpha ID 或 ID/combo、ID/selection
+}
+
+type similarityEntry struct {
+	alpha  ActiveAlphaList
+	source string
+	shape  *fastexpr.Shape
+}
+
+// NewSimilarityIndex 读取 filter 范围内的 alpha，解析 regular、combo 和 selection 代码
+func NewSimilarityIndex(ctx context.Context, repo AlphaRepo, filter AlphaFilter) (*SimilarityIndex, error) {
+	alphas, _, err := repo.List(ctx, filter)
+	if err != nil {
+		return nil, i18n.Errorf("similar.load_alphas_failed", err)
+	}
+
+	index := &SimilarityIndex{}
+	for _, alpha := range alphas {
+		for _, code := range storedAlphaCodes(alpha) {
+			shape, err := fastexpr.NewShape(code.code)
+			if err != nil {
+				name := alpha.ID
+				if code.source != "regular" {
+					name += "/" + code.source
+				}
+				index.Unparsable = append(index.Unparsable, name)
+				continue
+			}
+			index.entries = append(index.entries, similarityEntry{alpha: alpha, source: code.source, shape: shape})
+		}
+	}
+
+	programLogger("Similarity").Info(i18n.T("similar.index_built"), "alphas", len(alphas), "codes", len(index.entries), "unparsable", len(index.Unparsable))
+	return index, nil
+}
+
+// SimilarAlpha 与表达式结构相似的已保存 alpha
+type SimilarAlpha struct {
+	AlphaID       string   `json:"alpha_id"`
+	Type          string   `json:"type"`
+	Settings      string   `json:"settings"` // 地区/股票池/延迟/中性化方式
+	DateSubmitted string   `json:"date_submitted,omitempty"`
+	Source        string   `json:"source"` // 最相似的代码：regular、combo 或 selection
+	Score         float64  `json:"score"`  // 0~1
+	Shared        []string `json:"shared"` // 共有的子表达式，数字写作 #
+}
+
+// Search 返回与 code 最相似的 topK 个 alpha（topK 为 0 时不限），得分低于 minScore 或为 0 的不返回；
+// 同一 alpha 的多段代码取得分最高的一段
+func (idx *SimilarityIndex) Search(code string, topK int, minScore float64) ([]SimilarAlpha, error) {
+	shape, err := fastexpr.NewShape(code)
+	if err != nil {
+		return nil, err
+	}
+
+	best := map[string]*SimilarAlpha{}
+	for _, entry := range idx.entries {
+		score := fastexpr.Similarity(shape, entry.shape)
+		if score == 0 || score < minScore {
+			continue
+		}
+		if existing, ok := best[entry.alpha.ID]; ok && existing.Score >= score {
+			continue
+		}
+		best[entry.alpha.ID] = &SimilarAlpha{
+			AlphaID:       entry.alpha.ID,
+			Type:          entry.alpha.Type,
+			Settings:      settingsKey(entry.alpha),
+			DateSubmitted: derefOr(entry.alpha.DateSubmitted, ""),
+			Source:        entry.source,
+			Score:         score,
+			Shared:        fastexpr.SharedSubexpressions(shape, entry.shape),
+		}
+	}
+
+	result := make([]SimilarAlpha, 0, len(best))
+	for _, similar := range best {
+		result = append(result, *similar)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].AlphaID < result[j].AlphaID
+	})
+	if topK > 0 && len(result) > topK {
+		result = result[:topK]
+	}
+	return result, nil
+}
+
+// SimilarityResult 一个候选表达式的相似度查找结果
+type SimilarityResult struct {
+	Name    string         `json:"name"`
+	Err     error          `json:"-"`
+	Similar []SimilarAlpha `json:"similar"`
+}
+
+// SearchCandidates 依次查找每个候选表达式的相似 alpha
+func (idx *SimilarityIndex) SearchCandidates(candidates []DuplicateCandidate, topK int, minScore float64) []SimilarityResult {
+	results := make([]SimilarityResult, len(candidates))
+	for i, candidate := range candidates {
+		results[i].Name = candidate.Name
+		results[i].Similar, results[i].Err = idx.Search(candidate.Code, topK, minScore)
+	}
+	return results
+}
+
+// PrintSimilarityResults 输出相似度查找结果，有表达式解析失败时返回 false
+func PrintSimilarityResults(results []SimilarityResult) bool {
+	ok := true
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		switch {
+		case result.Err != nil:
+			ok = false
+			fmt.Println(i18n.T("similar.parse_failed", result.Name, result.Err))
+		case len(result.Similar) == 0:
+			fmt.Println(i18n.T("similar.none", result.Name))
+		default:
+			fmt.Println(i18n.T("similar.header", result.Name, len(result.Similar)))
+			for _, similar := range result.Similar {
+				fmt.Printf("   %.3f  %-10s  %-8s  %-10s  %-9s  %s\n", similar.Score, similar.AlphaID, similar.Type, dateOnly(similar.DateSubmitted), similar.Source, similar.Settings)
+				for _, shared := range similar.Shared {
+					fmt.Printf("          = %s\n", strings.ReplaceAll(shared, ",", ", "))
+				}
+			}
+		}
+	}
+	return ok
+}
@@ -2,13 +2,19 @@ package small_program
 
 import (
 	"bufio"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"program-collection/credentials"
+	"program-collection/fastexpr"
+	"program-collection/i18n"
 	"program-collection/models"
 
 	"github.com/samber/lo"
@@ -22,470 +28,217 @@ type ParamRule struct {
 	IgnoreNamed    bool  // 是否忽略命名参数
 }
 
-// 移除注释但保留代码
-func removeComments(input string) string {
-	var result strings.Builder
-	inMultiLineComment := false
-	inSingleLineComment := false
+// 不算作字段的常量
+var exprConstants = map[string]bool{"true": true, "false": true, "nan": true, "inf": true}
 
-	for i := 0; i < len(input); i++ {
-		char := input[i]
-
-		if inMultiLineComment {
-			if char == '*' && i+1 < len(input) && input[i+1] == '/' {
-				inMultiLineComment = false
-				i++ // 跳过 '/'
-			}
-			continue
-		}
-
-		if inSingleLineComment {
-			if char == '\n' {
-				inSingleLineComment = false
-				result.WriteByte(char)
-			}
-			continue
-		}
-
-		// 检查是否开始多行注释
-		if char == '/' && i+1 < len(input) && input[i+1] == '*' {
-			inMultiLineComment = true
-			i++ // 跳过 '*'
-			continue
-		}
-
-		// 检查是否开始单行注释
-		if char == '/' && i+1 < len(input) && input[i+1] == '/' {
-			inSingleLineComment = true
-			i++ // 跳过第二个 '/'
-			continue
-		}
-
-		result.WriteByte(char)
-	}
-
-	// 清理结果
-	cleaned := result.String()
-	cleaned = strings.ReplaceAll(cleaned, "\r", " ")
-	cleaned = regexp.MustCompile(`\s+`).ReplaceAllString(cleaned, " ")
-	return strings.TrimSpace(cleaned)
+// 手动添加的特殊操作符规则：这些操作符的参数个数可变，definition 中解析不出字段位置
+var specialFieldRules = map[string]ParamRule{
+	"greater_equal": {FieldPositions: []int{0, 1}, IgnoreNamed: true},
+	"multiply":      {FieldPositions: []int{0, 1, 2, 3, 4, 5, 6}, IgnoreNamed: true},
+	"max":           {FieldPositions: []int{0, 1, 2, 3, 4, 5, 6}, IgnoreNamed: true},
+	"min":           {FieldPositions: []int{0, 1, 2, 3, 4, 5, 6}, IgnoreNamed: true},
 }
 
-func extractFields(input string, allOperators []string, functionRules map[string]ParamRule) []string {
-	// 首先移除所有注释
-	cleanedInput := removeComments(input)
-
-	// 提取所有定义的变量
-	definedVars := extractDefinedVariables(cleanedInput, allOperators)
-
-	// 移除赋值语句的左边部分并获取处理后的表达式
-	processedInput, rightSides := removeAssignmentLeft(cleanedInput)
+// FieldExtractor 按操作符列表和参数规则从表达式中提取数据字段，创建一次后可重复使用
+type FieldExtractor struct {
+	operators map[string]bool
+	rules     map[string]ParamRule
+}
 
-	uniqueFields := make(map[string]bool)
+// NewFieldExtractor 由操作符列表生成参数规则，并合并特殊操作符的规则
+func NewFieldExtractor(allOperators []models.Operator) *FieldExtractor {
+	specialOperatorNames := lo.Keys(specialFieldRules)
 
-	// 递归提取所有字段
-	fields := extractFieldsRecursive(processedInput, allOperators, functionRules)
-	for _, field := range fields {
-		// 过滤掉函数名和定义的变量
-		if !isFunctionName(field, allOperators) && !definedVars[field] {
-			uniqueFields[field] = true
-		}
-	}
+	// 生成函数规则（自动跳过特殊操作符）
+	functionRules := GenerateFunctionRules(allOperators, specialOperatorNames)
 
-	// 另外从等号右边部分提取字段（确保不会漏掉）
-	for _, expr := range rightSides {
-		exprFields := extractFieldsRecursive(expr, allOperators, functionRules)
-		for _, field := range exprFields {
-			if !isFunctionName(field, allOperators) && !definedVars[field] {
-				uniqueFields[field] = true
-			}
-		}
+	// 将特殊操作符规则合并到functionRules中
+	for name, rule := range specialFieldRules {
+		functionRules[name] = rule
 	}
 
-	// 转换为切片
-	result := make([]string, 0, len(uniqueFields))
-	for field := range uniqueFields {
-		result = append(result, field)
+	return &FieldExtractor{
+		operators: lo.SliceToMap(allOperators, func(op models.Operator) (string, bool) { return op.Name, true }),
+		rules:     functionRules,
 	}
-
-	return result
 }
 
-// 提取所有定义的变量（等号左边的标识符）
-func extractDefinedVariables(input string, allOperators []string) map[string]bool {
-	definedVars := make(map[string]bool)
-
-	// 按分号分割多个表达式
-	expressions := strings.Split(input, ";")
-
-	for _, expr := range expressions {
-		expr = strings.TrimSpace(expr)
-		if expr == "" {
-			continue
-		}
-
-		// 查找第一个不在括号内的等号
-		parenDepth := 0
-		for i, char := range expr {
-			switch char {
-			case '(':
-				parenDepth++
-			case ')':
-				parenDepth--
-			case '=':
-				if parenDepth == 0 {
-					// 提取等号左边的变量
-					leftSide := strings.TrimSpace(expr[:i])
-					if leftSide != "" {
-						// 左边可能是一个变量或多个变量，这里简单处理为单个变量
-						// 使用正则提取有效的变量名
-						varPattern := `[a-zA-Z_][a-zA-Z0-9_]*`
-						varRe := regexp.MustCompile(varPattern)
-						matches := varRe.FindAllString(leftSide, -1)
-
-						for _, match := range matches {
-							if !isFunctionName(match, allOperators) {
-								definedVars[match] = true
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	return definedVars
+// FieldUse 字段的一次使用，Path 为从外到内包含该字段的操作符，以 / 连接，如 rank/ts_delta；
+// 字段不在任何操作符内或表达式无法解析时为空
+type FieldUse struct {
+	Field string `json:"field"`
+	Path  string `json:"path"`
 }
 
-// 移除赋值语句的左边部分，返回处理后的表达式和等号右边的部分
-func removeAssignmentLeft(input string) (string, []string) {
-	// 按分号分割多个表达式
-	expressions := strings.Split(input, ";")
-	var result []string
-	var rightSides []string
-
-	for _, expr := range expressions {
-		expr = strings.TrimSpace(expr)
-		if expr == "" {
-			continue
-		}
-
-		// 查找第一个不在括号内的等号
-		parenDepth := 0
-		foundEqual := false
-		for i, char := range expr {
-			switch char {
-			case '(':
-				parenDepth++
-			case ')':
-				parenDepth--
-			case '=':
-				if parenDepth == 0 {
-					// 返回等号右边的部分
-					rightSide := strings.TrimSpace(expr[i+1:])
-					if rightSide != "" {
-						result = append(result, rightSide)
-						rightSides = append(rightSides, rightSide)
-					}
-					foundEqual = true
-				}
-			}
-			if foundEqual {
-				break
-			}
-		}
-
-		// 如果没有等号，保留整个表达式
-		if !foundEqual {
-			result = append(result, expr)
-			rightSides = append(rightSides, expr)
-		}
-	}
-
-	return strings.Join(result, "; "), rightSides
+// Fields 解析表达式并遍历语法树提取数据字段，按首次出现的顺序返回
+func (e *FieldExtractor) Fields(input string) []string {
+	return e.walk(input).fields
 }
 
-// 递归提取字段（带深度限制）
-func extractFieldsRecursive(expr string, allOperators []string, functionRules map[string]ParamRule) []string {
-	return extractFieldsRecursiveWithDepth(expr, allOperators, functionRules, 0)
+// Uses 返回字段及其所在的操作符路径，同一字段在不同路径下各返回一次
+func (e *FieldExtractor) Uses(input string) []FieldUse {
+	return e.walk(input).uses
 }
 
-func extractFieldsRecursiveWithDepth(expr string, allOperators []string, functionRules map[string]ParamRule, depth int) []string {
-	// 添加递归深度限制
-	if depth > 50 {
-		// fmt.Printf("警告: 递归深度超过限制，表达式: %s\n", expr)
-		return extractSimpleFields(expr, allOperators)
+// walk 遍历语法树提取数据字段：
+// 跳过操作符名、命名参数、局部变量和常量；有规则的操作符只看规则中的参数位置，
+// 没有规则的操作符只看第一个参数。表达式无法解析时退化为按词法单元提取
+func (e *FieldExtractor) walk(input string) *fieldWalker {
+	w := &fieldWalker{
+		operators: e.operators,
+		rules:     e.rules,
+		locals:    map[string]bool{},
+		seen:      map[string]bool{},
+		seenUses:  map[FieldUse]bool{},
 	}
 
-	expr = strings.TrimSpace(expr)
-	if expr == "" {
-		return nil
+	program, err := fastexpr.Parse(input)
+	if err != nil {
+		w.fromTokens(input)
+		return w
 	}
 
-	var fields []string
-
-	// 先检查是否包含比较或逻辑运算符
-	if hasComparisonOrLogicalOps(expr) {
-		// 分割表达式并递归处理每个部分
-		parts := splitExpression(expr)
-		for _, part := range parts {
-			partFields := extractFieldsRecursiveWithDepth(part, allOperators, functionRules, depth+1)
-			fields = append(fields, partFields...)
+	// 先收集全部局部变量，赋值前引用的同名标识符同样不算字段
+	for _, stmt := range program.Stmts {
+		if assign, ok := stmt.(*fastexpr.Assign); ok {
+			w.locals[assign.Name.Name] = true
 		}
-		return fields
 	}
-
-	// 修复：使用更强大的函数调用匹配，处理嵌套函数
-	funcPattern := `([a-zA-Z_][a-zA-Z0-9_]*)\((.*)\)`
-	funcRe := regexp.MustCompile(funcPattern)
-
-	// 尝试匹配最外层的函数调用
-	remainingExpr := expr
-	for {
-		funcMatch := funcRe.FindStringSubmatch(remainingExpr)
-		if funcMatch == nil {
-			break
-		}
-
-		funcName := funcMatch[1]
-		paramsStr := funcMatch[2]
-
-		// 检查参数是否平衡（括号匹配）
-		if !isBalancedParentheses(paramsStr) {
-			// 如果括号不平衡，跳过这个函数匹配
-			break
-		}
-
-		if rule, exists := functionRules[funcName]; exists {
-			// 根据规则处理参数
-			paramFields := processParametersByRule(paramsStr, rule, allOperators, functionRules)
-			fields = append(fields, paramFields...)
-		} else if isFunctionName(funcName, allOperators) {
-			// 默认规则：递归处理第一个参数
-			params := parseParameters(paramsStr)
-			if len(params) > 0 {
-				firstParamFields := extractFieldsRecursiveWithDepth(params[0], allOperators, functionRules, depth+1)
-				fields = append(fields, firstParamFields...)
-			}
+	for _, stmt := range program.Stmts {
+		switch n := stmt.(type) {
+		case *fastexpr.Assign:
+			w.expr(n.Value)
+		case fastexpr.Expr:
+			w.expr(n)
 		}
-
-		// 移除已处理的函数调用，继续处理剩余部分
-		remainingExpr = strings.Replace(remainingExpr, funcMatch[0], "", 1)
-	}
-
-	// 如果没有函数调用，提取简单字段和表达式中的字段
-	if len(fields) == 0 {
-		fields = extractSimpleFields(expr, allOperators)
 	}
+	return w
+}
 
-	return fields
+// 遍历语法树时的状态，fields 和 uses 按首次出现的顺序保存，path 为当前所在的操作符
+type fieldWalker struct {
+	operators map[string]bool
+	rules     map[string]ParamRule
+	locals    map[string]bool
+	seen      map[string]bool
+	seenUses  map[FieldUse]bool
+	path      []string
+	fields    []string
+	uses      []FieldUse
 }
 
-// 检查括号是否平衡
-func isBalancedParentheses(s string) bool {
-	count := 0
-	for _, char := range s {
-		switch char {
-		case '(':
-			count++
-		case ')':
-			count--
-			if count < 0 {
-				return false
-			}
-		}
+func (w *fieldWalker) add(name string) {
+	if w.operators[name] || w.locals[name] || exprConstants[strings.ToLower(name)] {
+		return
+	}
+	if !w.seen[name] {
+		w.seen[name] = true
+		w.fields = append(w.fields, name)
+	}
+	use := FieldUse{Field: name, Path: strings.Join(w.path, "/")}
+	if !w.seenUses[use] {
+		w.seenUses[use] = true
+		w.uses = append(w.uses, use)
 	}
-	return count == 0
 }
 
-// 检查是否包含比较或逻辑运算符
-func hasComparisonOrLogicalOps(expr string) bool {
-	operators := []string{">", "<", ">=", "<=", "==", "!=", "&&", "||"}
-	for _, op := range operators {
-		if strings.Contains(expr, op) {
-			return true
-		}
-	}
-	return false
+// 在操作符 name 内遍历 fn 中的参数
+func (w *fieldWalker) within(name string, fn func()) {
+	w.path = append(w.path, name)
+	fn()
+	w.path = w.path[:len(w.path)-1]
 }
 
-// 分割表达式（处理比较和逻辑运算符）
-func splitExpression(expr string) []string {
-	var parts []string
-	var current strings.Builder
-	parenDepth := 0
-
-	for i := 0; i < len(expr); i++ {
-		char := expr[i]
-
-		switch char {
-		case '(':
-			parenDepth++
-			current.WriteByte(char)
-		case ')':
-			parenDepth--
-			current.WriteByte(char)
-		case '>', '<', '!', '&', '|':
-			if parenDepth == 0 {
-				// 检查是否是复合运算符
-				if i+1 < len(expr) {
-					nextChar := expr[i+1]
-					compoundOps := []string{">=", "<=", "==", "!=", "&&", "||"}
-					compound := string(char) + string(nextChar)
-
-					// 检查是否是逻辑运算符 and, or 的一部分
-					if i+2 < len(expr) {
-						thirdChar := expr[i+2]
-						if (char == 'a' && nextChar == 'n' && thirdChar == 'd') ||
-							(char == 'o' && nextChar == 'r') {
-							current.WriteByte(char)
-							continue
-						}
-					}
-
-					foundCompound := false
-					for _, op := range compoundOps {
-						if op == compound {
-							if current.Len() > 0 {
-								parts = append(parts, strings.TrimSpace(current.String()))
-								current.Reset()
-							}
-							i++ // 跳过下一个字符
-							foundCompound = true
-							break
-						}
-					}
-					if foundCompound {
-						continue
-					}
-				}
-
-				// 单个运算符
-				if current.Len() > 0 {
-					parts = append(parts, strings.TrimSpace(current.String()))
-					current.Reset()
-				}
-			} else {
-				current.WriteByte(char)
-			}
+func (w *fieldWalker) expr(e fastexpr.Expr) {
+	switch n := e.(type) {
+	case *fastexpr.Ident:
+		w.add(n.Name)
+	case *fastexpr.Call:
+		w.within(n.Fun.Name, func() { w.call(n) })
+	case *fastexpr.NamedArg:
+		w.expr(n.Value)
+	case *fastexpr.UnaryOp:
+		switch n.Op {
+		case fastexpr.SUB:
+			w.within("reverse", func() { w.expr(n.X) })
+		case fastexpr.NOT:
+			w.within("not", func() { w.expr(n.X) })
 		default:
-			current.WriteByte(char)
-		}
-	}
-
-	// 添加最后一部分
-	if current.Len() > 0 {
-		parts = append(parts, strings.TrimSpace(current.String()))
+			w.expr(n.X)
+		}
+	case *fastexpr.BinaryOp:
+		w.within(fastexpr.BinaryOperatorName(n.Op), func() {
+			w.expr(n.X)
+			w.expr(n.Y)
+		})
+	case *fastexpr.Ternary:
+		w.within("if_else", func() {
+			w.expr(n.Cond)
+			w.expr(n.Then)
+			w.expr(n.Else)
+		})
 	}
-
-	return parts
 }
 
-// 根据规则处理参数
-func processParametersByRule(paramsStr string, rule ParamRule, allOperators []string, functionRules map[string]ParamRule) []string {
-	params := parseParameters(paramsStr)
-	var allFields []string
+func (w *fieldWalker) call(call *fastexpr.Call) {
+	rule, hasRule := w.rules[call.Fun.Name]
 
-	for _, pos := range rule.FieldPositions {
-		if pos < len(params) {
-			param := strings.TrimSpace(params[pos])
-			// 只有 IgnoreNamed 为 true 时才跳过命名参数
-			if rule.IgnoreNamed && strings.Contains(param, "=") {
+	switch {
+	// 1. 有规则：只看规则中的位置（位置按全部参数计）
+	case hasRule && len(rule.FieldPositions) > 0:
+		for _, pos := range rule.FieldPositions {
+			if pos >= len(call.Args) {
+				continue
+			}
+			if _, named := call.Args[pos].(*fastexpr.NamedArg); named && rule.IgnoreNamed {
 				continue
 			}
-			// 递归处理参数（可能包含嵌套函数或简单字段）
-			paramFields := extractFieldsRecursive(param, allOperators, functionRules)
-			allFields = append(allFields, paramFields...)
+			w.expr(call.Args[pos])
 		}
-	}
-
-	return allFields
-}
-
-// 提取简单表达式中的字段（过滤掉命名参数）
-func extractSimpleFields(expr string, allOperators []string) []string {
-	var fields []string
 
-	// 使用正则匹配所有标识符
-	fieldPattern := `[a-zA-Z_][a-zA-Z0-9_]*`
-	fieldRe := regexp.MustCompile(fieldPattern)
-	matches := fieldRe.FindAllString(expr, -1)
+	// 2. 已知操作符但没有规则：只看第一个参数
+	case !hasRule && w.operators[call.Fun.Name]:
+		if len(call.Args) > 0 {
+			w.expr(call.Args[0])
+		}
 
-	for _, match := range matches {
-		// 过滤掉函数名和命名参数
-		if !isFunctionName(match, allOperators) && !isNamedParameter(expr, match) {
-			fields = append(fields, match)
+	// 3. 规则中没有位置或未知函数：看全部位置参数
+	default:
+		for _, arg := range call.Args {
+			if _, named := arg.(*fastexpr.NamedArg); named {
+				continue
+			}
+			w.expr(arg)
 		}
 	}
-
-	return fields
 }
 
-// 检查是否是命名参数（如 std=4.0 中的 std）
-func isNamedParameter(expr, identifier string) bool {
-	// 查找标识符后面是否有等号
-	pattern := regexp.QuoteMeta(identifier) + `\s*=`
-	re := regexp.MustCompile(pattern)
-	return re.MatchString(expr)
-}
+// 无法解析时，取不是调用名、命名参数名、局部变量的标识符
+func (w *fieldWalker) fromTokens(input string) {
+	tokens, _ := fastexpr.Tokenize(input)
 
-// 解析参数字符串，处理嵌套函数
-func parseParameters(paramsStr string) []string {
-	var params []string
-	var currentParam strings.Builder
-	parenDepth := 0
-	bracketDepth := 0
-	braceDepth := 0
-
-	for i, char := range paramsStr {
-		switch char {
-		case '(':
-			parenDepth++
-			currentParam.WriteRune(char)
-		case ')':
-			parenDepth--
-			currentParam.WriteRune(char)
-		case '[':
-			bracketDepth++
-			currentParam.WriteRune(char)
-		case ']':
-			bracketDepth--
-			currentParam.WriteRune(char)
-		case '{':
-			braceDepth++
-			currentParam.WriteRune(char)
-		case '}':
-			braceDepth--
-			currentParam.WriteRune(char)
-		case ',':
-			if parenDepth == 0 && bracketDepth == 0 && braceDepth == 0 {
-				param := strings.TrimSpace(currentParam.String())
-				if param != "" {
-					params = append(params, param)
-				}
-				currentParam.Reset()
-			} else {
-				currentParam.WriteRune(char)
-			}
-		default:
-			currentParam.WriteRune(char)
+	var code []fastexpr.Token
+	for _, tok := range tokens {
+		if tok.Kind != fastexpr.COMMENT {
+			code = append(code, tok)
 		}
-
-		// 如果是最后一个字符，添加当前参数
-		if i == len(paramsStr)-1 {
-			param := strings.TrimSpace(currentParam.String())
-			if param != "" {
-				params = append(params, param)
-			}
+	}
+	// 语句开头的 name = 视为局部变量
+	for i, tok := range code {
+		if tok.Kind == fastexpr.IDENT && i+1 < len(code) && code[i+1].Kind == fastexpr.ASSIGN &&
+			(i == 0 || code[i-1].Kind == fastexpr.SEMICOLON) {
+			w.locals[tok.Text] = true
 		}
 	}
-
-	return params
-}
-
-// 判断是否为函数名
-func isFunctionName(s string, operatorNmae []string) bool {
-	return lo.Contains(operatorNmae, s)
+	for i, tok := range code {
+		if tok.Kind != fastexpr.IDENT {
+			continue
+		}
+		if i+1 < len(code) && (code[i+1].Kind == fastexpr.LPAREN || code[i+1].Kind == fastexpr.ASSIGN) {
+			continue
+		}
+		w.add(tok.Text)
+	}
 }
 
 // ------------------------------------------------------------------- 获取操作符参数位置 -------------------------------------------------------------------
@@ -592,75 +345,6 @@ func isFieldParam(param string) bool {
 
 // ------------------------------------------------- 字段使用情况检测 ----------------------------------------------
 
-func GetFieldData(config models.Config, token string) ([]string, map[string]ParamRule, map[string][]string) {
-
-	// 获取操作符列表
-	allOperators, err := FetchOperators(config, token)
-	if err != nil {
-		log.Fatal("获取操作符失败:", err)
-	}
-
-	var allOperatorName []string
-	for _, operator := range allOperators {
-		allOperatorName = append(allOperatorName, operator.Name)
-	}
-
-	// 手动添加特殊操作符的规则
-	specialRules := map[string]ParamRule{
-		"greater_equal": {FieldPositions: []int{0, 1}, IgnoreNamed: true},
-		"multiply":      {FieldPositions: []int{0, 1, 2, 3, 4, 5, 6}, IgnoreNamed: true},
-		"max":           {FieldPositions: []int{0, 1, 2, 3, 4, 5, 6}, IgnoreNamed: true},
-		"min":           {FieldPositions: []int{0, 1, 2, 3, 4, 5, 6}, IgnoreNamed: true},
-	}
-
-	// 获取特殊操作符的key放入切片
-	specialOperatorNames := make([]string, 0, len(specialRules))
-	for key := range specialRules {
-		specialOperatorNames = append(specialOperatorNames, key)
-	}
-
-	// 生成函数规则（自动跳过特殊操作符）
-	functionRules := GenerateFunctionRules(allOperators, specialOperatorNames)
-
-	// 将特殊操作符规则合并到functionRules中
-	for name, rule := range specialRules {
-		functionRules[name] = rule
-	}
-
-	// fmt.Printf("\n总共生成 %d 个函数规则\n\n", len(functionRules))
-
-	beginDate, _ := ConvertToUTCPlus5("2025-09-01")
-	endDate, _ := ConvertToUTCPlus5("2025-10-01")
-
-	// 获取 alpha 列表信息
-	alphaLists, _ := GetAllAlphas(config, token, models.GetAlphasRequest{
-		Limit:    50,
-		Offset:   0,
-		DateFrom: beginDate,
-		DateTo:   endDate,
-		Order:    "-dateSubmitted",
-		Type:     "REGULAR",
-	})
-
-	// var alphaFields []string
-	// var i int
-	alphaIDFieldsMap := make(map[string][]string, len(alphaLists))
-
-	for _, alpha := range alphaLists {
-		fields := extractFields(alpha.Regular.Code, allOperatorName, functionRules)
-		alphaIDFieldsMap[alpha.ID] = fields
-
-		// fmt.Printf("表达式: %s\n", alpha.Regular.Code)
-		// fmt.Printf("字段: %v, 数量: %d\n", fields, len(fields))
-		// fmt.Printf("如有疑问请访问: %s/alphas/%s\n", config.Paths.Auth, alpha.ID)
-		// fmt.Printf("或访问: %s/alpha/%s\n\n", config.Third.Addr, alpha.ID)
-		// i += 1
-		// fmt.Printf("%d\n", i)
-	}
-
-	return allOperatorName, functionRules, alphaIDFieldsMap
-}
-
 // ExtractContent 从字符串中提取内容
 // 如果字符串包含 https://platform.worldquantbrain.com，则提取最后一个斜杠后的内容
 // 否则，返回整个字符串
@@ -729,35 +413,55 @@ func contains(slice []string, target string) bool {
 	return false
 }
 
-// CheckAndPrintResults 统一处理检查和打印结果
-func CheckAndPrintResults(config models.Config, token string, checkFields []string, alphaIDFieldsMap map[string][]string, alphaID string) {
+// 统一打印字段检查结果
+func printFieldCheckResult(config models.Config, result *FieldCheckResult) {
+	if len(result.Matches) > 0 {
+		fmt.Println(i18n.T("field.found"))
+		for _, match := range result.Matches {
+			fmt.Printf("   - Alpha ID: %s\n", match.AlphaID)
+			i18n.Printf("field.overlap", match.Overlap, len(result.Fields), strings.Join(match.Fields, ", "))
+			i18n.Printf("field.match_info", match.Type, match.Settings, dateOnly(match.DateSubmitted))
+			i18n.Printf("field.detail_api", config.Paths.Auth, match.AlphaID)
+			i18n.Printf("field.detail_web", config.Third.Addr, match.AlphaID)
+		}
+	} else if result.AlphaID != "" {
+		i18n.Printf("field.no_other")
+		i18n.Printf("field.current", config.Paths.Auth, result.AlphaID)
+	} else {
+		fmt.Println(i18n.T("field.none"))
+	}
 
-	// 查找包含这些字段的alphaIDs
-	matchingAlphaIDs := FindKeysForSliceElements(alphaIDFieldsMap, checkFields)
+	if len(result.GeniusFindings) > 0 {
+		fmt.Println(i18n.T("field.genius_locked"))
+		for _, finding := range result.GeniusFindings {
+			fmt.Printf("   - %s\n", finding)
+		}
+	}
+}
 
-	if len(matchingAlphaIDs) > 0 {
-		fmt.Println("\n🔍 找到相关Alpha:")
-		for _, id := range matchingAlphaIDs {
-			fmt.Printf("   - Alpha ID: %s\n", id)
-			fmt.Printf("     如需查看详情: %s/alphas/%s\n", config.Paths.Auth, id)
-			fmt.Printf("     或访问: %s/alpha/%s\n", config.Third.Addr, id)
+// 输出字段检查的查找范围，未限定的项显示为 -
+func printFieldCheckScope(check models.FieldCheck) {
+	list := func(values []string) string {
+		if len(values) == 0 {
+			return "-"
 		}
-	} else if alphaID != "" {
-		fmt.Printf("\n⚠️  未找到包含这些字段的其他Alpha。\n")
-		fmt.Printf("   当前Alpha: %s/alphas/%s\n", config.Paths.Auth, alphaID)
-	} else {
-		fmt.Println("\n❌ 未找到包含这些字段的Alpha。")
+		return strings.Join(values, ",")
+	}
+	delay := "-"
+	if check.Delay != nil {
+		delay = strconv.Itoa(*check.Delay)
 	}
+	i18n.Printf("field.scope", cmp.Or(check.From, "-"), cmp.Or(check.To, "-"), list(check.Types), list(check.Regions), list(check.Universes), delay)
 }
 
 // GetUserInput 获取用户输入
 func GetUserInput() string {
-	fmt.Print("\n请输入（输入 'quit' 退出）: ")
+	fmt.Print(i18n.T("field.prompt"))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		fmt.Println("读取输入时出错:", err)
+		fmt.Println(i18n.T("field.read_error"), err)
 		return ""
 	}
 
@@ -765,14 +469,170 @@ func GetUserInput() string {
 	return strings.TrimSpace(input)
 }
 
+// FieldCheckResult 单个输入的字段检查结果
+type FieldCheckResult struct {
+	Input           string   `json:"input"`
+	AlphaID         string   `json:"alpha_id,omitempty"`    // 输入被识别为 Alpha ID/URL 且获取成功时填写
+	AlphaType       string   `json:"alpha_type,omitempty"`  // 获取到的 alpha 的类型，SUPER alpha 的字段来自 combo 和 selection 代码
+	FetchError      string   `json:"fetch_error,omitempty"` // 按 Alpha ID 获取失败时的原因，此时没有字段
+	Fields          []string `json:"fields"`
+	MatchedAlphaIDs []string `json:"matched_alpha_ids"`
+
+	// 每个匹配的 alpha 与输入重叠的字段、设置和提交时间，顺序与 MatchedAlphaIDs 相同
+	Matches []FieldMatch `json:"matches"`
+
+	// 高于账户 Genius 等级的操作符，config 中没有配置 genius.level 时为空
+	GeniusFindings []fastexpr.Finding `json:"genius_findings,omitempty"`
+}
+
+// CheckFieldUsage 对单个输入（URL、Alpha ID 或表达式）提取字段，在字段索引中查找 scope 范围内使用相同字段的 Alpha，
+// 同时检查表达式中是否有高于账户 Genius 等级的操作符
+func CheckFieldUsage(ctx context.Context, deps Deps, input string, scope FieldCheckScope) (*FieldCheckResult, error) {
+	extractor, err := loadFieldExtractor(ctx, &deps)
+	if err != nil {
+		return nil, err
+	}
+	return checkFieldUsage(ctx, &deps, extractor, input, scope)
+}
+
+func checkFieldUsage(ctx context.Context, deps *Deps, extractor *FieldExtractor, input string, scope FieldCheckScope) (*FieldCheckResult, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, i18n.Errorf("field.empty_input")
+	}
+
+	result := &FieldCheckResult{Input: input}
+	codes := []alphaCode{{source: "regular", code: input}}
+
+	// 输入是URL或Alpha ID时，使用Alpha的代码；SUPER alpha 没有 regular 代码，检查 combo 和 selection 代码；
+	// 获取失败时没有代码，不从 ID 或 URL 的文本中提取字段
+	alphaID, alpha, isAlphaID, err := fieldCheckAlpha(ctx, deps, extractor, input)
+	switch {
+	case !isAlphaID:
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		result.FetchError = credentials.Redact(err.Error())
+		codes = nil
+	default:
+		result.AlphaID = alphaID
+		result.AlphaType = alpha.Type
+		codes = storedAlphaCodes(*alpha)
+	}
+
+	// 在字段索引中一次查出范围内的全部历史 alpha，输入的 alpha 本身不算
+	for _, code := range codes {
+		for _, use := range code.fieldUses(extractor) {
+			if !contains(result.Fields, use.Field) {
+				result.Fields = append(result.Fields, use.Field)
+			}
+		}
+	}
+	matches, err := FindAlphasByFields(ctx, deps.Repos, result.Fields, result.AlphaID, scope)
+	if err != nil {
+		return nil, err
+	}
+	result.Matches = matches
+	result.MatchedAlphaIDs = make([]string, len(matches))
+	for i, match := range matches {
+		result.MatchedAlphaIDs[i] = match.AlphaID
+	}
+
+	// 操作符等级检查失败不影响字段检查结果
+	for _, code := range codes {
+		findings, err := GeniusFindings(ctx, *deps, code.code)
+		if err != nil {
+			programLogger("FieldCheck").Warn(i18n.T("field.genius_failed"), "error", err)
+			break
+		}
+		result.GeniusFindings = append(result.GeniusFindings, findings...)
+	}
+	return result, nil
+}
+
+// 只由字母和数字组成的输入才会按 Alpha ID 处理
+var alphaIDPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// 取得输入对应的 alpha：URL 中的 ID 和只由字母、数字组成的输入先在数据库中查找，没有时登录后通过 API 获取。
+// 只由字母、数字组成的输入在以下情况按表达式处理（isAlphaID 为 false）：是操作符或字段索引中已有的字段（如 close），
+// BRAIN 返回 404，或未登录无法确认时不含大写字母（数据字段都是小写，如 returns、vwap）。
+// isAlphaID 为 false 时 err 为读取数据库的错误，为 true 时为获取 alpha 的错误
+func fieldCheckAlpha(ctx context.Context, deps *Deps, extractor *FieldExtractor, input string) (alphaID string, alpha *ActiveAlphaList, isAlphaID bool, err error) {
+	alphaID, isURL := ExtractContent(deps.Config, input)
+	if !isURL || deps.Config.Third.Addr == "" {
+		if !alphaIDPattern.MatchString(input) {
+			return "", nil, false, nil
+		}
+		alphaID, isURL = input, false
+	}
+
+	// 1. 数据库中已保存的 alpha
+	stored, err := deps.Alphas.Get(ctx, alphaID)
+	if err == nil {
+		return alphaID, stored, true, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return "", nil, false, i18n.Errorf("field.load_alpha_failed", alphaID, err)
+	}
+
+	// 2. 已知的操作符和字段
+	if !isURL {
+		if extractor.operators[alphaID] {
+			return "", nil, false, nil
+		}
+		records, err := deps.AlphaFields.ByFields(ctx, []string{alphaID})
+		if err != nil {
+			return "", nil, false, err
+		}
+		if len(records) > 0 {
+			return "", nil, false, nil
+		}
+	}
+
+	// 3. 通过 API 获取
+	if deps.Token == "" {
+		if !isURL && strings.ToLower(alphaID) == alphaID {
+			return "", nil, false, nil
+		}
+		return alphaID, nil, true, i18n.Errorf("field.not_stored", alphaID)
+	}
+	fetched, err := retryOnUnauthorized(deps, func(token string) (models.Alpha, error) {
+		return GetAlphaByID(deps.Config, token, alphaID)
+	})
+	var statusErr brainStatusError
+	if !isURL && errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return "", nil, false, nil
+	}
+	if err != nil {
+		return alphaID, nil, true, err
+	}
+	converted := convertAlphaToDB(fetched)
+	return alphaID, &converted, true, nil
+}
+
 // 主处理函数
-func FieldCheck(config models.Config, token string) {
-	fmt.Println("\n====================== 执行字段检查 ======================")
-	fmt.Println("🚀 字段检查功能正在执行...")
-	fmt.Println("📝 支持的输入格式:")
-	fmt.Println("   1. 完整URL: https://platform.worldquantbrain.com/alpha/1Y5Nj28K")
+func FieldCheck(deps Deps) error {
+	config := deps.Config
+	ctx := context.Background()
+
+	// 操作符只加载一次，每次输入只查询字段索引
+	extractor, err := loadFieldExtractor(ctx, &deps)
+	if err != nil {
+		return err
+	}
+	scope, err := NewFieldCheckScope(config.FieldCheck)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(i18n.T("field.banner"))
+	fmt.Println(i18n.T("field.running"))
+	fmt.Println(i18n.T("field.formats"))
+	fmt.Println(i18n.T("field.format_url"))
 	fmt.Println("   2. Alpha ID: 1Y5Nj28K")
-	fmt.Println("   3. Alpha表达式: (rank(correlation(close, volume, 10)))")
+	fmt.Println(i18n.T("field.format_expr"))
+	printFieldCheckScope(config.FieldCheck)
 	fmt.Println("   ------------------------------------------------------")
 
 	for {
@@ -780,50 +640,41 @@ func FieldCheck(config models.Config, token string) {
 
 		// 检查是否退出
 		if strings.ToLower(input) == "quit" || strings.ToLower(input) == "exit" {
-			fmt.Println("👋 再见！")
+			fmt.Println(i18n.T("field.bye"))
 			break
 		}
 
 		if input == "" {
-			fmt.Println("⚠️  输入不能为空，请重新输入。")
+			fmt.Println(i18n.T("field.empty_retry"))
 			continue
 		}
 
-		// 获取全部操作符以及分解的字段和alphaID数据
-		allOperatorName, functionRules, alphaIDFieldsMap := GetFieldData(config, token)
-
-		// 处理输入
-		alphaInfo, isAlphaID := ExtractContent(config, input)
+		result, err := checkFieldUsage(ctx, &deps, extractor, input, scope)
+		if err != nil {
+			i18n.Printf("field.failed", err)
+			continue
+		}
 
-		if isAlphaID {
+		if alphaInfo, isAlphaID := ExtractContent(config, input); isAlphaID || result.AlphaID != "" || result.FetchError != "" {
 			// 输入是URL或Alpha ID
-			fmt.Printf("🔍 检测到Alpha ID: %s\n", alphaInfo)
-
-			// 尝试获取Alpha详情
-			alpha, err := GetAlphaByID(config, token, alphaInfo)
-			if err != nil {
-				fmt.Printf("❌ 无法获取Alpha '%s' 的详情: %v\n", alphaInfo, err)
-				fmt.Println("📝 尝试将其作为Alpha表达式处理...")
-
-				// 作为表达式处理
-				checkFields := extractFields(input, allOperatorName, functionRules)
-				CheckAndPrintResults(config, token, checkFields, alphaIDFieldsMap, "")
+			i18n.Printf("field.detected_id", alphaInfo)
+			if result.FetchError != "" {
+				i18n.Printf("field.fetch_failed", alphaInfo, result.FetchError)
+				fmt.Println(i18n.T("field.no_code"))
 			} else {
-				// 成功获取Alpha，提取字段并检查
-				checkFields := extractFields(alpha.Regular.Code, allOperatorName, functionRules)
-				fmt.Printf("📊 从Alpha代码中提取到 %d 个字段\n", len(checkFields))
-				CheckAndPrintResults(config, token, checkFields, alphaIDFieldsMap, alphaInfo)
+				i18n.Printf("field.fields_from_alpha", len(result.Fields))
 			}
 		} else {
 			// 输入是Alpha表达式
-			fmt.Println("📝 检测到Alpha表达式")
-			checkFields := extractFields(input, allOperatorName, functionRules)
-			fmt.Printf("📊 从表达式中提取到 %d 个字段\n", len(checkFields))
-			CheckAndPrintResults(config, token, checkFields, alphaIDFieldsMap, "")
+			fmt.Println(i18n.T("field.detected_expr"))
+			i18n.Printf("field.fields_from_expr", len(result.Fields))
 		}
 
+		printFieldCheckResult(config, result)
+
 		fmt.Println("\n" + strings.Repeat("-", 50))
 	}
 
-	fmt.Println("✅ 字段检查完成！")
+	fmt.Println(i18n.T("field.done"))
+	return nil
 }
@@ -0,0 +1,138 @@
+package small_program
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 批量字段检查 -----------------------------------------------
+// 一次检查多个表达式或 Alpha ID，便于在排队模拟前筛查一批生成的表达式；
+// 输入每行一个，或者是字符串的 JSON 数组（多行表达式需要用 JSON），报告为 JSON 或每个输入一行的 CSV
+
+// FieldCheckItem 批量检查中一个输入的结果，检查失败时只有 Error
+type FieldCheckItem struct {
+	Index      int    `json:"index"` // 从 1 开始
+	Error      string `json:"error,omitempty"`
+	ParseError string `json:"parse_error,omitempty"` // 表达式无法解析时的原因，此时字段可能不完整
+	*FieldCheckResult
+}
+
+// ParseFieldCheckInputs 解析批量输入：以 [ 开头时按 JSON 字符串数组解析，否则按 SplitExpressions 分成多个输入，
+// 每行一个 Alpha ID 或链接，表达式可以跨行
+func ParseFieldCheckInputs(data string) ([]string, error) {
+	var inputs []string
+	if strings.HasPrefix(strings.TrimSpace(data), "[") {
+		if err := json.Unmarshal([]byte(data), &inputs); err != nil {
+			return nil, i18n.Errorf("field.batch_bad_json", err)
+		}
+	} else {
+		for _, expr := range SplitExpressions(data) {
+			inputs = append(inputs, expr.Text)
+		}
+	}
+
+	result := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		if input = strings.TrimSpace(input); input != "" {
+			result = append(result, input)
+		}
+	}
+	if len(result) == 0 {
+		return nil, i18n.Errorf("field.batch_empty")
+	}
+	return result, nil
+}
+
+// BatchFieldCheck 依次检查每个输入，操作符只加载一次；单个输入失败不影响其他输入
+func BatchFieldCheck(ctx context.Context, deps Deps, inputs []string, scope FieldCheckScope) ([]FieldCheckItem, error) {
+	extractor, err := loadFieldExtractor(ctx, &deps)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]FieldCheckItem, len(inputs))
+	failed := 0
+	for i, input := range inputs {
+		items[i].Index = i + 1
+		result, err := checkFieldUsage(ctx, &deps, extractor, input, scope)
+		if err != nil {
+			items[i].Error = err.Error()
+			items[i].FieldCheckResult = &FieldCheckResult{Input: input}
+			failed++
+			continue
+		}
+		items[i].FieldCheckResult = result
+		if result.AlphaID == "" && result.FetchError == "" {
+			if _, err := fastexpr.Parse(result.Input); err != nil {
+				items[i].ParseError = err.Error()
+			}
+		}
+	}
+
+	programLogger("FieldCheck").Info(i18n.T("field.batch_done"), "inputs", len(inputs), "failed", failed)
+	return items, nil
+}
+
+// WriteFieldCheckJSON 以 JSON 数组输出批量检查结果
+func WriteFieldCheckJSON(w io.Writer, items []FieldCheckItem) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(items); err != nil {
+		return i18n.Errorf("field.batch_write_failed", err)
+	}
+	return nil
+}
+
+// 批量检查 CSV 的列
+var fieldCheckCSVHeader = []string{"index", "input", "alpha_id", "alpha_type", "fields", "field_count", "matched_alphas", "max_overlap", "matches", "genius_locked", "parse_error", "error"}
+
+// WriteFieldCheckCSV 以 CSV 输出批量检查结果，每个输入一行；
+// matches 列为 "ID:重叠字段数"，以分号分隔，顺序与 JSON 相同
+func WriteFieldCheckCSV(w io.Writer, items []FieldCheckItem) error {
+	writer := csv.NewWriter(w)
+	writer.Write(fieldCheckCSVHeader)
+	for _, item := range items {
+		maxOverlap := 0
+		matches := make([]string, len(item.Matches))
+		for i, match := range item.Matches {
+			maxOverlap = max(maxOverlap, match.Overlap)
+			matches[i] = fmt.Sprintf("%s:%d", match.AlphaID, match.Overlap)
+		}
+		genius := make([]string, len(item.GeniusFindings))
+		for i, finding := range item.GeniusFindings {
+			genius[i] = finding.String()
+		}
+		errText := item.Error
+		if errText == "" {
+			errText = item.FetchError
+		}
+
+		writer.Write([]string{
+			strconv.Itoa(item.Index),
+			item.Input,
+			item.AlphaID,
+			item.AlphaType,
+			strings.Join(item.Fields, ";"),
+			strconv.Itoa(len(item.Fields)),
+			strconv.Itoa(len(item.Matches)),
+			strconv.Itoa(maxOverlap),
+			strings.Join(matches, ";"),
+			strings.Join(genius, ";"),
+			item.ParseError,
+			errText,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return i18n.Errorf("field.batch_write_failed", err)
+	}
+	return nil
+}
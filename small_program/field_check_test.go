@@ -0,0 +1,251 @@
+package small_program
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"program-collection/models"
+)
+
+var testFieldOperators = []models.Operator{
+	{Name: "rank", Definition: "rank(x, rate=2)"},
+	{Name: "ts_mean", Definition: "ts_mean(x, d)"},
+	{Name: "ts_regression", Definition: "ts_regression(y, x, d, lag = 0, rettype = 0)"},
+	{Name: "group_rank", Definition: "group_rank(x, group)"},
+	{Name: "if_else", Definition: "if_else(input1, input2, input 3)"},
+	{Name: "multiply", Definition: "multiply(x ,y, ... , filter=false), x * y"},
+	{Name: "add", Definition: "add(x, y, filter = false), x + y"},
+	{Name: "subtract", Definition: "subtract(x, y, filter=false), x - y"},
+	{Name: "greater", Definition: "x > y"},
+	{Name: "trade_when", Definition: "trade_when(x, y, z)"},
+}
+
+func TestFieldExtractor(t *testing.T) {
+	extractor := NewFieldExtractor(testFieldOperators)
+	tests := []struct {
+		name   string
+		input  string
+		fields []string
+		uses   []FieldUse
+	}{
+		{
+			name:   "nested",
+			input:  "rank(ts_mean(close, 20))",
+			fields: []string{"close"},
+			uses:   []FieldUse{{"close", "rank/ts_mean"}},
+		},
+		{
+			name:   "named args and windows skipped",
+			input:  "ts_regression(returns, volume, 20, lag=1, rettype=vwap)",
+			fields: []string{"returns", "volume"},
+		},
+		{
+			name:   "group argument skipped",
+			input:  "group_rank(close, industry)",
+			fields: []string{"close"},
+		},
+		{
+			name:   "constants skipped",
+			input:  "if_else(close > open, nan, true)",
+			fields: []string{"close", "open"},
+			uses:   []FieldUse{{"close", "if_else/greater"}, {"open", "if_else/greater"}},
+		},
+		{
+			name:   "special variadic rule",
+			input:  "multiply(close, volume, returns, filter=true)",
+			fields: []string{"close", "volume", "returns"},
+		},
+		{
+			name:   "locals",
+			input:  "a = ts_mean(close, 5); b = a - open; rank(b)",
+			fields: []string{"close", "open"},
+			uses:   []FieldUse{{"close", "ts_mean"}, {"open", "subtract"}},
+		},
+		{
+			name:   "local used before assignment",
+			input:  "b = a + 1; a = close; b",
+			fields: []string{"close"},
+		},
+		{
+			name:   "unary operators",
+			input:  "-close + !open",
+			fields: []string{"close", "open"},
+			uses:   []FieldUse{{"close", "add/reverse"}, {"open", "add/not"}},
+		},
+		{
+			name:   "unknown function",
+			input:  "my_op(close, volume, k=cap)",
+			fields: []string{"close", "volume"},
+		},
+		{
+			name:   "repeated field",
+			input:  "rank(close) + ts_mean(close, 5)",
+			fields: []string{"close"},
+			uses:   []FieldUse{{"close", "add/rank"}, {"close", "add/ts_mean"}},
+		},
+		{
+			name:   "comments",
+			input:  "# volume 不算字段\nrank(close) // open",
+			fields: []string{"close"},
+		},
+		{
+			name:   "unparsable falls back to tokens",
+			input:  "a = close; rank(a, volume",
+			fields: []string{"close", "volume"},
+			uses:   []FieldUse{{"close", ""}, {"volume", ""}},
+		},
+		{
+			name:   "empty",
+			input:  "",
+			fields: nil,
+		},
+	}
+	for _, tt := range tests {
+		if got := extractor.Fields(tt.input); !slices.Equal(got, tt.fields) {
+			t.Errorf("%s: Fields(%q) = %q, want %q", tt.name, tt.input, got, tt.fields)
+		}
+		if tt.uses == nil {
+			continue
+		}
+		if got := extractor.Uses(tt.input); !slices.Equal(got, tt.uses) {
+			t.Errorf("%s: Uses(%q) = %v, want %v", tt.name, tt.input, got, tt.uses)
+		}
+	}
+}
+
+func TestParseDefinitionFieldPositions(t *testing.T) {
+	tests := []struct {
+		def  string
+		want []int
+	}{
+		{"rank(x, rate=2)", []int{0}},
+		{"ts_regression(y, x, d, lag = 0, rettype = 0)", []int{0, 1}},
+		{"if_else(input1, input2, input 3)", []int{0, 1, 2}},
+		{"group_rank(x, group)", []int{0}},
+		{"x > y", []int{}},
+	}
+	for _, tt := range tests {
+		if got := parseDefinition(tt.def); !slices.Equal(got, tt.want) {
+			t.Errorf("parseDefinition(%q) = %v, want %v", tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestParseFieldCheckInputs(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{"ab12XYz\n\nrank(close)\n", []string{"ab12XYz", "rank(close)"}},
+		{"a = ts_mean(close, 5);\n\nrank(a)\nab12XYz", []string{"a = ts_mean(close, 5);\n\nrank(a)", "ab12XYz"}},
+		{`["rank(close)", " ", "ab12XYz"]`, []string{"rank(close)", "ab12XYz"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseFieldCheckInputs(tt.data)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("ParseFieldCheckInputs(%q) = %q, %v, want %q", tt.data, got, err, tt.want)
+		}
+	}
+	if _, err := ParseFieldCheckInputs("\n \n"); err == nil {
+		t.Error("ParseFieldCheckInputs accepted empty input")
+	}
+}
+
+// 只由字母和数字组成的输入：已保存的 alpha 取其代码，操作符、字段索引中的字段和未登录时的小写输入按表达式处理，
+// 其他按未保存的 Alpha ID 报告
+func TestCheckFieldUsageAlphaIDs(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemoryRepos()
+	code := "rank(ts_mean(volume, 5))"
+	if _, err := repos.Alphas.CreateIfMissing(ctx, ActiveAlphaList{ID: "AAA111", Type: "REGULAR", Author: "XX1", RegularCode: &code}); err != nil {
+		t.Fatal(err)
+	}
+	fields := []AlphaField{{AlphaID: "BBB222", Field: "close", Source: "regular"}, {AlphaID: "BBB222", Field: "volume", Source: "regular"}}
+	if err := repos.AlphaFields.Replace(ctx, []string{"BBB222"}, fields); err != nil {
+		t.Fatal(err)
+	}
+	deps := Deps{Repos: repos}
+	extractor := NewFieldExtractor(testFieldOperators)
+
+	tests := []struct {
+		input      string
+		alphaID    string
+		fetchError bool
+		fields     []string
+	}{
+		{"AAA111", "AAA111", false, []string{"volume"}},
+		{"close", "", false, []string{"close"}},
+		{"rank(close)", "", false, []string{"close"}},
+		{"returns", "", false, []string{"returns"}},
+		{"rank", "", false, nil},
+		{"ab12XYz", "", true, nil},
+		{"1Y5Nj28K", "", true, nil},
+	}
+	for _, tt := range tests {
+		result, err := checkFieldUsage(ctx, &deps, extractor, tt.input, FieldCheckScope{})
+		if err != nil {
+			t.Errorf("checkFieldUsage(%q): %v", tt.input, err)
+			continue
+		}
+		if result.AlphaID != tt.alphaID || (result.FetchError != "") != tt.fetchError || !slices.Equal(result.Fields, tt.fields) {
+			t.Errorf("checkFieldUsage(%q) = alpha %q, fetch error %q, fields %q; want alpha %q, fetch error %v, fields %q",
+				tt.input, result.AlphaID, result.FetchError, result.Fields, tt.alphaID, tt.fetchError, tt.fields)
+		}
+	}
+}
+
+// 登录后未保存的 Alpha ID 通过 API 获取
+func TestCheckFieldUsageFetchesAlphaID(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	brain.Put(brainAlpha("ab12XYz", "ts_mean(returns, 20)", time.Hour, time.Hour, 1.5))
+
+	result, err := checkFieldUsage(context.Background(), &deps, NewFieldExtractor(testFieldOperators), "ab12XYz", FieldCheckScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AlphaID != "ab12XYz" || result.FetchError != "" || !slices.Equal(result.Fields, []string{"returns"}) {
+		t.Errorf("checkFieldUsage = alpha %q, fetch error %q, fields %q", result.AlphaID, result.FetchError, result.Fields)
+	}
+
+	// BRAIN 返回 404 时按表达式处理
+	for _, input := range []string{"vwap", "zz99QQq"} {
+		result, err = checkFieldUsage(context.Background(), &deps, NewFieldExtractor(testFieldOperators), input, FieldCheckScope{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.FetchError != "" || !slices.Equal(result.Fields, []string{input}) {
+			t.Errorf("%s = fetch error %q, fields %q, want it treated as a field", input, result.FetchError, result.Fields)
+		}
+	}
+
+	// 其他错误（如会话过期且无法重新登录）仍然报告
+	deps.Token = "stale-token"
+	result, err = checkFieldUsage(context.Background(), &deps, NewFieldExtractor(testFieldOperators), "zz99QQq", FieldCheckScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FetchError == "" || len(result.Fields) != 0 {
+		t.Errorf("unauthorized = fetch error %q, fields %q, want an error and no fields", result.FetchError, result.Fields)
+	}
+}
+
+// 读取 alpha 失败的仓库
+type failingAlphaRepo struct{ AlphaRepo }
+
+func (failingAlphaRepo) Get(context.Context, string) (*ActiveAlphaList, error) {
+	return nil, errors.New("database is locked")
+}
+
+// 读取数据库失败时返回错误，而不是当作未保存的 alpha 去 API 获取
+func TestCheckFieldUsageDatabaseError(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	brain.Put(brainAlpha("ab12XYz", "ts_mean(returns, 20)", time.Hour, time.Hour, 1.5))
+	deps.Alphas = failingAlphaRepo{deps.Alphas}
+
+	if _, err := checkFieldUsage(context.Background(), &deps, NewFieldExtractor(testFieldOperators), "ab12XYz", FieldCheckScope{}); err == nil {
+		t.Error("checkFieldUsage succeeded with a failing alpha repository")
+	}
+}
@@ -0,0 +1,263 @@
+package small_program
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+// ------------------------------------------------ Genius 等级与操作符 -----------------------------------------------
+// operators 表按 Genius 等级和季度分别保存操作符列表，某个操作符最早出现在哪个等级的列表中，就是可以使用它的最低等级；
+// 操作符自身的 level 也是 Genius 等级时（如 MASTER），取两者中较高的一个
+
+// geniusScopeError 等级或季度参数无效，API 据此返回 400，其余错误（如读取数据库失败）返回 500
+type geniusScopeError struct{ err error }
+
+func (e geniusScopeError) Error() string { return e.err.Error() }
+
+// 判断 err 是否由无效的等级或季度参数引起
+func isGeniusScopeError(err error) bool {
+	var scopeErr geniusScopeError
+	return errors.As(err, &scopeErr)
+}
+
+// GeniusCatalog 用 quarter 季度各等级的操作符记录创建目录，高于 level 的操作符标记为 Locked。
+// quarter 为空时使用表中最新的季度，level 为空时不标记；返回实际使用的季度
+func GeniusCatalog(ctx context.Context, repo OperatorRepo, level, quarter string) (*fastexpr.Catalog, string, error) {
+	// 1. 账户等级，为空时视为最高等级
+	accountRank := len(models.GeniusLevels) - 1
+	if level != "" {
+		accountRank = models.GeniusRank(level)
+		if accountRank < 0 {
+			return nil, "", geniusScopeError{i18n.Errorf("operators.bad_level", level, strings.Join(models.GeniusLevels, ", "))}
+		}
+	}
+
+	records, err := repo.List(ctx)
+	if err != nil {
+		return nil, "", i18n.Errorf("lint.load_operators_failed", err)
+	}
+	if len(records) == 0 {
+		return nil, "", i18n.Errorf("lint.no_operators")
+	}
+
+	// 2. 确定季度
+	quarters := geniusQuarters(records)
+	if quarter == "" {
+		quarter = quarters[len(quarters)-1]
+	} else if !slices.Contains(quarters, quarter) {
+		return nil, "", geniusScopeError{i18n.Errorf("genius.no_quarter", quarter, strings.Join(quarters, ", "))}
+	}
+
+	// 3. 每个操作符取出现过的最低等级，定义以最新写入的记录为准
+	type entry struct {
+		record Operators
+		rank   int
+	}
+	entries := map[string]*entry{}
+	var names []string
+	for _, record := range records {
+		if record.GeniusQuarter != quarter {
+			continue
+		}
+		rank := max(models.GeniusRank(record.GeniusLevel), models.GeniusRank(record.Level), 0)
+
+		e, ok := entries[record.Name]
+		if !ok {
+			entries[record.Name] = &entry{record: record, rank: rank}
+			names = append(names, record.Name)
+			continue
+		}
+		e.record = record
+		e.rank = min(e.rank, rank)
+	}
+
+	operators := make([]fastexpr.Operator, 0, len(names))
+	for _, name := range names {
+		e := entries[name]
+		op := toCatalogOperator(e.record)
+		op.GeniusLevel = models.GeniusLevels[e.rank]
+		op.Locked = e.rank > accountRank
+		operators = append(operators, op)
+	}
+	return fastexpr.NewCatalog(operators), quarter, nil
+}
+
+// 表中出现过的季度，升序（YYYY-Qn 按字符串排序即按时间排序）
+func geniusQuarters(records []Operators) []string {
+	var quarters []string
+	for _, record := range records {
+		if record.GeniusQuarter != "" && !slices.Contains(quarters, record.GeniusQuarter) {
+			quarters = append(quarters, record.GeniusQuarter)
+		}
+	}
+	slices.Sort(quarters)
+	if len(quarters) == 0 {
+		quarters = []string{""}
+	}
+	return quarters
+}
+
+// GeniusFindings 返回表达式中高于账户等级的操作符，config 中没有配置 genius.level 时返回 nil
+func GeniusFindings(ctx context.Context, deps Deps, code string) ([]fastexpr.Finding, error) {
+	genius := deps.Config.Genius
+	if genius.Level == "" {
+		return nil, nil
+	}
+
+	catalog, _, err := GeniusCatalog(ctx, deps.Operators, genius.Level, genius.Quarter)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []fastexpr.Finding
+	for _, finding := range fastexpr.Lint(code, catalog, "") {
+		if finding.Rule == "genius-level" {
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// ---- 已提交 alpha 的操作符等级报告 ----
+
+// GeniusAlphaUsage 一个已提交的 alpha 依赖的最高等级操作符
+type GeniusAlphaUsage struct {
+	AlphaID       string   `json:"alpha_id"`
+	Type          string   `json:"type"`
+	Region        string   `json:"region,omitempty"`
+	DateSubmitted string   `json:"date_submitted"`
+	Operator      string   `json:"operator,omitempty"`     // 等级最高的操作符，表达式中没有操作符时为空
+	GeniusLevel   string   `json:"genius_level,omitempty"` // 该操作符需要的最低等级
+	Locked        bool     `json:"locked"`                 // 高于账户当前等级
+	Unknown       []string `json:"unknown,omitempty"`      // 该季度目录中没有的操作符
+	ParseError    string   `json:"parse_error,omitempty"`
+}
+
+// GeniusReport 等级报告，Level 为空时不判断是否高于账户等级
+type GeniusReport struct {
+	Level   string             `json:"level"`
+	Quarter string             `json:"quarter"`
+	Alphas  []GeniusAlphaUsage `json:"alphas"`
+}
+
+// GeniusOperatorReport 对 active_alpha_list 中每个已提交的 alpha，找出它使用的等级最高的操作符
+func GeniusOperatorReport(ctx context.Context, deps Deps, level, quarter string) (*GeniusReport, error) {
+	catalog, quarter, err := GeniusCatalog(ctx, deps.Operators, level, quarter)
+	if err != nil {
+		return nil, err
+	}
+
+	alphas, _, err := deps.Alphas.List(ctx, AlphaFilter{})
+	if err != nil {
+		return nil, i18n.Errorf("genius.load_alphas_failed", err)
+	}
+
+	report := &GeniusReport{Level: level, Quarter: quarter}
+	for _, alpha := range alphas {
+		if alpha.DateSubmitted == nil || *alpha.DateSubmitted == "" {
+			continue
+		}
+		report.Alphas = append(report.Alphas, geniusAlphaUsage(alpha, catalog))
+	}
+
+	programLogger("GeniusReport").Info(i18n.T("genius.report_built"), "alphas", len(report.Alphas), "level", level, "quarter", quarter)
+	return report, nil
+}
+
+// REGULAR alpha 只有 regular_code，SUPER alpha 检查 combo_code 和 selection_code
+func geniusAlphaUsage(alpha ActiveAlphaList, catalog *fastexpr.Catalog) GeniusAlphaUsage {
+	usage := GeniusAlphaUsage{AlphaID: alpha.ID, Type: alpha.Type, DateSubmitted: *alpha.DateSubmitted}
+	if alpha.Region != nil {
+		usage.Region = *alpha.Region
+	}
+
+	bestRank := -1
+	for _, code := range []*string{alpha.RegularCode, alpha.ComboCode, alpha.SelectionCode} {
+		if code == nil || strings.TrimSpace(*code) == "" {
+			continue
+		}
+		program, err := fastexpr.Parse(*code)
+		if err != nil {
+			if usage.ParseError == "" {
+				usage.ParseError = err.Error()
+			}
+			continue
+		}
+
+		fastexpr.Inspect(program, func(node fastexpr.Node) bool {
+			call, ok := node.(*fastexpr.Call)
+			if !ok {
+				return true
+			}
+			op, known := catalog.Lookup(call.Fun.Name)
+			if !known {
+				if !slices.Contains(usage.Unknown, call.Fun.Name) {
+					usage.Unknown = append(usage.Unknown, call.Fun.Name)
+				}
+				return true
+			}
+			// 同等级时保留先出现的操作符
+			if rank := models.GeniusRank(op.GeniusLevel); rank > bestRank {
+				bestRank = rank
+				usage.Operator = op.Name
+				usage.GeniusLevel = op.GeniusLevel
+				usage.Locked = op.Locked
+			}
+			return true
+		})
+	}
+	return usage
+}
+
+// PrintGeniusReport 输出每个 alpha 的最高等级操作符，以及各等级的 alpha 数量
+func PrintGeniusReport(report *GeniusReport) {
+	level := report.Level
+	if level == "" {
+		level = "-"
+	}
+	fmt.Println(i18n.T("genius.report_title", level, report.Quarter))
+	if len(report.Alphas) == 0 {
+		fmt.Println(i18n.T("genius.report_empty"))
+		return
+	}
+
+	fmt.Println(i18n.T("genius.report_header"))
+	counts := make([]int, len(models.GeniusLevels))
+	locked := 0
+	for _, usage := range report.Alphas {
+		operator, geniusLevel := usage.Operator, usage.GeniusLevel
+		if operator == "" {
+			operator, geniusLevel = "-", "-"
+		} else {
+			counts[models.GeniusRank(geniusLevel)]++
+		}
+
+		var notes []string
+		if usage.Locked {
+			locked++
+			notes = append(notes, i18n.T("genius.note_locked"))
+		}
+		if len(usage.Unknown) > 0 {
+			notes = append(notes, i18n.T("genius.note_unknown", strings.Join(usage.Unknown, ", ")))
+		}
+		if usage.ParseError != "" {
+			notes = append(notes, i18n.T("genius.note_parse_error", usage.ParseError))
+		}
+		fmt.Printf("%-10s  %-8s  %-6s  %-28s  %-12s  %s\n", usage.AlphaID, usage.Type, usage.Region, operator, geniusLevel, strings.Join(notes, "; "))
+	}
+
+	fmt.Println()
+	for i, name := range models.GeniusLevels {
+		fmt.Println(i18n.T("genius.report_level_count", name, counts[i]))
+	}
+	if report.Level != "" {
+		fmt.Println(i18n.T("genius.report_locked_count", locked, report.Level))
+	}
+}
@@ -0,0 +1,163 @@
+package small_program
+
+import (
+	"context"
+	"testing"
+)
+
+// 2025-Q3 各等级的操作符列表，另有一个只在 2025-Q2 出现的操作符
+func newGeniusRepos(t *testing.T) Repos {
+	t.Helper()
+	repos := NewMemoryRepos()
+	records := []Operators{
+		{Name: "rank", Definition: "rank(x)", GeniusLevel: "Gold", GeniusQuarter: "2025-Q3"},
+		{Name: "ts_mean", Definition: "ts_mean(x, d)", GeniusLevel: "Gold", GeniusQuarter: "2025-Q3"},
+		{Name: "hump", Definition: "hump(x, hump = 0.01)", Level: "GRAND_MASTER", GeniusLevel: "Gold", GeniusQuarter: "2025-Q3"},
+		{Name: "rank", Definition: "rank(x, rate = 2)", GeniusLevel: "Expert", GeniusQuarter: "2025-Q3"},
+		{Name: "ts_rank", Definition: "ts_rank(x, d)", GeniusLevel: "Expert", GeniusQuarter: "2025-Q3"},
+		{Name: "ts_rank", Definition: "ts_rank(x, d)", GeniusLevel: "Master", GeniusQuarter: "2025-Q3"},
+		{Name: "group_neutralize", Definition: "group_neutralize(x, group)", GeniusLevel: "Master", GeniusQuarter: "2025-Q3"},
+		{Name: "trade_when", Definition: "trade_when(x, y, z)", GeniusLevel: "GRAND_MASTER", GeniusQuarter: "2025-Q3"},
+		{Name: "old_op", Definition: "old_op(x)", GeniusLevel: "Gold", GeniusQuarter: "2025-Q2"},
+	}
+	if _, err := repos.Operators.Insert(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	return repos
+}
+
+func TestGeniusCatalog(t *testing.T) {
+	repos := newGeniusRepos(t)
+
+	tests := []struct {
+		name        string
+		level       string
+		quarter     string
+		operator    string
+		wantQuarter string
+		wantLevel   string // 为空时该季度目录中不应有这个操作符
+		wantLocked  bool
+	}{
+		// 多个等级的列表中都有时取最低等级
+		{"lowest level wins", "Expert", "", "rank", "2025-Q3", "Gold", false},
+		{"listed at two levels", "Expert", "", "ts_rank", "2025-Q3", "Expert", false},
+		{"above account level", "Expert", "", "group_neutralize", "2025-Q3", "Master", true},
+		// 操作符自身的 level 高于所在列表时取较高的一个，GRAND_MASTER 写法也能识别
+		{"operator level", "Expert", "", "hump", "2025-Q3", "Grand Master", true},
+		{"grand master list", "Grand Master", "", "trade_when", "2025-Q3", "Grand Master", false},
+		// level 为空时不标记
+		{"no account level", "", "", "trade_when", "2025-Q3", "Grand Master", false},
+		// quarter 为空时使用最新季度，指定季度时只用该季度的记录
+		{"other quarter", "Gold", "", "old_op", "2025-Q3", "", false},
+		{"older quarter", "Gold", "2025-Q2", "old_op", "2025-Q2", "Gold", false},
+		{"not in older quarter", "Gold", "2025-Q2", "rank", "2025-Q2", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, quarter, err := GeniusCatalog(context.Background(), repos.Operators, tt.level, tt.quarter)
+			if err != nil {
+				t.Fatalf("GeniusCatalog: %v", err)
+			}
+			if quarter != tt.wantQuarter {
+				t.Errorf("quarter = %q, want %q", quarter, tt.wantQuarter)
+			}
+			op, ok := catalog.Lookup(tt.operator)
+			if tt.wantLevel == "" {
+				if ok {
+					t.Errorf("%s found in %s catalog", tt.operator, quarter)
+				}
+				return
+			}
+			if !ok || op.GeniusLevel != tt.wantLevel || op.Locked != tt.wantLocked {
+				t.Errorf("%s = %+v (found %v), want %s locked=%v", tt.operator, op, ok, tt.wantLevel, tt.wantLocked)
+			}
+		})
+	}
+
+	// 定义以最新写入的记录为准
+	catalog, _, _ := GeniusCatalog(context.Background(), repos.Operators, "", "")
+	if op, _ := catalog.Lookup("rank"); op.Definition != "rank(x, rate = 2)" {
+		t.Errorf("rank definition = %q, want the Expert record", op.Definition)
+	}
+
+	invalid := []struct{ level, quarter string }{
+		{"Platinum", ""},
+		{"Expert", "2024-Q1"},
+	}
+	for _, tt := range invalid {
+		if _, _, err := GeniusCatalog(context.Background(), repos.Operators, tt.level, tt.quarter); !isGeniusScopeError(err) {
+			t.Errorf("GeniusCatalog(%q, %q) error = %v, want a scope error", tt.level, tt.quarter, err)
+		}
+	}
+	if _, _, err := GeniusCatalog(context.Background(), NewMemoryRepos().Operators, "", ""); err == nil || isGeniusScopeError(err) {
+		t.Errorf("GeniusCatalog on an empty table error = %v", err)
+	}
+}
+
+func TestGeniusOperatorReport(t *testing.T) {
+	repos := newGeniusRepos(t)
+	ctx := context.Background()
+	submitted := "2025-09-01"
+	alphas := []ActiveAlphaList{
+		{ID: "REG1", Type: "REGULAR", DateSubmitted: &submitted, RegularCode: ptr("rank(ts_rank(close, 5))")},
+		{ID: "REG2", Type: "REGULAR", DateSubmitted: &submitted, RegularCode: ptr("group_neutralize(close, market) + trade_when(a, b, -1)")},
+		{ID: "REG3", Type: "REGULAR", DateSubmitted: &submitted, RegularCode: ptr("ts_mean(rank(close), 5)")},
+		{ID: "REG4", Type: "REGULAR", DateSubmitted: &submitted, RegularCode: ptr("close")},
+		{ID: "REG5", Type: "REGULAR", DateSubmitted: &submitted, RegularCode: ptr("foo(rank(close))")},
+		{ID: "REG6", Type: "REGULAR", DateSubmitted: &submitted, RegularCode: ptr("rank(close")},
+		{ID: "SUP1", Type: "SUPER", DateSubmitted: &submitted, ComboCode: ptr("rank(x)"), SelectionCode: ptr("ts_rank(x, 5)")},
+		{ID: "UNSUB", Type: "REGULAR", RegularCode: ptr("trade_when(a, b, -1)")},
+	}
+	for _, alpha := range alphas {
+		if _, err := repos.Alphas.CreateIfMissing(ctx, alpha); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := GeniusOperatorReport(ctx, Deps{Repos: repos}, "Expert", "")
+	if err != nil {
+		t.Fatalf("GeniusOperatorReport: %v", err)
+	}
+	if report.Quarter != "2025-Q3" || len(report.Alphas) != 7 {
+		t.Fatalf("report = %s with %d alphas, want 2025-Q3 with 7 submitted alphas", report.Quarter, len(report.Alphas))
+	}
+	usages := map[string]GeniusAlphaUsage{}
+	for _, usage := range report.Alphas {
+		usages[usage.AlphaID] = usage
+	}
+
+	tests := []struct {
+		id         string
+		operator   string
+		level      string
+		locked     bool
+		unknown    int
+		parseError bool
+	}{
+		{"REG1", "ts_rank", "Expert", false, 0, false},
+		// 取等级最高的操作符，与出现顺序无关
+		{"REG2", "trade_when", "Grand Master", true, 0, false},
+		// 同等级时保留先出现的操作符
+		{"REG3", "ts_mean", "Gold", false, 0, false},
+		{"REG4", "", "", false, 0, false},
+		{"REG5", "rank", "Gold", false, 1, false},
+		{"REG6", "", "", false, 0, true},
+		// SUPER alpha 同时检查 combo 和 selection
+		{"SUP1", "ts_rank", "Expert", false, 0, false},
+	}
+	for _, tt := range tests {
+		usage, ok := usages[tt.id]
+		if !ok {
+			t.Errorf("%s missing from report", tt.id)
+			continue
+		}
+		if usage.Operator != tt.operator || usage.GeniusLevel != tt.level || usage.Locked != tt.locked ||
+			len(usage.Unknown) != tt.unknown || (usage.ParseError != "") != tt.parseError {
+			t.Errorf("%s = %+v, want %s %s locked=%v", tt.id, usage, tt.operator, tt.level, tt.locked)
+		}
+	}
+
+	if _, err := GeniusOperatorReport(ctx, Deps{Repos: repos}, "Expert", "2024-Q1"); !isGeniusScopeError(err) {
+		t.Errorf("report for a missing quarter error = %v, want a scope error", err)
+	}
+}
@@ -0,0 +1,237 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"program-collection/credentials"
+	"program-collection/i18n"
+	"program-collection/models"
+)
+
+// ------------------------------------------------ 结构化日志 -----------------------------------------------
+
+var (
+	// 所有程序日志共用的底层 handler，SetupLogger 之前使用默认的文本输出
+	baseLogHandler slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{ReplaceAttr: redactAttr})
+	globalLogLevel              = slog.LevelInfo
+	programLevels               = map[string]slog.Level{}
+)
+
+// SetupLogger 之前的日志（如 main 中加载配置、解析凭据失败时的 fatal）同样经过 redactAttr 脱敏
+func init() {
+	slog.SetDefault(slog.New(baseLogHandler))
+}
+
+// SetupLogger 根据配置初始化全局日志：级别、text/json 格式以及可选的滚动日志文件。
+// 返回的 io.Closer 用于在程序退出时关闭日志文件
+func SetupLogger(config models.Log) (io.Closer, error) {
+	level, err := parseLogLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]slog.Level, len(config.Programs))
+	minLevel := level
+	for program, value := range config.Programs {
+		programLevel, err := parseLogLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("log.programs.%s: %v", program, err)
+		}
+		overrides[program] = programLevel
+		minLevel = min(minLevel, programLevel)
+	}
+
+	var writer io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	if config.File != "" {
+		rotating, err := newRotatingFile(config.File, config.MaxSizeMB, config.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		writer = io.MultiWriter(os.Stderr, rotating)
+		closer = rotating
+	}
+
+	// 底层 handler 放行所有程序中最低的级别，具体过滤交给 levelHandler
+	options := &slog.HandlerOptions{Level: minLevel, ReplaceAttr: redactAttr}
+	var handler slog.Handler
+	switch strings.ToLower(config.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(writer, options)
+	case "json":
+		handler = slog.NewJSONHandler(writer, options)
+	default:
+		return nil, i18n.Errorf("log.bad_format", config.Format)
+	}
+
+	baseLogHandler = handler
+	globalLogLevel = level
+	programLevels = overrides
+	slog.SetDefault(slog.New(&levelHandler{level: level, next: handler}))
+
+	return closer, nil
+}
+
+// programLogger 返回带 program 字段的日志器，级别可由 log.programs 单独配置
+func programLogger(program string) *slog.Logger {
+	level, ok := programLevels[program]
+	if !ok {
+		level = globalLogLevel
+	}
+	return slog.New(&levelHandler{level: level, next: baseLogHandler}).With("program", program)
+}
+
+func parseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(value) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, i18n.Errorf("log.bad_level", value)
+	}
+}
+
+// 日志中的消息、字符串和 error 字段都会去掉已登记的密钥
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(credentials.Redact(attr.Value.String()))
+	case slog.KindAny:
+		switch v := attr.Value.Any().(type) {
+		case error:
+			attr.Value = slog.StringValue(credentials.Redact(v.Error()))
+		case fmt.Stringer:
+			attr.Value = slog.StringValue(credentials.Redact(v.String()))
+		}
+	}
+	return attr
+}
+
+// levelHandler 在共享 handler 之前按级别过滤
+type levelHandler struct {
+	level slog.Level
+	next  slog.Handler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.next.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.next.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, next: h.next.WithGroup(name)}
+}
+
+// ------------------------------------------------ 滚动日志文件 -----------------------------------------------
+
+// rotatingFile 按大小滚动的日志文件：app.log 写满后依次改名为 app.log.1 ... app.log.N
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newRotatingFile(path string, maxSizeMB, maxBackups int) (*rotatingFile, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = 100
+	}
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, i18n.Errorf("log.mkdir_failed", err)
+	}
+
+	r := &rotatingFile{
+		path:       path,
+		maxBytes:   int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return i18n.Errorf("log.open_failed", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return i18n.Errorf("log.stat_failed", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	if r.maxBackups == 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+
+	// app.log.(N-1) -> app.log.N ... app.log -> app.log.1
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,341 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"program-collection/i18n"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ------------------------------------------------ Prometheus 指标 -----------------------------------------------
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+
+	brainRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wqb_brain_api_requests_total",
+		Help: "BRAIN API 请求次数，按接口和状态码统计",
+	}, []string{"endpoint", "code"})
+
+	brainRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wqb_brain_api_request_duration_seconds",
+		Help:    "BRAIN API 请求耗时",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"endpoint"})
+
+	brainRateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wqb_brain_api_rate_limited_total",
+		Help: "BRAIN API 返回 429 的次数",
+	}, []string{"endpoint"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wqb_job_duration_seconds",
+		Help:    "各程序单次运行耗时",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"job", "result"})
+
+	jobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "wqb_job_last_success_timestamp_seconds",
+		Help: "各程序最近一次成功完成的时间",
+	}, []string{"job"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		brainRequestsTotal,
+		brainRequestDuration,
+		brainRateLimitedTotal,
+		jobDuration,
+		jobLastSuccess,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// DoBrainRequest 发送 BRAIN API 请求并记录请求次数、耗时和 429 次数
+func DoBrainRequest(endpoint string, req *http.Request) (*http.Response, error) {
+	return doBrainRequest(endpoint, req)
+}
+
+func doBrainRequest(endpoint string, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := brainHTTPClient.Do(req)
+	brainRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		brainRequestsTotal.WithLabelValues(endpoint, "error").Inc()
+		return nil, err
+	}
+
+	brainRequestsTotal.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		brainRateLimitedTotal.WithLabelValues(endpoint).Inc()
+	}
+	return resp, nil
+}
+
+// 所有 BRAIN 请求共用的 HTTP 客户端
+var brainHTTPClient = &http.Client{}
+
+// ObserveJob 运行一个程序并记录其耗时和结果
+func ObserveJob(job string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	} else {
+		jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+	jobDuration.WithLabelValues(job, result).Observe(time.Since(start).Seconds())
+
+	return err
+}
+
+// MetricsHandler 返回 /metrics 处理器；repos 不为空时，每次抓取都会从数据库读取业务指标
+func MetricsHandler(repos *Repos) http.Handler {
+	gatherers := prometheus.Gatherers{metricsRegistry}
+	if repos != nil {
+		dbRegistry := prometheus.NewRegistry()
+		dbRegistry.MustRegister(&dbCollector{alphas: repos.Alphas, factors: repos.Factors})
+		gatherers = append(gatherers, dbRegistry)
+	}
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// ------------------------------------------------ 数据库业务指标 -----------------------------------------------
+
+var (
+	syncLagDesc = prometheus.NewDesc(
+		"wqb_alpha_sync_lag_seconds",
+		"当前时间与 active_alpha_list 中最大 date_submitted 的差值",
+		nil, nil,
+	)
+	alphaCountDesc = prometheus.NewDesc(
+		"wqb_alphas",
+		"active_alpha_list 中的 alpha 数量，按状态和地区统计",
+		[]string{"status", "region"}, nil,
+	)
+	weightFactorDesc = prometheus.NewDesc(
+		"wqb_weight_factor",
+		"最近一次记录的 weight_factor",
+		[]string{"user_id"}, nil,
+	)
+	valueFactorDesc = prometheus.NewDesc(
+		"wqb_value_factor",
+		"最近一次记录的 value_factor",
+		[]string{"user_id"}, nil,
+	)
+	scrapeErrorDesc = prometheus.NewDesc(
+		"wqb_db_scrape_error",
+		"读取数据库指标是否失败（1 表示失败）",
+		[]string{"query"}, nil,
+	)
+)
+
+// dbCollector 在抓取时从数据库读取业务指标
+type dbCollector struct {
+	alphas  AlphaRepo
+	factors FactorRepo
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- syncLagDesc
+	ch <- alphaCountDesc
+	ch <- weightFactorDesc
+	ch <- valueFactorDesc
+	ch <- scrapeErrorDesc
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c.report(ch, "sync_lag", c.collectSyncLag(ctx, ch))
+	c.report(ch, "alpha_counts", c.collectAlphaCounts(ctx, ch))
+	c.report(ch, "factors", c.collectFactors(ctx, ch))
+}
+
+func (c *dbCollector) report(ch chan<- prometheus.Metric, query string, err error) {
+	value := 0.0
+	if err != nil {
+		programLogger("Metrics").Warn(i18n.T("metrics.scrape_failed"), "query", query, "error", err)
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, value, query)
+}
+
+func (c *dbCollector) collectSyncLag(ctx context.Context, ch chan<- prometheus.Metric) error {
+	latest, ok, err := c.alphas.LatestSubmitted(ctx, "")
+	if err != nil || !ok {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(syncLagDesc, prometheus.GaugeValue, time.Since(latest).Seconds())
+	return nil
+}
+
+func (c *dbCollector) collectAlphaCounts(ctx context.Context, ch chan<- prometheus.Metric) error {
+	counts, err := c.alphas.CountByStatusRegion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range counts {
+		ch <- prometheus.MustNewConstMetric(alphaCountDesc, prometheus.GaugeValue, float64(row.Count), row.Status, row.Region)
+	}
+	return nil
+}
+
+func (c *dbCollector) collectFactors(ctx context.Context, ch chan<- prometheus.Metric) error {
+	userIDs, err := c.factors.UserIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		latest, err := c.factors.Latest(ctx, userID)
+		if err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(weightFactorDesc, prometheus.GaugeValue, latest.WeightFactor, userID)
+		ch <- prometheus.MustNewConstMetric(valueFactorDesc, prometheus.GaugeValue, latest.ValueFactor, userID)
+	}
+	return nil
+}
+
+// 数据库驱动可能把时间列返回为 time.Time、string 或 []byte
+func parseDBTime(value any) (time.Time, bool) {
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ------------------------------------------------ 指标服务模式 -----------------------------------------------
+
+// RunMetricsServer 以常驻模式提供 /metrics；配置了 metrics.syncInterval 时会定期同步新 alpha 和 wf/vf。
+// 收到 Ctrl+C 或 SIGTERM 后停止服务，并等正在进行的同步写完数据库再退出
+func RunMetricsServer(deps Deps) error {
+	fmt.Println(i18n.T("metrics.banner"))
+
+	var every time.Duration
+	if interval := deps.Config.Metrics.SyncInterval; interval != "" {
+		var err error
+		every, err = time.ParseDuration(interval)
+		if err != nil || every <= 0 {
+			return i18n.Errorf("metrics.bad_interval", interval)
+		}
+	}
+
+	addr := deps.Config.Metrics.Addr
+	if addr == "" {
+		addr = ":9090"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", MetricsHandler(&deps.Repos))
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// 与 API 服务一样，收到退出信号后不再接受新请求，同步协程在当前一轮结束后退出；再次 Ctrl+C 立即退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	syncDone := make(chan struct{})
+	go func() {
+		defer close(syncDone)
+		if every > 0 {
+			runPeriodicSync(ctx, deps, every)
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		i18n.Printf("metrics.listening", addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	programLogger("Metrics").Info(i18n.T("metrics.shutting_down"))
+	if err := httpServer.Shutdown(context.Background()); err != nil {
+		return i18n.Errorf("metrics.shutdown_failed", err)
+	}
+	<-syncDone
+	programLogger("Metrics").Info(i18n.T("metrics.stopped"))
+	return nil
+}
+
+// 定期执行非交互式的同步任务，ctx 取消后不再开始新的一轮；
+// BRAIN 会话会过期，配置了 deps.SignIn 时每轮开始前重新登录
+func runPeriodicSync(ctx context.Context, deps Deps, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	// 进行中的一轮不随 ctx 中断，避免写到一半的数据
+	jobCtx := context.WithoutCancel(ctx)
+	for {
+		if deps.SignIn != nil {
+			if token, err := deps.SignIn(); err != nil {
+				programLogger("Metrics").Error(i18n.T("metrics.signin_failed"), "error", err)
+			} else {
+				deps.Token = token
+			}
+		}
+
+		err := ObserveJob("sync-alphas", func() error {
+			_, err := SyncModifiedAlphas(jobCtx, deps)
+			return err
+		})
+		if err != nil {
+			programLogger("Metrics").Error(i18n.T("metrics.sync_alphas_failed"), "error", err)
+		}
+		err = ObserveJob("weight-value-factor", func() error {
+			_, err := RecordWeightValueFactor(jobCtx, deps)
+			return err
+		})
+		if err != nil {
+			programLogger("Metrics").Error(i18n.T("metrics.sync_factor_failed"), "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package small_program
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"program-collection/models"
+)
+
+// 每轮同步前重新登录，旧会话过期后同步和 wf/vf 记录仍能完成；ctx 取消后不再开始新的一轮
+func TestRunPeriodicSync(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	brain.Put(brainAlpha("AAA111", "rank(close)", time.Hour, time.Hour, 1.2))
+	brain.consultant = models.ConsultantResponse{
+		DateStarted: "2025-01-02",
+		Leaderboard: models.Leaderboard{User: "XX1", WeightFactor: 1.2, ValueFactor: 0.5},
+	}
+	brain.token = "fresh-token"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signIns := 0
+	deps.SignIn = func() (string, error) {
+		signIns++
+		cancel()
+		return "fresh-token", nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		runPeriodicSync(ctx, deps, time.Hour)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("runPeriodicSync did not stop after ctx was cancelled")
+	}
+
+	if signIns != 1 {
+		t.Errorf("signed in %d times, want 1", signIns)
+	}
+	if _, err := deps.Alphas.Get(context.Background(), "AAA111"); err != nil {
+		t.Errorf("alpha not synced: %v", err)
+	}
+	if _, err := deps.Factors.Latest(context.Background(), "XX1"); err != nil {
+		t.Errorf("factor not recorded: %v", err)
+	}
+}
@@ -0,0 +1,358 @@
+package small_program
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"program-collection/fastexpr"
+	"program-collection/i18n"
+)
+
+// ------------------------------------------------ 操作符使用统计 -----------------------------------------------
+// 统计已提交 alpha 的 regular_code、combo_code 和 selection_code 中 operators 表里各操作符的使用情况：
+// 按地区、提交季度和 alpha 类型分组计数，计算使用与否和 IS sharpe/fitness 的相关系数，并列出可用但从未使用的操作符。
+// 运算符按对应的操作符计数：a + b 即 add，-a 即 reverse，c ? x : y 即 if_else
+
+// OperatorUsage 一个操作符的使用情况，alpha 数指至少调用过一次的 alpha 数量
+type OperatorUsage struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"category,omitempty"`
+	Alphas    int            `json:"alphas"`
+	Calls     int            `json:"calls"`
+	ByRegion  map[string]int `json:"by_region"`
+	ByQuarter map[string]int `json:"by_quarter"`
+	ByType    map[string]int `json:"by_type"`
+
+	// 使用它的 alpha 的 IS 均值，与未使用它的 alpha 均值之差，以及使用与否（0/1）与指标的相关系数；
+	// 样本不足时为 nil
+	MeanSharpe  *float64 `json:"mean_sharpe,omitempty"`
+	SharpeLift  *float64 `json:"sharpe_lift,omitempty"`
+	SharpeCorr  *float64 `json:"sharpe_corr,omitempty"`
+	MeanFitness *float64 `json:"mean_fitness,omitempty"`
+	FitnessLift *float64 `json:"fitness_lift,omitempty"`
+	FitnessCorr *float64 `json:"fitness_corr,omitempty"`
+}
+
+// OperatorStats 操作符使用统计
+type OperatorStats struct {
+	Quarter    string          `json:"quarter"` // operators 表中使用的季度
+	Alphas     int             `json:"alphas"`
+	Unparsable []string        `json:"unparsable,omitempty"`
+	Regions    []string        `json:"regions"`
+	Quarters   []string        `json:"quarters"`
+	Types      []string        `json:"types"`
+	Operators  []OperatorUsage `json:"operators"`  // operators 表中用到过的操作符，alpha 数多的在前
+	Unknown    []OperatorUsage `json:"unknown"`    // 代码中出现但 operators 表中没有的
+	NeverUsed  []string        `json:"never_used"` // 账户等级可用但从未使用的
+}
+
+// 一个 alpha 用到的操作符及调用次数
+type alphaOperatorUse struct {
+	alpha ActiveAlphaList
+	calls map[string]int
+}
+
+// OperatorUsageStats 统计 filter 范围内已提交 alpha 的操作符使用情况；
+// 操作符列表取自 operators 表中 quarter 季度（为空时为最新季度）的记录，高于 level 等级的操作符不算可用
+func OperatorUsageStats(ctx context.Context, deps Deps, filter AlphaFilter, level, quarter string) (*OperatorStats, error) {
+	// 1. 操作符列表：该季度的全部操作符
+	catalog, quarter, err := GeniusCatalog(ctx, deps.Operators, level, quarter)
+	if err != nil {
+		return nil, err
+	}
+	records, err := deps.Operators.List(ctx)
+	if err != nil {
+		return nil, i18n.Errorf("lint.load_operators_failed", err)
+	}
+	categories := map[string]string{}
+	var names []string
+	for _, record := range records {
+		if record.GeniusQuarter != quarter {
+			continue
+		}
+		if _, ok := categories[record.Name]; !ok {
+			names = append(names, record.Name)
+		}
+		categories[record.Name] = record.Category
+	}
+
+	// 2. 解析已提交 alpha 的代码
+	alphas, _, err := deps.Alphas.List(ctx, filter)
+	if err != nil {
+		return nil, i18n.Errorf("opstats.load_alphas_failed", err)
+	}
+	stats := &OperatorStats{Quarter: quarter}
+	var uses []alphaOperatorUse
+	for _, alpha := range alphas {
+		if alpha.DateSubmitted == nil || *alpha.DateSubmitted == "" {
+			continue
+		}
+		// 代码都无法解析的 alpha 不参与统计
+		use := alphaOperatorUse{alpha: alpha, calls: map[string]int{}}
+		parsed := false
+		for _, code := range storedAlphaCodes(alpha) {
+			program, err := fastexpr.Parse(code.code)
+			if err != nil {
+				name := alpha.ID
+				if code.source != "regular" {
+					name += "/" + code.source
+				}
+				stats.Unparsable = append(stats.Unparsable, name)
+				continue
+			}
+			countOperatorCalls(program, use.calls)
+			parsed = true
+		}
+		if parsed {
+			uses = append(uses, use)
+		}
+	}
+	stats.Alphas = len(uses)
+
+	// 3. 按操作符汇总
+	usages := map[string]*OperatorUsage{}
+	for _, use := range uses {
+		region, alphaQuarter := derefOr(use.alpha.Region, "-"), "-"
+		if submitted, ok := submittedAt(use.alpha); ok {
+			alphaQuarter = CurrentQuarter(submitted)
+		}
+		addDimension(&stats.Regions, region)
+		addDimension(&stats.Quarters, alphaQuarter)
+		addDimension(&stats.Types, use.alpha.Type)
+
+		for name, calls := range use.calls {
+			usage, ok := usages[name]
+			if !ok {
+				usage = &OperatorUsage{Name: name, Category: categories[name], ByRegion: map[string]int{}, ByQuarter: map[string]int{}, ByType: map[string]int{}}
+				usages[name] = usage
+			}
+			usage.Alphas++
+			usage.Calls += calls
+			usage.ByRegion[region]++
+			usage.ByQuarter[alphaQuarter]++
+			usage.ByType[use.alpha.Type]++
+		}
+	}
+
+	// 4. 与 IS sharpe/fitness 的关系
+	for name, usage := range usages {
+		usage.MeanSharpe, usage.SharpeLift, usage.SharpeCorr = operatorMetric(uses, name, func(a ActiveAlphaList) *float64 { return a.IsSharpe })
+		usage.MeanFitness, usage.FitnessLift, usage.FitnessCorr = operatorMetric(uses, name, func(a ActiveAlphaList) *float64 { return a.IsFitness })
+
+		if _, known := categories[name]; known {
+			stats.Operators = append(stats.Operators, *usage)
+		} else {
+			stats.Unknown = append(stats.Unknown, *usage)
+		}
+	}
+	for _, list := range [][]OperatorUsage{stats.Operators, stats.Unknown} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Alphas != list[j].Alphas {
+				return list[i].Alphas > list[j].Alphas
+			}
+			return list[i].Name < list[j].Name
+		})
+	}
+	slices.Sort(stats.Regions)
+	slices.Sort(stats.Quarters)
+	slices.Sort(stats.Types)
+
+	// 5. 可用但从未使用的操作符
+	for _, name := range names {
+		if op, ok := catalog.Lookup(name); ok && !op.Locked && usages[name] == nil {
+			stats.NeverUsed = append(stats.NeverUsed, name)
+		}
+	}
+	slices.Sort(stats.NeverUsed)
+
+	programLogger("OperatorStats").Info(i18n.T("opstats.built"), "alphas", stats.Alphas, "operators", len(stats.Operators), "never_used", len(stats.NeverUsed))
+	return stats, nil
+}
+
+// 统计一段代码中各操作符的调用次数
+func countOperatorCalls(program *fastexpr.Program, calls map[string]int) {
+	fastexpr.Inspect(program, func(node fastexpr.Node) bool {
+		switch n := node.(type) {
+		case *fastexpr.Call:
+			calls[n.Fun.Name]++
+		case *fastexpr.BinaryOp:
+			calls[fastexpr.BinaryOperatorName(n.Op)]++
+		case *fastexpr.UnaryOp:
+			switch n.Op {
+			case fastexpr.SUB:
+				// -5 这样的负数不算调用
+				if _, number := n.X.(*fastexpr.Number); !number {
+					calls["reverse"]++
+				}
+			case fastexpr.NOT:
+				calls["not"]++
+			}
+		case *fastexpr.Ternary:
+			calls["if_else"]++
+		}
+		return true
+	})
+}
+
+func addDimension(values *[]string, value string) {
+	if !slices.Contains(*values, value) {
+		*values = append(*values, value)
+	}
+}
+
+// 相关系数至少需要的样本数（使用和未使用的 alpha 各自）
+const minMetricSamples = 2
+
+// 使用 name 的 alpha 的指标均值、与未使用的 alpha 均值之差，以及使用与否与指标的相关系数（点二列相关）
+func operatorMetric(uses []alphaOperatorUse, name string, metric func(ActiveAlphaList) *float64) (mean, lift, corr *float64) {
+	var with, without []float64
+	for _, use := range uses {
+		value := metric(use.alpha)
+		if value == nil {
+			continue
+		}
+		if use.calls[name] > 0 {
+			with = append(with, *value)
+		} else {
+			without = append(without, *value)
+		}
+	}
+	if len(with) == 0 {
+		return nil, nil, nil
+	}
+	meanWith := average(with)
+	mean = &meanWith
+	if len(without) < minMetricSamples || len(with) < minMetricSamples {
+		return mean, nil, nil
+	}
+
+	meanWithout := average(without)
+	diff := meanWith - meanWithout
+	lift = &diff
+
+	// r = (m1 - m0) / s * sqrt(p * q)，s 为全部样本的总体标准差
+	all := append(append([]float64{}, with...), without...)
+	overall := average(all)
+	variance := 0.0
+	for _, value := range all {
+		variance += (value - overall) * (value - overall)
+	}
+	s := math.Sqrt(variance / float64(len(all)))
+	if s == 0 {
+		return mean, lift, nil
+	}
+	p := float64(len(with)) / float64(len(all))
+	r := diff / s * math.Sqrt(p*(1-p))
+	corr = &r
+	return mean, lift, corr
+}
+
+func average(values []float64) float64 {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// ---- 输出 ----
+
+// 相关系数排行的默认长度
+const operatorRankSize = 10
+
+// PrintOperatorStats 输出操作符使用统计；by 为 region、quarter 或 type 时按该维度输出各操作符的 alpha 数，
+// minAlphas 为参与相关系数排行的最少 alpha 数
+func PrintOperatorStats(stats *OperatorStats, by string, minAlphas int) {
+	fmt.Println(i18n.T("opstats.title", stats.Alphas, stats.Quarter, len(stats.Operators), len(stats.Unknown)))
+	if len(stats.Unparsable) > 0 {
+		fmt.Println(i18n.T("opstats.unparsable", len(stats.Unparsable), strings.Join(stats.Unparsable, ", ")))
+	}
+	if stats.Alphas == 0 {
+		return
+	}
+
+	// 1. 使用次数与 IS 指标
+	fmt.Println()
+	fmt.Println(i18n.T("opstats.usage_header"))
+	for _, usage := range stats.Operators {
+		fmt.Printf("%-28s  %6d  %6d  %8s  %8s  %8s  %8s  %8s  %8s\n", usage.Name, usage.Alphas, usage.Calls,
+			formatMetric(usage.MeanSharpe), formatMetric(usage.SharpeLift), formatMetric(usage.SharpeCorr),
+			formatMetric(usage.MeanFitness), formatMetric(usage.FitnessLift), formatMetric(usage.FitnessCorr))
+	}
+
+	// 2. 按维度分组
+	if dimensions, pick := operatorDimension(stats, by); pick != nil {
+		fmt.Println()
+		fmt.Println(i18n.T("opstats.by_header", by))
+		fmt.Printf("%-28s", "")
+		for _, value := range dimensions {
+			fmt.Printf("  %8s", value)
+		}
+		fmt.Println()
+		for _, usage := range stats.Operators {
+			fmt.Printf("%-28s", usage.Name)
+			counts := pick(usage)
+			for _, value := range dimensions {
+				fmt.Printf("  %8d", counts[value])
+			}
+			fmt.Println()
+		}
+	}
+
+	// 3. 与 IS sharpe/fitness 正相关最强的操作符
+	for _, rank := range []struct {
+		key  string
+		corr func(OperatorUsage) *float64
+	}{
+		{"opstats.top_sharpe", func(u OperatorUsage) *float64 { return u.SharpeCorr }},
+		{"opstats.top_fitness", func(u OperatorUsage) *float64 { return u.FitnessCorr }},
+	} {
+		var ranked []OperatorUsage
+		for _, usage := range stats.Operators {
+			if usage.Alphas >= minAlphas && rank.corr(usage) != nil && *rank.corr(usage) > 0 {
+				ranked = append(ranked, usage)
+			}
+		}
+		sort.SliceStable(ranked, func(i, j int) bool { return *rank.corr(ranked[i]) > *rank.corr(ranked[j]) })
+		fmt.Println()
+		fmt.Println(i18n.T(rank.key, minAlphas))
+		if len(ranked) == 0 {
+			fmt.Println(i18n.T("opstats.rank_empty"))
+		}
+		for _, usage := range ranked[:min(operatorRankSize, len(ranked))] {
+			fmt.Printf("   %+.3f  %-28s  %d\n", *rank.corr(usage), usage.Name, usage.Alphas)
+		}
+	}
+
+	// 4. 不在 operators 表中的操作符和从未使用的操作符
+	if len(stats.Unknown) > 0 {
+		fmt.Println()
+		names := make([]string, len(stats.Unknown))
+		for i, usage := range stats.Unknown {
+			names[i] = fmt.Sprintf("%s(%d)", usage.Name, usage.Alphas)
+		}
+		fmt.Println(i18n.T("opstats.unknown", strings.Join(names, ", ")))
+	}
+	fmt.Println()
+	fmt.Println(i18n.T("opstats.never_used", len(stats.NeverUsed)))
+	for _, name := range stats.NeverUsed {
+		fmt.Printf("   - %s\n", name)
+	}
+}
+
+// 按维度取各操作符的 alpha 数，by 不是 region、quarter、type 时返回 nil
+func operatorDimension(stats *OperatorStats, by string) ([]string, func(OperatorUsage) map[string]int) {
+	switch by {
+	case "region":
+		return stats.Regions, func(u OperatorUsage) map[string]int { return u.ByRegion }
+	case "quarter":
+		return stats.Quarters, func(u OperatorUsage) map[string]int { return u.ByQuarter }
+	case "type":
+		return stats.Types, func(u OperatorUsage) map[string]int { return u.ByType }
+	}
+	return nil, nil
+}
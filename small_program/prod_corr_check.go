@@ -3,7 +3,9 @@ package small_program
 import (
 	"fmt"
 	"math"
+	"time"
 
+	"program-collection/i18n"
 	"program-collection/models"
 )
 
@@ -49,16 +51,17 @@ func GetProdCorrMath(alphaLists []models.Alpha) (prod_corr_min float64, prod_cor
 	return
 }
 
-// ------------------------------------------------ 相似度计算 -----------------------------------------------
-
-func ProdCorrCheck(config models.Config, token string) {
-	fmt.Println("\n====================== 执行相似度检测 ======================")
-
-	dateFrom, _ := ConvertToUTCPlus5("2025-10-01 00:00:00")
-	dateTo, _ := ConvertToUTCPlus5("2025-11-01 00:00:00")
+// ProdCorrStats prod_corr 统计结果
+type ProdCorrStats struct {
+	AlphaCount int     `json:"alpha_count"`
+	Min        float64 `json:"min"`
+	Max        float64 `json:"max"`
+	Avg        float64 `json:"avg"`
+}
 
-	// 获取 alpha 列表信息
-	alphaLists, _ := GetAllAlphas(config, token, models.GetAlphasRequest{
+// GetProdCorrStats 统计提交时间在 [dateFrom, dateTo) 内的 REGULAR alpha 的 prod_corr
+func GetProdCorrStats(config models.Config, token string, dateFrom, dateTo time.Time) (ProdCorrStats, error) {
+	alphaLists, err := GetAllAlphas(config, token, models.GetAlphasRequest{
 		Limit:    50,
 		Offset:   0,
 		DateFrom: dateFrom,
@@ -66,15 +69,32 @@ func ProdCorrCheck(config models.Config, token string) {
 		Order:    "-dateSubmitted",
 		Type:     "REGULAR",
 	})
+	if err != nil {
+		return ProdCorrStats{}, err
+	}
 
-	// 计算数学统计量 prod_corr
 	min, max, avg, count := GetProdCorrMath(alphaLists)
+	return ProdCorrStats{AlphaCount: count, Min: min, Max: max, Avg: avg}, nil
+}
+
+// ------------------------------------------------ 相似度计算 -----------------------------------------------
+
+func ProdCorrCheck(config models.Config, token string) error {
+	fmt.Println(i18n.T("prodcorr.banner"))
+
+	dateFrom, _ := ConvertToUTCPlus5("2025-10-01 00:00:00")
+	dateTo, _ := ConvertToUTCPlus5("2025-11-01 00:00:00")
+
+	// 获取 alpha 列表信息并计算数学统计量 prod_corr
+	stats, err := GetProdCorrStats(config, token, dateFrom, dateTo)
+	if err != nil {
+		return i18n.Errorf("common.fetch_alphas_failed", err)
+	}
 
 	// 打印 prod_corr 统计学量结果
-	fmt.Printf("\n统计结果如下:\n")
-	fmt.Printf("十月共提交 (不包括sa) alpha 数量: %d, prod_corr最小值: %.4f, 最大值: %.4f, 平均值: %.4f", count, min, max, avg)
+	i18n.Printf("prodcorr.header")
+	i18n.Printf("prodcorr.stats", stats.AlphaCount, stats.Min, stats.Max, stats.Avg)
 
-	fmt.Println("相似度检测功能正在执行...")
-	// TODO: 实现实际的相似度检测逻辑
-	fmt.Println("相似度检测完成！")
+	fmt.Println(i18n.T("prodcorr.done"))
+	return nil
 }
@@ -0,0 +1,378 @@
+package small_program
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"program-collection/models"
+)
+
+// ------------------------------------------------ BRAIN 接口替身 -----------------------------------------------
+
+// fakeBrain 用 httptest.Server 模拟 BRAIN 接口：alpha 列表（按提交、修改时间过滤并分页）、
+// 单个 alpha、操作符、研究顾问和金字塔数据
+type fakeBrain struct {
+	mu         sync.Mutex
+	token      string // 只接受该会话（Cookie t= 或 Bearer），其他 token 返回 401
+	alphas     map[string]models.Alpha
+	consultant models.ConsultantResponse
+	pyramids   []models.Pyramids
+}
+
+func newFakeBrain(t *testing.T) (*fakeBrain, Deps) {
+	t.Helper()
+	brain := &fakeBrain{token: "test-token", alphas: map[string]models.Alpha{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /alphas/{id}", brain.handleAlpha)
+	mux.HandleFunc("GET /users/self/alphas", brain.handleAlphaList)
+	mux.HandleFunc("GET /operators", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []models.Operator{
+			{Name: "rank", Definition: "rank(x, rate=2)"},
+			{Name: "ts_mean", Definition: "ts_mean(x, d)"},
+			{Name: "add", Definition: "add(x, y, filter = false)"},
+		})
+	})
+	mux.HandleFunc("GET /users/self/consultant", func(w http.ResponseWriter, r *http.Request) {
+		brain.mu.Lock()
+		defer brain.mu.Unlock()
+		writeJSON(w, http.StatusOK, brain.consultant)
+	})
+	mux.HandleFunc("GET /users/self/activities/pyramid-alphas", func(w http.ResponseWriter, r *http.Request) {
+		brain.mu.Lock()
+		defer brain.mu.Unlock()
+		writeJSON(w, http.StatusOK, models.PyramidsResponse{Pyramids: brain.pyramids})
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brain.mu.Lock()
+		token := brain.token
+		brain.mu.Unlock()
+		if r.Header.Get("Cookie") != "t="+token && r.Header.Get("Authorization") != "Bearer "+token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Incorrect authentication credentials."})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	config := models.Config{
+		Third: models.Third{Addr: server.URL},
+		Paths: models.Paths{
+			Alpha:      "/alphas",
+			AlphaList:  "/users/self/alphas",
+			Operator:   "/operators",
+			Consultant: "/users/self/consultant",
+		},
+		Sync: models.Sync{Concurrency: 2, RequestsPerSecond: 1000, RetryFile: filepath.Join(t.TempDir(), "retry.txt")},
+	}
+	return brain, Deps{Config: config, Token: "test-token", Repos: NewMemoryRepos()}
+}
+
+// Put 新增或替换 alpha
+func (b *fakeBrain) Put(alphas ...models.Alpha) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, alpha := range alphas {
+		b.alphas[alpha.ID] = alpha
+	}
+}
+
+func (b *fakeBrain) handleAlpha(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	alpha, ok := b.alphas[r.PathValue("id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found."})
+		return
+	}
+	writeJSON(w, http.StatusOK, alpha)
+}
+
+func (b *fakeBrain) handleAlphaList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	order := query.Get("order")
+
+	b.mu.Lock()
+	var matched []models.Alpha
+	for _, alpha := range b.alphas {
+		if afterParam(query.Get("dateSubmitted>"), alpha.DateSubmitted, true) &&
+			afterParam(query.Get("dateSubmitted<"), alpha.DateSubmitted, false) &&
+			afterParam(query.Get("dateModified>"), alpha.DateModified, true) {
+			matched = append(matched, alpha)
+		}
+	}
+	b.mu.Unlock()
+
+	slices.SortFunc(matched, func(a, c models.Alpha) int {
+		if order == "dateModified" {
+			return strings.Compare(a.DateModified, c.DateModified)
+		}
+		return strings.Compare(a.DateSubmitted, c.DateSubmitted)
+	})
+
+	response := models.AlphaListResponse{Count: len(matched)}
+	end := min(offset+limit, len(matched))
+	if offset < end {
+		response.Results = matched[offset:end]
+	}
+	if end < len(matched) {
+		next := r.URL.Path + "?offset=" + strconv.Itoa(end)
+		response.Next = &next
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// 未带参数时不过滤；after 为 true 时要求 value 晚于参数，否则要求早于参数
+func afterParam(param, value string, after bool) bool {
+	if param == "" {
+		return true
+	}
+	bound, err := time.Parse("2006-01-02T15:04:05.000Z", param)
+	if err != nil {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	if after {
+		return t.After(bound)
+	}
+	return t.Before(bound)
+}
+
+// 构造一个 REGULAR alpha，提交和修改时间为 now 之前的时长
+func brainAlpha(id, code string, submittedAgo, modifiedAgo time.Duration, sharpe float64) models.Alpha {
+	now := time.Now().UTC().Truncate(time.Second)
+	return models.Alpha{
+		ID:            id,
+		Type:          "REGULAR",
+		Author:        "XX1",
+		Settings:      models.Settings{Region: "USA", Universe: "TOP3000", Delay: 1},
+		Regular:       &models.AlphaCode{Code: code},
+		DateSubmitted: now.Add(-submittedAgo).Format(time.RFC3339),
+		DateModified:  now.Add(-modifiedAgo).Format(time.RFC3339),
+		Stage:         "OS",
+		Status:        "ACTIVE",
+		IS:            &models.Performance{Sharpe: sharpe, Fitness: 1},
+	}
+}
+
+// ------------------------------------------------ 同步程序 -----------------------------------------------
+
+func TestFetchNewAlphas(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	ctx := context.Background()
+	day := 24 * time.Hour
+	brain.Put(
+		brainAlpha("AAA111", "rank(close)", 3*day, 3*day, 1.2),
+		brainAlpha("BBB222", "ts_mean(volume, 5)", 2*day, 2*day, 1.4),
+	)
+
+	// 1. 空库从五年前开始获取全部 alpha，并写入字段索引
+	if err := FetchNewAlphas(ctx, deps); err != nil {
+		t.Fatalf("FetchNewAlphas: %v", err)
+	}
+	if count, _ := deps.Alphas.Count(ctx); count != 2 {
+		t.Fatalf("Count = %d, want 2", count)
+	}
+	rows, err := deps.AlphaFields.ByFields(ctx, []string{"close", "volume"})
+	if err != nil || len(rows) != 2 {
+		t.Errorf("ByFields = %+v, %v; want close and volume indexed", rows, err)
+	}
+
+	// 2. 再次运行只获取新提交的 alpha，已有的不重复插入
+	brain.Put(brainAlpha("CCC333", "rank(open)", time.Hour, time.Hour, 0.8))
+	if err := FetchNewAlphas(ctx, deps); err != nil {
+		t.Fatalf("FetchNewAlphas again: %v", err)
+	}
+	ids, _ := deps.Alphas.IDs(ctx)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"AAA111", "BBB222", "CCC333"}) {
+		t.Errorf("IDs = %v", ids)
+	}
+}
+
+func TestSyncModifiedAlphas(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	ctx := context.Background()
+	day := 24 * time.Hour
+
+	// 数据库中已有旧版本的 AAA111，接口返回 sharpe 变化后的版本和新的 BBB222
+	stored := convertAlphaToDB(brainAlpha("AAA111", "rank(close)", 5*day, 5*day, 1.0))
+	if _, err := deps.Alphas.InsertIgnore(ctx, []ActiveAlphaList{stored}); err != nil {
+		t.Fatalf("InsertIgnore: %v", err)
+	}
+	modified := brainAlpha("AAA111", "rank(close)", 5*day, 2*day, 1.5)
+	brain.Put(modified, brainAlpha("BBB222", "ts_mean(volume, 5)", day, day, 1.1))
+
+	// 1. 首次同步：一个插入、一个更新，水位推进到最大修改时间
+	result, err := SyncModifiedAlphas(ctx, deps)
+	if err != nil {
+		t.Fatalf("SyncModifiedAlphas: %v", err)
+	}
+	if result.Fetched != 2 || result.Inserted != 1 || result.Updated != 1 || result.Failed != 0 {
+		t.Errorf("result = %+v, want 2 fetched, 1 inserted, 1 updated", result)
+	}
+	watermark, ok, err := deps.SyncState.Watermark(ctx, alphaSyncName)
+	if err != nil || !ok {
+		t.Fatalf("Watermark = %v, %v, %v", watermark, ok, err)
+	}
+	if want, _ := time.Parse(time.RFC3339, brain.alphas["BBB222"].DateModified); !watermark.Equal(want) {
+		t.Errorf("watermark = %v, want %v", watermark, want)
+	}
+
+	got, err := deps.Alphas.Get(ctx, "AAA111")
+	if err != nil || got.IsSharpe == nil || *got.IsSharpe != 1.5 {
+		t.Errorf("AAA111 after sync = %+v, %v", got, err)
+	}
+	changes, err := deps.Changes.ForAlpha(ctx, "AAA111")
+	if err != nil || !slices.ContainsFunc(changes, func(c AlphaChange) bool { return c.Field == "is_sharpe" }) {
+		t.Errorf("changes = %+v, %v; want is_sharpe", changes, err)
+	}
+
+	// 2. 再次同步只取水位之后（含重叠窗口）的 alpha，没有变化时不写入
+	result, err = SyncModifiedAlphas(ctx, deps)
+	if err != nil {
+		t.Fatalf("SyncModifiedAlphas again: %v", err)
+	}
+	if result.Fetched != 1 || result.Unchanged != 1 || result.Inserted+result.Updated != 0 {
+		t.Errorf("second result = %+v, want 1 fetched and unchanged", result)
+	}
+}
+
+func TestUpdateExistingAlphas(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	ctx := context.Background()
+	day := 24 * time.Hour
+
+	// AAA111 接口中有新的 sharpe；BBB222 接口中暂时不存在
+	stored := []ActiveAlphaList{
+		convertAlphaToDB(brainAlpha("AAA111", "rank(close)", 3*day, 3*day, 1.0)),
+		convertAlphaToDB(brainAlpha("BBB222", "rank(open)", 2*day, 2*day, 1.0)),
+	}
+	if _, err := deps.Alphas.InsertIgnore(ctx, stored); err != nil {
+		t.Fatalf("InsertIgnore: %v", err)
+	}
+	brain.Put(brainAlpha("AAA111", "rank(close)", 3*day, day, 2.0))
+
+	// 1. 拉取失败的 ID 写入重试文件
+	result, err := UpdateExistingAlphas(ctx, deps)
+	if err != nil {
+		t.Fatalf("UpdateExistingAlphas: %v", err)
+	}
+	if result.Total != 2 || result.Updated != 1 || !slices.Equal(result.Failed, []string{"BBB222"}) {
+		t.Errorf("result = %+v, want 1 updated and BBB222 failed", result)
+	}
+	if result.Changes.ChangedAlphas != 1 || result.Changes.Fields["is_sharpe"] != 1 {
+		t.Errorf("changes = %+v", result.Changes)
+	}
+	retryIDs, err := readRetryFile(deps.Config.Sync.RetryPath())
+	if err != nil || !slices.Equal(retryIDs, []string{"BBB222"}) {
+		t.Errorf("retry file = %v, %v", retryIDs, err)
+	}
+
+	// 2. 重试成功后删除重试文件
+	brain.Put(brainAlpha("BBB222", "rank(open)", 2*day, day, 1.3))
+	result, err = RetryFailedAlphas(ctx, deps)
+	if err != nil {
+		t.Fatalf("RetryFailedAlphas: %v", err)
+	}
+	if result.Total != 1 || result.Updated != 1 || len(result.Failed) != 0 {
+		t.Errorf("retry result = %+v", result)
+	}
+	if _, err := os.Stat(deps.Config.Sync.RetryPath()); !os.IsNotExist(err) {
+		t.Errorf("retry file still exists: %v", err)
+	}
+	got, _ := deps.Alphas.Get(ctx, "BBB222")
+	if got == nil || got.IsSharpe == nil || *got.IsSharpe != 1.3 {
+		t.Errorf("BBB222 after retry = %+v", got)
+	}
+}
+
+// ------------------------------------------------ wf/vf 和金字塔 -----------------------------------------------
+
+func TestSaveWeightValueFactor(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	ctx := context.Background()
+	brain.consultant = models.ConsultantResponse{
+		DateStarted: "2025-01-02",
+		Leaderboard: models.Leaderboard{User: "XX1", WeightFactor: 1.2, ValueFactor: 0.5},
+	}
+
+	// 1. 首次记录
+	if err := SaveWeightValueFactor(deps); err != nil {
+		t.Fatalf("SaveWeightValueFactor: %v", err)
+	}
+	latest, err := deps.Factors.Latest(ctx, "XX1")
+	if err != nil || latest.WeightFactor != 1.2 || latest.WeightFactorChange != 0 {
+		t.Fatalf("Latest = %+v, %v", latest, err)
+	}
+
+	// 2. 同一天再次记录时覆盖当天的数据，并计算与上次相比的变化
+	brain.mu.Lock()
+	brain.consultant.Leaderboard.WeightFactor = 1.5
+	brain.mu.Unlock()
+	stat, err := RecordWeightValueFactor(ctx, deps)
+	if err != nil {
+		t.Fatalf("RecordWeightValueFactor: %v", err)
+	}
+	if count, _ := deps.Factors.Count(ctx, "XX1"); count != 1 {
+		t.Errorf("Count = %d, want 1 record for today", count)
+	}
+	if diff := stat.WeightFactorChange - 0.3; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("WeightFactorChange = %v, want 0.3", stat.WeightFactorChange)
+	}
+	if latest, _ := deps.Factors.Latest(ctx, "XX1"); latest == nil || latest.WeightFactor != 1.5 {
+		t.Errorf("Latest after upsert = %+v", latest)
+	}
+}
+
+func TestSnapshotPyramidAlphas(t *testing.T) {
+	brain, deps := newFakeBrain(t)
+	ctx := context.Background()
+	brain.pyramids = []models.Pyramids{
+		{Category: models.Categorys{ID: "pv", Name: "Price Volume"}, Region: "USA", Delay: 1, AlphaCount: 3},
+		{Category: models.Categorys{ID: "fundamental", Name: "Fundamental"}, Region: "USA", Delay: 1, AlphaCount: 1},
+	}
+
+	if _, err := SnapshotPyramidAlphas(ctx, deps, "2025-Q3", "XX1"); err != nil {
+		t.Fatalf("SnapshotPyramidAlphas: %v", err)
+	}
+
+	// 再次快照时替换该季度的全部记录，其他季度不受影响
+	brain.mu.Lock()
+	brain.pyramids = []models.Pyramids{
+		{Category: models.Categorys{ID: "pv", Name: "Price Volume"}, Region: "USA", Delay: 1, AlphaCount: 5},
+	}
+	brain.mu.Unlock()
+	if _, err := SnapshotPyramidAlphas(ctx, deps, "2025-Q3", "XX1"); err != nil {
+		t.Fatalf("SnapshotPyramidAlphas again: %v", err)
+	}
+	if _, err := SnapshotPyramidAlphas(ctx, deps, "2025-Q2", "XX1"); err != nil {
+		t.Fatalf("SnapshotPyramidAlphas Q2: %v", err)
+	}
+
+	records, err := deps.Pyramids.List(ctx, "XX1", "2025-Q3")
+	if err != nil || len(records) != 1 || records[0].CategoryID != "pv" || records[0].AlphaCount != 5 {
+		t.Errorf("List(2025-Q3) = %+v, %v", records, err)
+	}
+	if count, _ := deps.Pyramids.Count(ctx, "XX1", "2025-Q2"); count != 1 {
+		t.Errorf("Count(2025-Q2) = %d, want 1", count)
+	}
+
+	if _, err := SnapshotPyramidAlphas(ctx, deps, "2025Q3", "XX1"); err == nil {
+		t.Error("SnapshotPyramidAlphas accepted a bad quarter")
+	}
+}
@@ -2,14 +2,14 @@ package small_program
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"program-collection/i18n"
 	"program-collection/models"
-
-	"gorm.io/gorm"
 )
 
 type PyramidAlphas struct {
@@ -32,7 +32,8 @@ func (PyramidAlphas) TableName() string {
 	return "pyramid_alphas"
 }
 
-func PyramidAlphaInfo(config models.Config, token string) error {
+func PyramidAlphaInfo(deps Deps) error {
+	ctx := context.Background()
 	// 创建控制台读取器
 	reader := bufio.NewReader(os.Stdin)
 
@@ -45,7 +46,7 @@ func PyramidAlphaInfo(config models.Config, token string) error {
 	// 2. 根据季度计算日期范围
 	startDate, endDate, err := calculateQuarterDates(quarter)
 	if err != nil {
-		return fmt.Errorf("季度格式错误: %v", err)
+		return i18n.Errorf("pyramid.bad_quarter", err)
 	}
 
 	// 3. 交互式获取用户ID
@@ -56,62 +57,93 @@ func PyramidAlphaInfo(config models.Config, token string) error {
 
 	// 4. 显示确认信息
 	fmt.Println("\n" + strings.Repeat("=", 50))
-	fmt.Println("请确认以下信息:")
-	fmt.Printf("季度: %s\n", quarter)
-	fmt.Printf("日期范围: %s 至 %s\n", startDate, endDate)
-	fmt.Printf("用户ID: %s\n", userID)
+	fmt.Println(i18n.T("pyramid.confirm_header"))
+	i18n.Printf("pyramid.quarter", quarter)
+	i18n.Printf("pyramid.date_range", startDate, endDate)
+	i18n.Printf("pyramid.user", userID)
 	fmt.Println(strings.Repeat("=", 50))
 
 	// 5. 确认是否继续
-	if !confirmAction(reader, "是否继续执行数据获取和保存？") {
-		fmt.Println("操作已取消")
+	if !confirmAction(reader, i18n.T("pyramid.confirm_fetch")) {
+		fmt.Println(i18n.T("common.cancelled"))
 		return nil
 	}
 
-	fmt.Printf("\n正在获取 %s 的数据...\n", quarter)
+	i18n.Printf("pyramid.fetching", quarter)
 
 	// 6. 调用API获取数据
-	pyramids, err := PyramidInfo(config, token, startDate, endDate)
+	pyramids, err := PyramidInfo(deps.Config, deps.Token, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("获取金字塔数据失败: %v", err)
+		return i18n.Errorf("pyramid.fetch_failed", err)
 	}
 
-	// 7. 连接数据库
-	db, err := ConnectDB(config)
-	if err != nil {
-		return fmt.Errorf("数据库连接失败: %v", err)
-	}
-	defer func() {
-		sqlDB, _ := db.DB()
-		sqlDB.Close()
-	}()
-
-	// 8. 检查是否已存在该季度数据
+	// 7. 检查是否已存在该季度数据
 	if shouldCheckExistingData(reader, quarter, userID) {
-		exists, err := checkExistingQuarterData(db, quarter, userID)
+		exists, err := checkExistingQuarterData(ctx, deps.Pyramids, quarter, userID)
 		if err != nil {
-			return fmt.Errorf("检查已有数据失败: %v", err)
+			return i18n.Errorf("pyramid.check_failed", err)
 		}
 
 		if exists {
-			if !confirmAction(reader, "该季度数据已存在，是否更新？") {
-				fmt.Println("操作已取消")
+			if !confirmAction(reader, i18n.T("pyramid.confirm_update")) {
+				fmt.Println(i18n.T("common.cancelled"))
 				return nil
 			}
 
 			// 删除现有数据
-			if err := deleteExistingQuarterData(db, quarter, userID); err != nil {
-				return fmt.Errorf("删除现有数据失败: %v", err)
+			if err := deleteExistingQuarterData(ctx, deps.Pyramids, quarter, userID); err != nil {
+				return i18n.Errorf("pyramid.delete_failed", err)
 			}
-			fmt.Println("已删除现有数据，准备重新插入...")
+			fmt.Println(i18n.T("pyramid.deleted"))
 		}
 	}
 
-	// 9. 解析日期范围
+	// 8. 转换为数据库模型并批量插入
+	records := buildPyramidRecords(pyramids, quarter, userID, startDate, endDate)
+	if err := deps.Pyramids.Insert(ctx, records); err != nil {
+		return i18n.Errorf("pyramid.insert_failed", err)
+	}
+
+	i18n.Printf("pyramid.saved", len(records))
+	i18n.Printf("pyramid.saved_quarter", quarter)
+	i18n.Printf("pyramid.saved_user", userID)
+	i18n.Printf("pyramid.saved_range", startDate, endDate)
+
+	return nil
+}
+
+// SnapshotPyramidAlphas 非交互式地拉取指定季度的金字塔数据，并替换数据库中该用户该季度的已有记录
+func SnapshotPyramidAlphas(ctx context.Context, deps Deps, quarter, userID string) ([]PyramidAlphas, error) {
+	if !isValidQuarterFormat(quarter) {
+		return nil, i18n.Errorf("pyramid.bad_quarter_value", quarter)
+	}
+	if userID == "" {
+		return nil, i18n.Errorf("pyramid.user_required")
+	}
+
+	startDate, endDate, err := calculateQuarterDates(quarter)
+	if err != nil {
+		return nil, i18n.Errorf("pyramid.bad_quarter", err)
+	}
+
+	pyramids, err := PyramidInfo(deps.Config, deps.Token, startDate, endDate)
+	if err != nil {
+		return nil, i18n.Errorf("pyramid.fetch_failed", err)
+	}
+
+	records := buildPyramidRecords(pyramids, quarter, userID, startDate, endDate)
+	if err := deps.Pyramids.Replace(ctx, userID, quarter, records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// 将接口返回的金字塔数据转换为数据库模型
+func buildPyramidRecords(pyramids []models.Pyramids, quarter, userID, startDate, endDate string) []PyramidAlphas {
 	statStart, _ := time.Parse("2006-01-02", startDate)
 	statEnd, _ := time.Parse("2006-01-02", endDate)
 
-	// 10. 遍历数据并转换为数据库模型
 	var records []PyramidAlphas
 	for _, p := range pyramids {
 		record := PyramidAlphas{
@@ -127,32 +159,18 @@ func PyramidAlphaInfo(config models.Config, token string) error {
 		}
 		records = append(records, record)
 	}
-
-	// 11. 批量插入数据库
-	tx := db.Begin()
-	if err := tx.CreateInBatches(&records, 100).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("批量插入数据失败: %v", err)
-	}
-	tx.Commit()
-
-	fmt.Printf("\n✅ 成功保存 %d 条金字塔Alpha记录\n", len(records))
-	fmt.Printf("   季度: %s\n", quarter)
-	fmt.Printf("   用户: %s\n", userID)
-	fmt.Printf("   时间: %s 至 %s\n", startDate, endDate)
-
-	return nil
+	return records
 }
 
 // 获取季度输入的辅助函数
 func getQuarterInput(reader *bufio.Reader) (string, error) {
 	for {
-		fmt.Println("\n请输入季度（格式: 年份-QN，例如: 2025-Q3）:")
+		fmt.Println(i18n.T("pyramid.quarter_prompt"))
 		fmt.Print("> ")
 
 		input, err := reader.ReadString('\n')
 		if err != nil {
-			return "", fmt.Errorf("读取输入失败: %v", err)
+			return "", i18n.Errorf("common.read_input_error", err)
 		}
 
 		// 清理输入
@@ -163,12 +181,17 @@ func getQuarterInput(reader *bufio.Reader) (string, error) {
 			return input, nil
 		}
 
-		fmt.Printf("❌ 格式错误: %s\n", input)
-		fmt.Println("正确格式示例: 2025-Q3, 2024-Q1, 2023-Q4")
-		fmt.Println("Q1: 1-3月, Q2: 4-6月, Q3: 7-9月, Q4: 10-12月")
+		i18n.Printf("pyramid.bad_format", input)
+		fmt.Println(i18n.T("pyramid.format_examples"))
+		fmt.Println(i18n.T("pyramid.quarter_months"))
 	}
 }
 
+// CurrentQuarter 返回给定时间所在的季度，格式: YYYY-QN
+func CurrentQuarter(now time.Time) string {
+	return fmt.Sprintf("%d-Q%d", now.Year(), (int(now.Month())-1)/3+1)
+}
+
 // 验证季度格式
 func isValidQuarterFormat(input string) bool {
 	if len(input) != 7 && len(input) != 6 {
@@ -209,7 +232,7 @@ func isValidQuarterFormat(input string) bool {
 func calculateQuarterDates(quarter string) (startDate, endDate string, err error) {
 	parts := strings.Split(quarter, "-")
 	if len(parts) != 2 {
-		return "", "", fmt.Errorf("无效的季度格式")
+		return "", "", i18n.Errorf("pyramid.invalid_quarter")
 	}
 
 	year := parts[0]
@@ -227,7 +250,7 @@ func calculateQuarterDates(quarter string) (startDate, endDate string, err error
 	case 4: // Q4: 10月-12月
 		startMonth, endMonth = 10, 1
 	default:
-		return "", "", fmt.Errorf("季度数字必须在1-4之间")
+		return "", "", i18n.Errorf("pyramid.quarter_range")
 	}
 
 	// 计算开始日期
@@ -249,24 +272,24 @@ func calculateQuarterDates(quarter string) (startDate, endDate string, err error
 // 获取用户ID输入
 func getUserIDInput(reader *bufio.Reader) (string, error) {
 	for {
-		fmt.Println("\n请输入用户ID:")
+		fmt.Println(i18n.T("pyramid.user_prompt"))
 		fmt.Print("> ")
 
 		input, err := reader.ReadString('\n')
 		if err != nil {
-			return "", fmt.Errorf("读取输入失败: %v", err)
+			return "", i18n.Errorf("common.read_input_error", err)
 		}
 
 		input = strings.TrimSpace(input)
 
 		if input == "" {
-			fmt.Println("❌ 用户ID不能为空")
+			fmt.Println(i18n.T("pyramid.user_empty"))
 			continue
 		}
 
 		// 确认用户ID
-		fmt.Printf("您输入的用户ID是: %s\n", input)
-		if confirmAction(reader, "是否确认？") {
+		i18n.Printf("pyramid.user_entered", input)
+		if confirmAction(reader, i18n.T("pyramid.confirm")) {
 			return input, nil
 		}
 	}
@@ -279,52 +302,46 @@ func confirmAction(reader *bufio.Reader, prompt string) bool {
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(strings.ToLower(input))
 
-		switch input {
-		case "y", "yes", "是", "确认":
+		switch {
+		case i18n.IsYes(input):
 			return true
-		case "n", "no", "否", "取消":
+		case i18n.IsNo(input):
 			return false
 		default:
-			fmt.Println("请输入 y/是 或 n/否")
+			fmt.Println(i18n.T("common.yes_no"))
 		}
 	}
 }
 
 // 检查是否应该检查已有数据
 func shouldCheckExistingData(reader *bufio.Reader, quarter, userID string) bool {
-	fmt.Printf("\n是否检查 %s 用户 %s 季度的现有数据？\n", userID, quarter)
-	return confirmAction(reader, "检查并提示是否更新？")
+	i18n.Printf("pyramid.check_prompt", userID, quarter)
+	return confirmAction(reader, i18n.T("pyramid.check_confirm"))
 }
 
 // 检查数据库中是否已存在该季度的数据
-func checkExistingQuarterData(db *gorm.DB, quarter, userID string) (bool, error) {
-	var count int64
-	err := db.Model(&PyramidAlphas{}).
-		Where("user_id = ? AND quarter_tag = ?", userID, quarter).
-		Count(&count).Error
-
+func checkExistingQuarterData(ctx context.Context, repo PyramidRepo, quarter, userID string) (bool, error) {
+	count, err := repo.Count(ctx, userID, quarter)
 	if err != nil {
 		return false, err
 	}
 
 	if count > 0 {
-		fmt.Printf("⚠️  数据库中年发现 %d 条已存在的 %s 季度数据\n", count, quarter)
+		i18n.Printf("pyramid.existing", count, quarter)
 		return true, nil
 	}
 
-	fmt.Printf("✅ 数据库中未找到 %s 季度的现有数据\n", quarter)
+	i18n.Printf("pyramid.no_existing", quarter)
 	return false, nil
 }
 
 // 删除现有季度数据
-func deleteExistingQuarterData(db *gorm.DB, quarter, userID string) error {
-	result := db.Where("user_id = ? AND quarter_tag = ?", userID, quarter).
-		Delete(&PyramidAlphas{})
-
-	if result.Error != nil {
-		return result.Error
+func deleteExistingQuarterData(ctx context.Context, repo PyramidRepo, quarter, userID string) error {
+	deleted, err := repo.Delete(ctx, userID, quarter)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("已删除 %d 条现有记录\n", result.RowsAffected)
+	i18n.Printf("pyramid.deleted_count", deleted)
 	return nil
 }
@@ -0,0 +1,161 @@
+package small_program
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"program-collection/models"
+)
+
+// ------------------------------------------------ 数据访问接口 -----------------------------------------------
+// 各程序只通过以下接口读写数据库，gorm 实现见 repository_gorm.go，内存实现见 repository_memory.go
+
+// ErrNotFound 查询的记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// AlphaFilter alpha 列表查询条件，切片为空或 Delay 为 nil 时不按该列过滤，Limit 为 0 时不限数量
+type AlphaFilter struct {
+	IDs       []string
+	Types     []string
+	Statuses  []string
+	Stages    []string
+	Regions   []string
+	Universes []string
+	Authors   []string
+	Delay     *int
+	Limit     int
+	Offset    int
+}
+
+// AlphaStatusCount 按状态和地区统计的 alpha 数量
+type AlphaStatusCount struct {
+	Status string
+	Region string
+	Count  int64
+}
+
+// AlphaRepo active_alpha_list 表
+type AlphaRepo interface {
+	// IDs 返回全部 alpha ID
+	IDs(ctx context.Context) ([]string, error)
+	// Count 返回 alpha 总数
+	Count(ctx context.Context) (int64, error)
+	// LatestSubmitted 返回最大的提交时间，alphaType 为空时不限类型；没有数据时 ok 为 false
+	LatestSubmitted(ctx context.Context, alphaType string) (latest time.Time, ok bool, err error)
+	// InsertIgnore 批量插入，已存在的 ID 跳过，返回实际插入的数量
+	InsertIgnore(ctx context.Context, alphas []ActiveAlphaList) (int, error)
+	// CreateIfMissing 插入单条记录，ID 已存在时不做修改，返回是否插入
+	CreateIfMissing(ctx context.Context, alpha ActiveAlphaList) (bool, error)
+	// Update 用非零字段更新已有记录（不存在时不创建），返回是否有记录被更新
+	Update(ctx context.Context, alpha ActiveAlphaList) (bool, error)
+	// Get 按 ID 查询，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*ActiveAlphaList, error)
+	// List 按条件分页查询，按提交时间倒序，同时返回符合条件的总数
+	List(ctx context.Context, filter AlphaFilter) ([]ActiveAlphaList, int64, error)
+	// CountByStatusRegion 按状态和地区统计数量
+	CountByStatusRegion(ctx context.Context) ([]AlphaStatusCount, error)
+	// ByCanonicalHash 返回规范化表达式哈希相同的 alpha，按提交时间倒序
+	ByCanonicalHash(ctx context.Context, hash string) ([]ActiveAlphaList, error)
+}
+
+// FactorRepo weight_value_factor 表
+type FactorRepo interface {
+	// Count 返回用户的记录数
+	Count(ctx context.Context, userID string) (int64, error)
+	// Latest 返回最新的一条记录，userID 为空时不限用户；没有记录时返回 ErrNotFound
+	Latest(ctx context.Context, userID string) (*WeightValueFactor, error)
+	// Create 插入一条记录
+	Create(ctx context.Context, stat *WeightValueFactor) error
+	// Upsert 插入一条记录，同一用户同一天已有记录时更新
+	Upsert(ctx context.Context, stat *WeightValueFactor) error
+	// UserIDs 返回有记录的全部用户
+	UserIDs(ctx context.Context) ([]string, error)
+}
+
+// PyramidRepo pyramid_alphas 表
+type PyramidRepo interface {
+	// Count 返回用户某季度的记录数
+	Count(ctx context.Context, userID, quarter string) (int64, error)
+	// Delete 删除用户某季度的记录，返回删除的数量
+	Delete(ctx context.Context, userID, quarter string) (int64, error)
+	// Insert 批量插入
+	Insert(ctx context.Context, records []PyramidAlphas) error
+	// Replace 在一个事务中用 records 替换用户某季度的记录
+	Replace(ctx context.Context, userID, quarter string, records []PyramidAlphas) error
+	// List 查询记录，userID/quarter 为空时不过滤，按季度、alpha 数量倒序
+	List(ctx context.Context, userID, quarter string) ([]PyramidAlphas, error)
+}
+
+// OperatorRepo operators 表
+type OperatorRepo interface {
+	// Count 返回操作符总数
+	Count(ctx context.Context) (int64, error)
+	// Clear 清空表，返回删除的数量
+	Clear(ctx context.Context) (int64, error)
+	// Insert 批量插入，返回插入的数量
+	Insert(ctx context.Context, operators []Operators) (int64, error)
+	// Replace 在一个事务中用 operators 替换同一 Genius 等级和季度的记录
+	Replace(ctx context.Context, geniusLevel, geniusQuarter string, operators []Operators) error
+	// List 返回全部记录，按 ID 升序
+	List(ctx context.Context) ([]Operators, error)
+}
+
+// SnapshotRepo alpha_snapshots 表
+type SnapshotRepo interface {
+	// Record 写入快照，同一 alpha 同一天已有快照时覆盖
+	Record(ctx context.Context, snapshots []AlphaSnapshot) error
+	// History 返回一个 alpha 的全部快照，按日期升序
+	History(ctx context.Context, alphaID string) ([]AlphaSnapshot, error)
+	// AsOf 返回每个 alpha 在 date 当天或之前最近的一次快照
+	AsOf(ctx context.Context, date time.Time) ([]AlphaSnapshot, error)
+}
+
+// ChangeRepo alpha_changes 表
+type ChangeRepo interface {
+	// Record 批量写入字段变化
+	Record(ctx context.Context, changes []AlphaChange) error
+	// ForAlpha 返回一个 alpha 的全部字段变化，按发现时间升序
+	ForAlpha(ctx context.Context, alphaID string) ([]AlphaChange, error)
+}
+
+// SyncStateRepo sync_state 表
+type SyncStateRepo interface {
+	// Watermark 返回某种同步已处理到的时间；没有记录时 ok 为 false
+	Watermark(ctx context.Context, name string) (watermark time.Time, ok bool, err error)
+	// SetWatermark 保存某种同步已处理到的时间
+	SetWatermark(ctx context.Context, name string, watermark time.Time) error
+}
+
+// AlphaFieldRepo alpha_fields 表
+type AlphaFieldRepo interface {
+	// Replace 在一个事务中删除 alphaIDs 的全部记录后写入 fields
+	Replace(ctx context.Context, alphaIDs []string, fields []AlphaField) error
+	// ByFields 返回使用了 fields 中任一字段的记录，按 alpha ID、字段排序
+	ByFields(ctx context.Context, fields []string) ([]AlphaField, error)
+}
+
+// Repos 全部数据访问接口
+type Repos struct {
+	Alphas      AlphaRepo
+	Factors     FactorRepo
+	Pyramids    PyramidRepo
+	Operators   OperatorRepo
+	Snapshots   SnapshotRepo
+	Changes     ChangeRepo
+	SyncState   SyncStateRepo
+	AlphaFields AlphaFieldRepo
+
+	// Ping 检查存储是否可用，为 nil 时视为可用
+	Ping func(ctx context.Context) error
+}
+
+// Deps 各程序共用的依赖，由 main 创建一次后传给各个程序
+type Deps struct {
+	Config models.Config
+	Token  string
+	Repos
+
+	// SignIn 重新登录 BRAIN 并返回新的 token，长时间运行的 API 服务用它替换过期的会话；为空时始终使用 Token
+	SignIn func() (string, error)
+}
@@ -0,0 +1,443 @@
+package small_program
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"program-collection/i18n"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ------------------------------------------------ 数据访问接口的 gorm 实现 -----------------------------------------------
+
+// NewGormRepos 基于同一个连接池创建全部数据访问接口，连接池由调用方负责关闭
+func NewGormRepos(db *gorm.DB) Repos {
+	return Repos{
+		Alphas:      &gormAlphaRepo{db: db},
+		Factors:     &gormFactorRepo{db: db},
+		Pyramids:    &gormPyramidRepo{db: db},
+		Operators:   &gormOperatorRepo{db: db},
+		Snapshots:   &gormSnapshotRepo{db: db},
+		Changes:     &gormChangeRepo{db: db},
+		SyncState:   &gormSyncStateRepo{db: db},
+		AlphaFields: &gormAlphaFieldRepo{db: db},
+		Ping: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// 把 gorm 的未找到错误统一为 ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ---- alpha ----
+
+type gormAlphaRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormAlphaRepo) IDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&ActiveAlphaList{}).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *gormAlphaRepo) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&ActiveAlphaList{}).Count(&count).Error
+	return count, err
+}
+
+// MySQL 返回 time.Time，SQLite 返回文本，统一由 parseDBTime 解析
+func (r *gormAlphaRepo) LatestSubmitted(ctx context.Context, alphaType string) (time.Time, bool, error) {
+	query := r.db.WithContext(ctx).Model(&ActiveAlphaList{})
+	if alphaType != "" {
+		query = query.Where("type = ?", alphaType)
+	}
+
+	var maxDate any
+	if err := query.Select("MAX(date_submitted)").Row().Scan(&maxDate); err != nil {
+		return time.Time{}, false, err
+	}
+	if maxDate == nil {
+		return time.Time{}, false, nil
+	}
+	latest, ok := parseDBTime(maxDate)
+	if !ok {
+		return time.Time{}, false, i18n.Errorf("alpha.fetch.parse_failed", maxDate)
+	}
+	return latest, true, nil
+}
+
+func (r *gormAlphaRepo) InsertIgnore(ctx context.Context, alphas []ActiveAlphaList) (int, error) {
+	if len(alphas) == 0 {
+		return 0, nil
+	}
+
+	// 冲突时跳过，MySQL 中相当于 INSERT IGNORE
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(alphas, 100)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (r *gormAlphaRepo) CreateIfMissing(ctx context.Context, alpha ActiveAlphaList) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", alpha.ID).FirstOrCreate(&alpha)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormAlphaRepo) Update(ctx context.Context, alpha ActiveAlphaList) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&ActiveAlphaList{}).Where("id = ?", alpha.ID).Updates(alpha)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormAlphaRepo) Get(ctx context.Context, id string) (*ActiveAlphaList, error) {
+	var alpha ActiveAlphaList
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&alpha).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &alpha, nil
+}
+
+func (r *gormAlphaRepo) List(ctx context.Context, filter AlphaFilter) ([]ActiveAlphaList, int64, error) {
+	query := r.db.WithContext(ctx).Model(&ActiveAlphaList{})
+	for _, in := range []struct {
+		column string
+		values []string
+	}{
+		{"id", filter.IDs},
+		{"type", filter.Types},
+		{"status", filter.Statuses},
+		{"stage", filter.Stages},
+		{"region", filter.Regions},
+		{"universe", filter.Universes},
+		{"author", filter.Authors},
+	} {
+		if len(in.values) > 0 {
+			query = query.Where(in.column+" IN ?", in.values)
+		}
+	}
+	if filter.Delay != nil {
+		query = query.Where("delay = ?", *filter.Delay)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("date_submitted DESC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var alphas []ActiveAlphaList
+	err := query.Find(&alphas).Error
+	return alphas, total, err
+}
+
+func (r *gormAlphaRepo) CountByStatusRegion(ctx context.Context) ([]AlphaStatusCount, error) {
+	var rows []struct {
+		Status string
+		Region *string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&ActiveAlphaList{}).
+		Select("status, region, COUNT(*) AS count").
+		Group("status, region").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]AlphaStatusCount, len(rows))
+	for i, row := range rows {
+		counts[i] = AlphaStatusCount{Status: row.Status, Count: row.Count}
+		if row.Region != nil {
+			counts[i].Region = *row.Region
+		}
+	}
+	return counts, nil
+}
+
+func (r *gormAlphaRepo) ByCanonicalHash(ctx context.Context, hash string) ([]ActiveAlphaList, error) {
+	var alphas []ActiveAlphaList
+	err := r.db.WithContext(ctx).Where("canonical_hash = ?", hash).Order("date_submitted DESC").Find(&alphas).Error
+	return alphas, err
+}
+
+// ---- wf/vf ----
+
+type gormFactorRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormFactorRepo) Count(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&WeightValueFactor{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormFactorRepo) Latest(ctx context.Context, userID string) (*WeightValueFactor, error) {
+	query := r.db.WithContext(ctx).Model(&WeightValueFactor{})
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var latest WeightValueFactor
+	if err := query.Order("stat_date DESC").Order("create_time DESC").First(&latest).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &latest, nil
+}
+
+func (r *gormFactorRepo) Create(ctx context.Context, stat *WeightValueFactor) error {
+	return r.db.WithContext(ctx).Create(stat).Error
+}
+
+func (r *gormFactorRepo) Upsert(ctx context.Context, stat *WeightValueFactor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "stat_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"weight_factor", "value_factor", "weight_factor_change", "value_factor_change",
+			"weight_factor_change_rate", "value_factor_change_rate", "data_fields_used",
+			"submissions_count", "super_alpha_submissions_count", "mean_prod_correlation",
+			"mean_self_correlation", "super_alpha_mean_prod_correlation",
+			"super_alpha_mean_self_correlation", "university", "country", "update_time",
+		}),
+	}).Create(stat).Error
+}
+
+func (r *gormFactorRepo) UserIDs(ctx context.Context) ([]string, error) {
+	var userIDs []string
+	err := r.db.WithContext(ctx).Model(&WeightValueFactor{}).Distinct("user_id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// ---- 金字塔 ----
+
+type gormPyramidRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormPyramidRepo) Count(ctx context.Context, userID, quarter string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&PyramidAlphas{}).
+		Where("user_id = ? AND quarter_tag = ?", userID, quarter).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormPyramidRepo) Delete(ctx context.Context, userID, quarter string) (int64, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND quarter_tag = ?", userID, quarter).Delete(&PyramidAlphas{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormPyramidRepo) Insert(ctx context.Context, records []PyramidAlphas) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&records, 100).Error
+	})
+}
+
+func (r *gormPyramidRepo) Replace(ctx context.Context, userID, quarter string, records []PyramidAlphas) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND quarter_tag = ?", userID, quarter).Delete(&PyramidAlphas{}).Error; err != nil {
+			return i18n.Errorf("pyramid.delete_failed", err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(&records, 100).Error; err != nil {
+			return i18n.Errorf("pyramid.insert_failed", err)
+		}
+		return nil
+	})
+}
+
+func (r *gormPyramidRepo) List(ctx context.Context, userID, quarter string) ([]PyramidAlphas, error) {
+	query := r.db.WithContext(ctx).Model(&PyramidAlphas{})
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if quarter != "" {
+		query = query.Where("quarter_tag = ?", quarter)
+	}
+
+	var records []PyramidAlphas
+	err := query.Order("quarter_tag DESC").Order("alpha_count DESC").Find(&records).Error
+	return records, err
+}
+
+// ---- 操作符 ----
+
+type gormOperatorRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormOperatorRepo) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Operators{}).Count(&count).Error
+	return count, err
+}
+
+func (r *gormOperatorRepo) Clear(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM operators")
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormOperatorRepo) Insert(ctx context.Context, operators []Operators) (int64, error) {
+	if len(operators) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).CreateInBatches(operators, 100)
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormOperatorRepo) List(ctx context.Context) ([]Operators, error) {
+	var operators []Operators
+	err := r.db.WithContext(ctx).Order("id").Find(&operators).Error
+	return operators, err
+}
+
+func (r *gormOperatorRepo) Replace(ctx context.Context, geniusLevel, geniusQuarter string, operators []Operators) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("genius_level = ? AND genius_quarter = ?", geniusLevel, geniusQuarter).Delete(&Operators{})
+		if result.Error != nil {
+			return i18n.Errorf("operators.clear_failed", result.Error)
+		}
+		if len(operators) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(operators, 100).Error; err != nil {
+			return i18n.Errorf("operators.insert_failed", err)
+		}
+		return nil
+	})
+}
+
+// ---- alpha 快照 ----
+
+type gormSnapshotRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormSnapshotRepo) Record(ctx context.Context, snapshots []AlphaSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "alpha_id"}, {Name: "snapshot_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"status", "stage", "is_sharpe", "is_fitness", "is_returns", "is_turnover", "is_drawdown",
+			"is_self_correlation", "is_prod_correlation", "os_is_sharpe_ratio", "os_pre_close_sharpe_ratio",
+			"pyramid_themes", "snapshot_time",
+		}),
+	}).CreateInBatches(snapshots, 100).Error
+}
+
+func (r *gormSnapshotRepo) History(ctx context.Context, alphaID string) ([]AlphaSnapshot, error) {
+	var snapshots []AlphaSnapshot
+	err := r.db.WithContext(ctx).Where("alpha_id = ?", alphaID).Order("snapshot_date").Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (r *gormSnapshotRepo) AsOf(ctx context.Context, date time.Time) ([]AlphaSnapshot, error) {
+	latest := r.db.Model(&AlphaSnapshot{}).
+		Select("alpha_id, MAX(snapshot_date) AS snapshot_date").
+		Where("snapshot_date <= ?", date).
+		Group("alpha_id")
+
+	var snapshots []AlphaSnapshot
+	err := r.db.WithContext(ctx).Model(&AlphaSnapshot{}).
+		Joins("JOIN (?) latest ON latest.alpha_id = alpha_snapshots.alpha_id AND latest.snapshot_date = alpha_snapshots.snapshot_date", latest).
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// ---- alpha 字段变化 ----
+
+type gormChangeRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormChangeRepo) Record(ctx context.Context, changes []AlphaChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(changes, 100).Error
+}
+
+func (r *gormChangeRepo) ForAlpha(ctx context.Context, alphaID string) ([]AlphaChange, error) {
+	var changes []AlphaChange
+	err := r.db.WithContext(ctx).Where("alpha_id = ?", alphaID).Order("detected_at").Order("id").Find(&changes).Error
+	return changes, err
+}
+
+// ---- 同步水位 ----
+
+type gormSyncStateRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormSyncStateRepo) Watermark(ctx context.Context, name string) (time.Time, bool, error) {
+	var state SyncState
+	err := r.db.WithContext(ctx).Where("name = ?", name).Limit(1).Find(&state).Error
+	if err != nil || state.Watermark == nil {
+		return time.Time{}, false, err
+	}
+	return *state.Watermark, true, nil
+}
+
+func (r *gormSyncStateRepo) SetWatermark(ctx context.Context, name string, watermark time.Time) error {
+	state := SyncState{Name: name, Watermark: &watermark, UpdatedAt: time.Now()}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"watermark", "updated_at"}),
+	}).Create(&state).Error
+}
+
+// ---- alpha 字段索引 ----
+
+type gormAlphaFieldRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormAlphaFieldRepo) Replace(ctx context.Context, alphaIDs []string, fields []AlphaField) error {
+	if len(alphaIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("alpha_id IN ?", alphaIDs).Delete(&AlphaField{}).Error; err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(fields, 100).Error
+	})
+}
+
+func (r *gormAlphaFieldRepo) ByFields(ctx context.Context, fields []string) ([]AlphaField, error) {
+	var rows []AlphaField
+	if len(fields) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).Where("field IN ?", fields).Order("alpha_id").Order("field").Order("id").Find(&rows).Error
+	return rows, err
+}
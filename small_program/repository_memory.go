@@ -0,0 +1,632 @@
+package small_program
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ------------------------------------------------ 数据访问接口的内存实现 -----------------------------------------------
+// 用于单元测试和不连接数据库的场景，行为与 gorm 实现保持一致，数据只保存在进程内
+
+// NewMemoryRepos 创建一组空的内存数据访问接口
+func NewMemoryRepos() Repos {
+	return Repos{
+		Alphas:      &MemoryAlphaRepo{alphas: map[string]ActiveAlphaList{}},
+		Factors:     &MemoryFactorRepo{},
+		Pyramids:    &MemoryPyramidRepo{},
+		Operators:   &MemoryOperatorRepo{},
+		Snapshots:   &MemorySnapshotRepo{},
+		Changes:     &MemoryChangeRepo{},
+		SyncState:   &MemorySyncStateRepo{},
+		AlphaFields: &MemoryAlphaFieldRepo{},
+	}
+}
+
+// ---- alpha ----
+
+// MemoryAlphaRepo 内存中的 AlphaRepo
+type MemoryAlphaRepo struct {
+	mu     sync.Mutex
+	alphas map[string]ActiveAlphaList
+}
+
+func (r *MemoryAlphaRepo) IDs(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(r.alphas))
+	for id := range r.alphas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (r *MemoryAlphaRepo) Count(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.alphas)), nil
+}
+
+func (r *MemoryAlphaRepo) LatestSubmitted(ctx context.Context, alphaType string) (time.Time, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var latest time.Time
+	found := false
+	for _, alpha := range r.alphas {
+		if alphaType != "" && alpha.Type != alphaType {
+			continue
+		}
+		submitted, ok := submittedAt(alpha)
+		if ok && (!found || submitted.After(latest)) {
+			latest, found = submitted, true
+		}
+	}
+	return latest, found, nil
+}
+
+func (r *MemoryAlphaRepo) InsertIgnore(ctx context.Context, alphas []ActiveAlphaList) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inserted := 0
+	for _, alpha := range alphas {
+		if _, exists := r.alphas[alpha.ID]; !exists {
+			r.alphas[alpha.ID] = alpha
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+func (r *MemoryAlphaRepo) CreateIfMissing(ctx context.Context, alpha ActiveAlphaList) (bool, error) {
+	inserted, err := r.InsertIgnore(ctx, []ActiveAlphaList{alpha})
+	return inserted > 0, err
+}
+
+func (r *MemoryAlphaRepo) Update(ctx context.Context, alpha ActiveAlphaList) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.alphas[alpha.ID]
+	if !ok {
+		return false, nil
+	}
+	mergeNonZero(&existing, alpha)
+	r.alphas[alpha.ID] = existing
+	return true, nil
+}
+
+func (r *MemoryAlphaRepo) Get(ctx context.Context, id string) (*ActiveAlphaList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alpha, ok := r.alphas[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &alpha, nil
+}
+
+func (r *MemoryAlphaRepo) List(ctx context.Context, filter AlphaFilter) ([]ActiveAlphaList, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	in := func(values []string, value *string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		return value != nil && slices.Contains(values, *value)
+	}
+
+	var matched []ActiveAlphaList
+	for _, alpha := range r.alphas {
+		if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, alpha.ID) ||
+			len(filter.Types) > 0 && !slices.Contains(filter.Types, alpha.Type) ||
+			len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, alpha.Status) ||
+			len(filter.Stages) > 0 && !slices.Contains(filter.Stages, alpha.Stage) ||
+			len(filter.Authors) > 0 && !slices.Contains(filter.Authors, alpha.Author) ||
+			!in(filter.Regions, alpha.Region) || !in(filter.Universes, alpha.Universe) {
+			continue
+		}
+		if filter.Delay != nil && (alpha.Delay == nil || *alpha.Delay != *filter.Delay) {
+			continue
+		}
+		matched = append(matched, alpha)
+	}
+
+	// 按提交时间倒序，与 ORDER BY date_submitted DESC 一致
+	sort.SliceStable(matched, func(i, j int) bool {
+		ti, _ := submittedAt(matched[i])
+		tj, _ := submittedAt(matched[j])
+		return ti.After(tj)
+	})
+
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return nil, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+func (r *MemoryAlphaRepo) ByCanonicalHash(ctx context.Context, hash string) ([]ActiveAlphaList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []ActiveAlphaList
+	for _, alpha := range r.alphas {
+		if alpha.CanonicalHash != nil && *alpha.CanonicalHash == hash {
+			matched = append(matched, alpha)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		ti, _ := submittedAt(matched[i])
+		tj, _ := submittedAt(matched[j])
+		return ti.After(tj)
+	})
+	return matched, nil
+}
+
+func (r *MemoryAlphaRepo) CountByStatusRegion(ctx context.Context) ([]AlphaStatusCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type key struct{ status, region string }
+	counts := map[key]int64{}
+	for _, alpha := range r.alphas {
+		k := key{status: alpha.Status}
+		if alpha.Region != nil {
+			k.region = *alpha.Region
+		}
+		counts[k]++
+	}
+
+	result := make([]AlphaStatusCount, 0, len(counts))
+	for k, count := range counts {
+		result = append(result, AlphaStatusCount{Status: k.status, Region: k.region, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Status != result[j].Status {
+			return result[i].Status < result[j].Status
+		}
+		return result[i].Region < result[j].Region
+	})
+	return result, nil
+}
+
+func submittedAt(alpha ActiveAlphaList) (time.Time, bool) {
+	if alpha.DateSubmitted == nil {
+		return time.Time{}, false
+	}
+	return parseDBTime(*alpha.DateSubmitted)
+}
+
+// 与 gorm 的 Updates(struct) 相同，只复制 src 中的非零字段
+func mergeNonZero(dst *ActiveAlphaList, src ActiveAlphaList) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src)
+	for i := 0; i < sv.NumField(); i++ {
+		if !sv.Field(i).IsZero() {
+			dv.Field(i).Set(sv.Field(i))
+		}
+	}
+}
+
+// ---- wf/vf ----
+
+// MemoryFactorRepo 内存中的 FactorRepo
+type MemoryFactorRepo struct {
+	mu     sync.Mutex
+	nextID int
+	stats  []WeightValueFactor
+}
+
+func (r *MemoryFactorRepo) Count(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, stat := range r.stats {
+		if stat.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryFactorRepo) Latest(ctx context.Context, userID string) (*WeightValueFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var latest *WeightValueFactor
+	for i := range r.stats {
+		stat := &r.stats[i]
+		if userID != "" && stat.UserID != userID {
+			continue
+		}
+		if latest == nil || stat.StatDate.After(latest.StatDate) ||
+			stat.StatDate.Equal(latest.StatDate) && createdAfter(stat.CreateTime, latest.CreateTime) {
+			latest = stat
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	result := *latest
+	return &result, nil
+}
+
+func (r *MemoryFactorRepo) Create(ctx context.Context, stat *WeightValueFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insert(stat)
+	return nil
+}
+
+func (r *MemoryFactorRepo) Upsert(ctx context.Context, stat *WeightValueFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.stats {
+		if existing.UserID == stat.UserID && existing.StatDate.Equal(stat.StatDate) {
+			now := time.Now()
+			stat.ID, stat.CreateTime, stat.UpdateTime = existing.ID, existing.CreateTime, &now
+			r.stats[i] = *stat
+			return nil
+		}
+	}
+	r.insert(stat)
+	return nil
+}
+
+func (r *MemoryFactorRepo) UserIDs(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var userIDs []string
+	for _, stat := range r.stats {
+		if !slices.Contains(userIDs, stat.UserID) {
+			userIDs = append(userIDs, stat.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// 调用方需持有锁
+func (r *MemoryFactorRepo) insert(stat *WeightValueFactor) {
+	r.nextID++
+	stat.ID = r.nextID
+	if stat.CreateTime == nil {
+		now := time.Now()
+		stat.CreateTime, stat.UpdateTime = &now, &now
+	}
+	r.stats = append(r.stats, *stat)
+}
+
+func createdAfter(a, b *time.Time) bool {
+	return a != nil && (b == nil || a.After(*b))
+}
+
+// ---- 金字塔 ----
+
+// MemoryPyramidRepo 内存中的 PyramidRepo
+type MemoryPyramidRepo struct {
+	mu      sync.Mutex
+	nextID  int
+	records []PyramidAlphas
+}
+
+func (r *MemoryPyramidRepo) Count(ctx context.Context, userID, quarter string) (int64, error) {
+	records, err := r.List(ctx, userID, quarter)
+	return int64(len(records)), err
+}
+
+func (r *MemoryPyramidRepo) Delete(ctx context.Context, userID, quarter string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.delete(userID, quarter), nil
+}
+
+func (r *MemoryPyramidRepo) Insert(ctx context.Context, records []PyramidAlphas) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insert(records)
+	return nil
+}
+
+func (r *MemoryPyramidRepo) Replace(ctx context.Context, userID, quarter string, records []PyramidAlphas) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delete(userID, quarter)
+	r.insert(records)
+	return nil
+}
+
+func (r *MemoryPyramidRepo) List(ctx context.Context, userID, quarter string) ([]PyramidAlphas, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []PyramidAlphas
+	for _, record := range r.records {
+		if (userID == "" || record.UserID == userID) && (quarter == "" || record.QuarterTag == quarter) {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].QuarterTag != records[j].QuarterTag {
+			return records[i].QuarterTag > records[j].QuarterTag
+		}
+		return records[i].AlphaCount > records[j].AlphaCount
+	})
+	return records, nil
+}
+
+// 调用方需持有锁
+func (r *MemoryPyramidRepo) delete(userID, quarter string) int64 {
+	kept := r.records[:0]
+	var deleted int64
+	for _, record := range r.records {
+		if record.UserID == userID && record.QuarterTag == quarter {
+			deleted++
+			continue
+		}
+		kept = append(kept, record)
+	}
+	r.records = kept
+	return deleted
+}
+
+// 调用方需持有锁；与 gorm 一致，回填 ID 和创建时间
+func (r *MemoryPyramidRepo) insert(records []PyramidAlphas) {
+	now := time.Now()
+	for i := range records {
+		r.nextID++
+		records[i].ID = r.nextID
+		records[i].CreateTime, records[i].CreateDate = now, now
+		r.records = append(r.records, records[i])
+	}
+}
+
+// ---- 操作符 ----
+
+// MemoryOperatorRepo 内存中的 OperatorRepo
+type MemoryOperatorRepo struct {
+	mu        sync.Mutex
+	nextID    int
+	operators []Operators
+}
+
+func (r *MemoryOperatorRepo) Count(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.operators)), nil
+}
+
+func (r *MemoryOperatorRepo) Clear(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := int64(len(r.operators))
+	r.operators = nil
+	return deleted, nil
+}
+
+func (r *MemoryOperatorRepo) Insert(ctx context.Context, operators []Operators) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insert(operators)
+	return int64(len(operators)), nil
+}
+
+func (r *MemoryOperatorRepo) List(ctx context.Context) ([]Operators, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.operators), nil
+}
+
+func (r *MemoryOperatorRepo) Replace(ctx context.Context, geniusLevel, geniusQuarter string, operators []Operators) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.operators[:0]
+	for _, op := range r.operators {
+		if op.GeniusLevel != geniusLevel || op.GeniusQuarter != geniusQuarter {
+			kept = append(kept, op)
+		}
+	}
+	r.operators = kept
+	r.insert(operators)
+	return nil
+}
+
+// 调用方需持有锁
+func (r *MemoryOperatorRepo) insert(operators []Operators) {
+	for i := range operators {
+		r.nextID++
+		operators[i].ID = r.nextID
+		r.operators = append(r.operators, operators[i])
+	}
+}
+
+// ---- alpha 快照 ----
+
+// MemorySnapshotRepo 内存中的 SnapshotRepo
+type MemorySnapshotRepo struct {
+	mu        sync.Mutex
+	nextID    int64
+	snapshots []AlphaSnapshot
+}
+
+func (r *MemorySnapshotRepo) Record(ctx context.Context, snapshots []AlphaSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		replaced := false
+		for j, existing := range r.snapshots {
+			if existing.AlphaID == snapshot.AlphaID && existing.SnapshotDate.Equal(snapshot.SnapshotDate) {
+				snapshot.ID = existing.ID
+				r.snapshots[j] = *snapshot
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			r.nextID++
+			snapshot.ID = r.nextID
+			r.snapshots = append(r.snapshots, *snapshot)
+		}
+	}
+	return nil
+}
+
+func (r *MemorySnapshotRepo) History(ctx context.Context, alphaID string) ([]AlphaSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var history []AlphaSnapshot
+	for _, snapshot := range r.snapshots {
+		if snapshot.AlphaID == alphaID {
+			history = append(history, snapshot)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].SnapshotDate.Before(history[j].SnapshotDate)
+	})
+	return history, nil
+}
+
+func (r *MemorySnapshotRepo) AsOf(ctx context.Context, date time.Time) ([]AlphaSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := map[string]AlphaSnapshot{}
+	for _, snapshot := range r.snapshots {
+		if snapshot.SnapshotDate.After(date) {
+			continue
+		}
+		if existing, ok := latest[snapshot.AlphaID]; !ok || snapshot.SnapshotDate.After(existing.SnapshotDate) {
+			latest[snapshot.AlphaID] = snapshot
+		}
+	}
+
+	snapshots := make([]AlphaSnapshot, 0, len(latest))
+	for _, snapshot := range latest {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].AlphaID < snapshots[j].AlphaID })
+	return snapshots, nil
+}
+
+// ---- alpha 字段变化 ----
+
+// MemoryChangeRepo 内存中的 ChangeRepo
+type MemoryChangeRepo struct {
+	mu      sync.Mutex
+	nextID  int64
+	changes []AlphaChange
+}
+
+func (r *MemoryChangeRepo) Record(ctx context.Context, changes []AlphaChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range changes {
+		r.nextID++
+		changes[i].ID = r.nextID
+		r.changes = append(r.changes, changes[i])
+	}
+	return nil
+}
+
+func (r *MemoryChangeRepo) ForAlpha(ctx context.Context, alphaID string) ([]AlphaChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changes []AlphaChange
+	for _, change := range r.changes {
+		if change.AlphaID == alphaID {
+			changes = append(changes, change)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].DetectedAt.Before(changes[j].DetectedAt) })
+	return changes, nil
+}
+
+// ---- 同步水位 ----
+
+// MemorySyncStateRepo 内存中的 SyncStateRepo
+type MemorySyncStateRepo struct {
+	mu         sync.Mutex
+	watermarks map[string]time.Time
+}
+
+func (r *MemorySyncStateRepo) Watermark(ctx context.Context, name string) (time.Time, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	watermark, ok := r.watermarks[name]
+	return watermark, ok, nil
+}
+
+func (r *MemorySyncStateRepo) SetWatermark(ctx context.Context, name string, watermark time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watermarks == nil {
+		r.watermarks = map[string]time.Time{}
+	}
+	r.watermarks[name] = watermark
+	return nil
+}
+
+// ---- alpha 字段索引 ----
+
+// MemoryAlphaFieldRepo 内存中的 AlphaFieldRepo
+type MemoryAlphaFieldRepo struct {
+	mu     sync.Mutex
+	nextID int64
+	rows   []AlphaField
+}
+
+func (r *MemoryAlphaFieldRepo) Replace(ctx context.Context, alphaIDs []string, fields []AlphaField) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rows = slices.DeleteFunc(r.rows, func(row AlphaField) bool { return slices.Contains(alphaIDs, row.AlphaID) })
+	for _, field := range fields {
+		r.nextID++
+		field.ID = r.nextID
+		r.rows = append(r.rows, field)
+	}
+	return nil
+}
+
+func (r *MemoryAlphaFieldRepo) ByFields(ctx context.Context, fields []string) ([]AlphaField, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rows []AlphaField
+	for _, row := range r.rows {
+		if slices.Contains(fields, row.Field) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].AlphaID != rows[j].AlphaID {
+			return rows[i].AlphaID < rows[j].AlphaID
+		}
+		return rows[i].Field < rows[j].Field
+	})
+	return rows, nil
+}
@@ -2,17 +2,17 @@ package small_program
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"regexp"
 	"strings"
 	"time"
 
+	"program-collection/i18n"
 	"program-collection/models"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -20,7 +20,7 @@ import (
 // ---------------------------------- Operator操作符入库结构体 ------------------------------ //
 type Operators struct {
 	ID            int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
-	Name          string `json:"name" gorm:"column:name;type:varchar(255);not null;unique"`
+	Name          string `json:"name" gorm:"column:name;type:varchar(255);not null;index:idx_name"`
 	Category      string `json:"category" gorm:"column:category;type:varchar(100);not null"`
 	Scope         string `json:"scope" gorm:"column:scope;type:json"`
 	Definition    string `json:"definition" gorm:"column:definition;type:text"`
@@ -47,6 +47,8 @@ func ConnectDB(config models.Config) (*gorm.DB, error) {
 
 	// MySQL 连接字符串
 	// 格式: "username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local"
+	// Postgres 格式: "host=xxx user=xxx password=xxx dbname=worldquant port=5432 sslmode=disable TimeZone=Asia/Shanghai"
+	// SQLite 为数据库文件路径，如 data/wqb.db
 	data := config.Database
 
 	// 配置 GORM
@@ -54,41 +56,62 @@ func ConnectDB(config models.Config) (*gorm.DB, error) {
 		Logger: logger.Default.LogMode(logger.Silent), // 设置日志级别 (静默)
 	}
 
-	db, err := gorm.Open(mysql.Open(data.DSN), gormConfig)
+	dialector, err := openDialector(data)
 	if err != nil {
-		return nil, fmt.Errorf("连接数据库失败: %v", err)
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, i18n.Errorf("common.db_connect_failed", err)
 	}
 
 	// 测试数据库连接
 	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("获取数据库实例失败: %v", err)
+		return nil, i18n.Errorf("operators.db_instance_failed", err)
 	}
 
-	// 设置连接池
-	sqlDB.SetMaxIdleConns(data.MaxIdleConns) // 最大空闲连接数
-	sqlDB.SetMaxOpenConns(data.MaxOpenConns) // 最大打开连接数
-	sqlDB.SetConnMaxLifetime(time.Hour)      // 连接最大生命周期
+	// 设置连接池；SQLite 同一时间只允许一个写入，使用单连接避免 database is locked
+	if data.DriverName() == models.DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxIdleConns(data.MaxIdleConns) // 最大空闲连接数
+		sqlDB.SetMaxOpenConns(data.MaxOpenConns) // 最大打开连接数
+	}
+	sqlDB.SetConnMaxLifetime(time.Hour) // 连接最大生命周期
 
-	// 自动迁移表结构（如果表不存在则创建）
-	// err = db.AutoMigrate(&Operators{})
-	// if err != nil {
-	// 	return nil, fmt.Errorf("自动迁移表结构失败: %v", err)
-	// }
+	// 表结构由 migrate 子命令维护（见 migrations 目录）
 
-	fmt.Println("数据库连接成功!")
+	slog.Debug(i18n.T("operators.db_connected"))
 	return db, nil
 }
 
 // 四、将 Operator 转换为 Operators 并保存到数据库
-func SaveOperators(db *gorm.DB, operators []models.Operator, geniusLevel, geniusQuarter string) error {
+func SaveOperators(ctx context.Context, repo OperatorRepo, operators []models.Operator, geniusLevel, geniusQuarter string) error {
+	dbOperators, err := buildOperatorRecords(operators, geniusLevel, geniusQuarter)
+	if err != nil {
+		return err
+	}
+
+	// 批量插入数据，每批100条
+	rows, err := repo.Insert(ctx, dbOperators)
+	if err != nil {
+		return i18n.Errorf("operators.insert_failed", err)
+	}
+
+	programLogger("UpdateOperators").Info(i18n.T("operators.inserted"), "rows", rows, "genius_level", geniusLevel, "genius_quarter", geniusQuarter)
+	return nil
+}
+
+// 将接口返回的操作符转换为数据库模型
+func buildOperatorRecords(operators []models.Operator, geniusLevel, geniusQuarter string) ([]Operators, error) {
 	var dbOperators []Operators
 
 	for _, op := range operators {
 		// 将 Scope 数组转换为 JSON 字符串
 		scopeJSON, err := json.Marshal(op.Scope)
 		if err != nil {
-			return fmt.Errorf("序列化 Scope 失败: %v", err)
+			return nil, i18n.Errorf("operators.scope_marshal_failed", err)
 		}
 
 		// 处理指针类型的字段
@@ -124,96 +147,111 @@ func SaveOperators(db *gorm.DB, operators []models.Operator, geniusLevel, genius
 		dbOperators = append(dbOperators, dbOp)
 	}
 
-	// 批量插入数据，使用 CreateInBatches 分批插入，每批100条
-	result := db.CreateInBatches(dbOperators, 100)
-	if result.Error != nil {
-		return fmt.Errorf("插入数据失败: %v", result.Error)
-	}
-
-	fmt.Printf("成功插入 %d 条操作符记录\n", result.RowsAffected)
-	return nil
+	return dbOperators, nil
 }
 
 // 五、检查数据库中是否已有数据
-func CheckDataExists(db *gorm.DB) (bool, error) {
+func CheckDataExists(ctx context.Context, repo OperatorRepo) (bool, error) {
 
 	var count int64
-	// result := db.Model(&Operators{}).Count(&count)
-	// if result.Error != nil {
-	// 	return false, fmt.Errorf("查询数据失败: %v", result.Error)
+	// count, err := repo.Count(ctx)
+	// if err != nil {
+	// 	return false, fmt.Errorf("查询数据失败: %v", err)
 	// }
 
 	return count > 0, nil
 }
 
 // 六、清空表数据
-func ClearTable(db *gorm.DB) error {
-	// 使用 Exec 执行原生 SQL 清空表
-	result := db.Exec("DELETE FROM operators")
-	if result.Error != nil {
-		return fmt.Errorf("清空表数据失败: %v", result.Error)
+func ClearTable(ctx context.Context, repo OperatorRepo) error {
+	rows, err := repo.Clear(ctx)
+	if err != nil {
+		return i18n.Errorf("operators.clear_failed", err)
 	}
 
-	fmt.Printf("已清空表，删除 %d 条记录\n", result.RowsAffected)
+	programLogger("UpdateOperators").Info(i18n.T("operators.cleared"), "rows", rows)
 	return nil
 }
 
+// ReloadOperators 非交互式地拉取操作符，并替换数据库中同一 Genius 等级和季度的已有记录
+func ReloadOperators(ctx context.Context, deps Deps, geniusLevel, geniusQuarter string) (int, error) {
+	// 等级和季度不区分大小写，按规范写法入库
+	rank := models.GeniusRank(geniusLevel)
+	if rank < 0 {
+		return 0, i18n.Errorf("operators.bad_level", geniusLevel, strings.Join(models.GeniusLevels, ", "))
+	}
+	geniusLevel = models.GeniusLevels[rank]
+	geniusQuarter = strings.ToUpper(strings.TrimSpace(geniusQuarter))
+	if !models.IsGeniusQuarter(geniusQuarter) {
+		return 0, i18n.Errorf("operators.bad_quarter", geniusQuarter)
+	}
+
+	allOperators, err := FetchOperators(deps.Config, deps.Token)
+	if err != nil {
+		return 0, i18n.Errorf("common.fetch_operators_failed", err)
+	}
+
+	dbOperators, err := buildOperatorRecords(allOperators, geniusLevel, geniusQuarter)
+	if err != nil {
+		return 0, err
+	}
+	if err := deps.Operators.Replace(ctx, geniusLevel, geniusQuarter, dbOperators); err != nil {
+		return 0, err
+	}
+
+	programLogger("UpdateOperators").Info(i18n.T("operators.inserted"), "rows", len(dbOperators), "genius_level", geniusLevel, "genius_quarter", geniusQuarter)
+
+	return len(allOperators), nil
+}
+
 // 七、验证和获取Genius等级
 func getGeniusLevel() (string, error) {
 	scanner := bufio.NewScanner(os.Stdin)
-	validLevels := []string{"Gold", "Expert", "Master", "Grand Master"}
 
 	for {
 		// 步骤1: 输入Genius等级
 		fmt.Println("\n" + strings.Repeat("-", 50))
-		fmt.Println("请输入Genius等级 (必填), 可选值: Gold, Expert, Master, Grand Master")
-		fmt.Print("请输入: ")
+		fmt.Println(i18n.T("operators.level_prompt"))
+		fmt.Print(i18n.T("operators.input"))
 
 		if !scanner.Scan() {
-			return "", fmt.Errorf("读取输入失败")
+			return "", i18n.Errorf("common.read_input_failed")
 		}
 
 		geniusLevel := strings.TrimSpace(scanner.Text())
 
 		// 验证是否为空
 		if geniusLevel == "" {
-			fmt.Println("❌ 错误: Genius等级不能为空，请重新输入")
+			fmt.Println(i18n.T("operators.level_empty"))
 			continue
 		}
 
-		// 验证是否有效 - 严格大小写匹配
-		isValid := false
-		for _, validLevel := range validLevels {
-			if geniusLevel == validLevel {  // 严格相等，包括大小写
-				isValid = true
-				break
-			}
-		}
-
-		if !isValid {
-			fmt.Printf("❌ 错误: '%s' 不是有效的Genius等级，请选择: Gold, Expert, Master, Grand Master", geniusLevel)
-			fmt.Println("注意: 必须完全匹配大小写")
+		// 验证是否有效，不区分大小写，确认后使用规范写法
+		rank := models.GeniusRank(geniusLevel)
+		if rank < 0 {
+			i18n.Printf("operators.level_invalid", geniusLevel)
 			continue
 		}
+		geniusLevel = models.GeniusLevels[rank]
 
 		// 步骤2: 确认输入
-		fmt.Printf("\n您输入的Genius等级是: %s\n", geniusLevel)
-		fmt.Print("确认吗? (y/n): ")
+		i18n.Printf("operators.level_entered", geniusLevel)
+		fmt.Print(i18n.T("operators.confirm"))
 
 		if !scanner.Scan() {
-			return "", fmt.Errorf("读取确认输入失败")
+			return "", i18n.Errorf("operators.read_confirm_failed")
 		}
 
 		confirm := strings.TrimSpace(strings.ToLower(scanner.Text()))
 
-		if confirm == "y" || confirm == "yes" || confirm == "是" {
-			fmt.Println("✅ Genius等级设置完成")
+		if i18n.IsYes(confirm) {
+			fmt.Println(i18n.T("operators.level_set"))
 			return geniusLevel, nil
-		} else if confirm == "n" || confirm == "no" || confirm == "否" {
-			fmt.Println("重新输入Genius等级...")
+		} else if i18n.IsNo(confirm) {
+			fmt.Println(i18n.T("operators.level_retry"))
 			continue
 		} else {
-			fmt.Println("❌ 无效的确认输入，请输入 y/n 或 是/否")
+			fmt.Println(i18n.T("operators.confirm_invalid"))
 			continue
 		}
 	}
@@ -222,45 +260,40 @@ func getGeniusLevel() (string, error) {
 // 八、验证和获取Genius季度
 func getGeniusQuarter() (string, error) {
 	scanner := bufio.NewScanner(os.Stdin)
-	quarterRegex := regexp.MustCompile(`^\d{4}-Q[1-4]$`)
 
 	// 获取当前季度作为参考
-	now := time.Now()
-	currentYear := now.Year()
-	currentMonth := int(now.Month())
-	currentQuarter := (currentMonth-1)/3 + 1
-	suggestedQuarter := fmt.Sprintf("%d-Q%d", currentYear, currentQuarter)
+	suggestedQuarter := CurrentQuarter(time.Now())
 
 	for {
 		// 步骤1: 输入Genius季度
 		fmt.Println("\n" + strings.Repeat("-", 50))
-		fmt.Println("请输入Genius季度 (必填):")
-		fmt.Printf("格式: YYYY-Q[1-4] (例如: %s)\n", suggestedQuarter)
-		fmt.Print("请输入: ")
+		fmt.Println(i18n.T("operators.quarter_prompt"))
+		i18n.Printf("operators.quarter_format", suggestedQuarter)
+		fmt.Print(i18n.T("operators.input"))
 
 		if !scanner.Scan() {
-			return "", fmt.Errorf("读取输入失败")
+			return "", i18n.Errorf("common.read_input_failed")
 		}
 
-		geniusQuarter := strings.TrimSpace(scanner.Text())
+		geniusQuarter := strings.ToUpper(strings.TrimSpace(scanner.Text()))
 
 		// 验证是否为空
 		if geniusQuarter == "" {
-			fmt.Println("❌ 错误: Genius季度不能为空，请重新输入")
+			fmt.Println(i18n.T("operators.quarter_empty"))
 			continue
 		}
 
 		// 验证格式
-		if !quarterRegex.MatchString(geniusQuarter) {
-			fmt.Println("❌ 错误: 季度格式不正确")
-			fmt.Println("正确格式: YYYY-Q[1-4] (例如: 2025-Q1, 2025-Q2, 2025-Q3, 2025-Q4)")
+		if !models.IsGeniusQuarter(geniusQuarter) {
+			fmt.Println(i18n.T("operators.quarter_invalid"))
+			fmt.Println(i18n.T("operators.quarter_examples"))
 			continue
 		}
 
 		// 提取年份和季度
 		parts := strings.Split(geniusQuarter, "-Q")
 		if len(parts) != 2 {
-			fmt.Println("❌ 错误: 季度格式解析失败")
+			fmt.Println(i18n.T("operators.quarter_parse_failed"))
 			continue
 		}
 
@@ -269,42 +302,42 @@ func getGeniusQuarter() (string, error) {
 
 		// 验证年份
 		if len(yearStr) != 4 {
-			fmt.Println("❌ 错误: 年份必须是4位数字")
+			fmt.Println(i18n.T("operators.year_digits"))
 			continue
 		}
 
 		// 检查年份是否合理（2000-2100）
 		year := 0
 		if _, err := fmt.Sscanf(yearStr, "%d", &year); err != nil || year < 2000 || year > 2100 {
-			fmt.Println("❌ 错误: 年份必须在 2000 到 2100 之间")
+			fmt.Println(i18n.T("operators.year_range"))
 			continue
 		}
 
 		// 检查季度是否合理
 		quarter := 0
 		if _, err := fmt.Sscanf(quarterStr, "%d", &quarter); err != nil || quarter < 1 || quarter > 4 {
-			fmt.Println("❌ 错误: 季度必须是 1-4 之间的数字")
+			fmt.Println(i18n.T("operators.quarter_range"))
 			continue
 		}
 
 		// 步骤2: 确认输入
-		fmt.Printf("\n您输入的Genius季度是: %s\n", geniusQuarter)
-		fmt.Print("确认吗? (y/n): ")
+		i18n.Printf("operators.quarter_entered", geniusQuarter)
+		fmt.Print(i18n.T("operators.confirm"))
 
 		if !scanner.Scan() {
-			return "", fmt.Errorf("读取确认输入失败")
+			return "", i18n.Errorf("operators.read_confirm_failed")
 		}
 
 		confirm := strings.TrimSpace(strings.ToLower(scanner.Text()))
 
-		if confirm == "y" || confirm == "yes" || confirm == "是" {
-			fmt.Println("✅ Genius季度设置完成")
+		if i18n.IsYes(confirm) {
+			fmt.Println(i18n.T("operators.quarter_set"))
 			return geniusQuarter, nil
-		} else if confirm == "n" || confirm == "no" || confirm == "否" {
-			fmt.Println("重新输入Genius季度...")
+		} else if i18n.IsNo(confirm) {
+			fmt.Println(i18n.T("operators.quarter_retry"))
 			continue
 		} else {
-			fmt.Println("❌ 无效的确认输入，请输入 y/n 或 是/否")
+			fmt.Println(i18n.T("operators.confirm_invalid"))
 			continue
 		}
 	}
@@ -312,108 +345,86 @@ func getGeniusQuarter() (string, error) {
 
 // ------------------------------------------------ 更新或加载新赛季操作符 -----------------------------------------------
 
-func UpdateOperators(config models.Config, token string) {
-	fmt.Println("\n====================== 执行更新操作符 ======================")
-
-	// 1. 连接数据库
-	db, err := ConnectDB(config)
-	if err != nil {
-		log.Fatal("数据库连接失败:", err)
-	}
-	defer func() {
-		sqlDB, _ := db.DB()
-		sqlDB.Close()
-	}()
+func UpdateOperators(deps Deps) error {
+	fmt.Println(i18n.T("operators.banner"))
+	ctx := context.Background()
 
-	// 2. 检查是否已有数据
-	hasData, err := CheckDataExists(db)
+	// 1. 检查是否已有数据
+	hasData, err := CheckDataExists(ctx, deps.Operators)
 	if err != nil {
-		log.Fatal("检查数据失败:", err)
+		return i18n.Errorf("operators.check_failed", err)
 	}
 
 	if hasData {
-		fmt.Print("数据库已有数据，是否清空重新导入？(y/n): ")
+		fmt.Print(i18n.T("operators.reimport_prompt"))
 		var answer string
 		fmt.Scanln(&answer)
 
-		if strings.ToLower(answer) == "y" {
-			err = ClearTable(db)
+		if i18n.IsYes(answer) {
+			err = ClearTable(ctx, deps.Operators)
 			if err != nil {
-				log.Fatal("清空表失败:", err)
+				return i18n.Errorf("operators.clear_table_failed", err)
 			}
 		} else {
-			fmt.Println("已取消操作")
-			return
+			fmt.Println(i18n.T("common.cancelled"))
+			return nil
 		}
 	}
 
-	// 3. 获取操作符列表
-	// fmt.Println("正在获取操作符列表...")
-	allOperators, err := FetchOperators(config, token)
+	// 2. 获取操作符列表
+	allOperators, err := FetchOperators(deps.Config, deps.Token)
 	if err != nil {
-		log.Fatal("获取操作符失败:", err)
+		return i18n.Errorf("common.fetch_operators_failed", err)
 	}
 
-	fmt.Printf("成功获取 %d 个操作符\n", len(allOperators))
-
-	// 4. 打印前3个操作符信息
-	// fmt.Println("\n前3个操作符信息:")
-	// for i, op := range allOperators {
-	// 	if i >= 3 {
-	// 		break
-	// 	}
-	// 	fmt.Printf("%d. %s (%s)\n", i+1, op.Name, op.Category)
-	// }
+	i18n.Printf("operators.fetched", len(allOperators))
 
-	// 5. 获取Genius等级和Genius季度（必填，带验证和确认）
-    geniusLevel, err := getGeniusLevel()
-    if err != nil {
-        log.Fatalf("获取Genius等级失败: %v", err)
-    }
-    geniusQuarter, err := getGeniusQuarter()
-    if err != nil {
-        log.Fatalf("获取Genius季度失败: %v", err)
-    }
-    
-    // 6. 显示最终配置确认
-    fmt.Println("\n" + strings.Repeat("=", 60))
-    fmt.Println("                  最终配置确认")
-    fmt.Println(strings.Repeat("=", 60))
-    fmt.Printf("Genius等级: %s\n", geniusLevel)
-    fmt.Printf("Genius季度: %s\n", geniusQuarter)
-    fmt.Println(strings.Repeat("-", 60))
-    
-    // 7. 最终确认
-    scanner := bufio.NewScanner(os.Stdin)
-    for {
-        fmt.Print("\n确认使用以上配置保存到数据库吗? (y/n): ")
-        
-        if !scanner.Scan() {
-            log.Fatal("读取最终确认失败")
-        }
-        
-        finalConfirm := strings.TrimSpace(strings.ToLower(scanner.Text()))
-        
-        if finalConfirm == "y" || finalConfirm == "yes" || finalConfirm == "是" {
-            break
-        } else if finalConfirm == "n" || finalConfirm == "no" || finalConfirm == "否" {
-            fmt.Println("❌ 操作已取消")
-            return
-        } else {
-            fmt.Println("❌ 无效输入，请输入 y/n 或 是/否")
-        }
-    }
-
-	// 8. 保存到数据库
-	fmt.Println("\n正在保存到数据库...")
-	err = SaveOperators(db, allOperators, geniusLevel, geniusQuarter)
+	// 3. 获取Genius等级和Genius季度（必填，带验证和确认）
+	geniusLevel, err := getGeniusLevel()
 	if err != nil {
-		log.Fatal("保存到数据库失败:", err)
+		return i18n.Errorf("operators.level_failed", err)
+	}
+	geniusQuarter, err := getGeniusQuarter()
+	if err != nil {
+		return i18n.Errorf("operators.quarter_failed", err)
+	}
+
+	// 4. 显示最终配置确认
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println(i18n.T("operators.final_title"))
+	fmt.Println(strings.Repeat("=", 60))
+	i18n.Printf("operators.final_level", geniusLevel)
+	i18n.Printf("operators.final_quarter", geniusQuarter)
+	fmt.Println(strings.Repeat("-", 60))
+
+	// 5. 最终确认
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print(i18n.T("operators.final_prompt"))
+
+		if !scanner.Scan() {
+			return i18n.Errorf("operators.final_read_failed")
+		}
+
+		finalConfirm := strings.TrimSpace(strings.ToLower(scanner.Text()))
+
+		if i18n.IsYes(finalConfirm) {
+			break
+		} else if i18n.IsNo(finalConfirm) {
+			fmt.Println(i18n.T("operators.final_cancelled"))
+			return nil
+		} else {
+			fmt.Println(i18n.T("operators.final_invalid"))
+		}
 	}
 
-	fmt.Println("操作完成!")
+	// 6. 保存到数据库
+	fmt.Println(i18n.T("operators.saving"))
+	err = SaveOperators(ctx, deps.Operators, allOperators, geniusLevel, geniusQuarter)
+	if err != nil {
+		return i18n.Errorf("operators.save_failed", err)
+	}
 
-	fmt.Println("更新操作符功能正在执行...")
-	// TODO: 实现实际的更新操作符逻辑
-	fmt.Println("更新操作符完成！")
+	fmt.Println(i18n.T("operators.done"))
+	return nil
 }
@@ -0,0 +1,38 @@
+package small_program
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReloadOperators(t *testing.T) {
+	_, deps := newFakeBrain(t)
+	ctx := context.Background()
+
+	// 等级和季度不区分大小写，按规范写法入库
+	count, err := ReloadOperators(ctx, deps, "grand_master", "2025-q3")
+	if err != nil {
+		t.Fatalf("ReloadOperators: %v", err)
+	}
+	records, _ := deps.Operators.List(ctx)
+	if count != 3 || len(records) != 3 {
+		t.Fatalf("ReloadOperators = %d, %d records; want 3", count, len(records))
+	}
+	for _, record := range records {
+		if record.GeniusLevel != "Grand Master" || record.GeniusQuarter != "2025-Q3" {
+			t.Errorf("record = %s %q %q", record.Name, record.GeniusLevel, record.GeniusQuarter)
+		}
+	}
+
+	invalid := []struct{ level, quarter string }{
+		{"Platinum", "2025-Q3"},
+		{"", "2025-Q3"},
+		{"Expert", "2025-Q5"},
+		{"Expert", "2025Q3"},
+	}
+	for _, tt := range invalid {
+		if _, err := ReloadOperators(ctx, deps, tt.level, tt.quarter); err == nil {
+			t.Errorf("ReloadOperators(%q, %q) succeeded", tt.level, tt.quarter)
+		}
+	}
+}
@@ -1,13 +1,11 @@
 package small_program
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"time"
 
-	"program-collection/models"
-
-	"gorm.io/gorm/clause"
+	"program-collection/i18n"
 )
 
 type QueryResult struct {
@@ -18,8 +16,8 @@ type QueryResult struct {
 // Consultant 研究顾问的 Weight | Value_factor信息
 type WeightValueFactor struct {
 	ID       int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	UserID   string    `gorm:"column:user_id;size:50;not null;index:idx_user_id" json:"userId"`
-	StatDate time.Time `gorm:"column:stat_date;not null;index:idx_stat_date" json:"statDate"`
+	UserID   string    `gorm:"column:user_id;size:50;not null;index:idx_user_id;uniqueIndex:uk_user_date,priority:1" json:"userId"`
+	StatDate time.Time `gorm:"column:stat_date;type:date;not null;index:idx_stat_date;uniqueIndex:uk_user_date,priority:2" json:"statDate"`
 
 	// 因子相关（当天数据）
 	WeightFactor float64 `gorm:"column:weight_factor;type:decimal(5,2)" json:"weightFactor"`
@@ -56,41 +54,40 @@ func (WeightValueFactor) TableName() string {
 }
 
 // --------------------------------------- 保存研究顾问 wf 和 vf 变化 -----------------------------------------
-func SaveWeightValueFactor(config models.Config, token string) error {
-	fmt.Println("\n====================== 执行Weight和Value_factor更新 ======================")
+func SaveWeightValueFactor(deps Deps) error {
+	fmt.Println(i18n.T("factor.banner"))
+
+	_, err := RecordWeightValueFactor(context.Background(), deps)
+	return err
+}
+
+// RecordWeightValueFactor 拉取研究顾问数据并写入当天的 wf/vf 记录，返回保存的数据
+func RecordWeightValueFactor(ctx context.Context, deps Deps) (*WeightValueFactor, error) {
 
 	// 获取研究顾问Consultant的wf和vf数据
-	resp, err := FetchConsultant(config, token)
+	resp, err := FetchConsultant(deps.Config, deps.Token)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if resp == nil {
-		return fmt.Errorf("consultant response is nil")
+		return nil, i18n.Errorf("factor.nil_response")
 	}
 
-	// 连接数据库
-	db, err := ConnectDB(config)
+	// 统计 alpha 总数，并判断今天是否提交过 SUPER alpha（日期在 Go 中比较，兼容各数据库）
+	var result QueryResult
+	alphaCount, err := deps.Alphas.Count(ctx)
 	if err != nil {
-		log.Fatal("数据库连接失败:", err)
+		return nil, i18n.Errorf("factor.query_failed", err)
 	}
-	defer func() {
-		sqlDB, _ := db.DB()
-		sqlDB.Close()
-	}()
-
-	var result QueryResult
-	err = db.Raw(`
-        SELECT 
-            (SELECT COUNT(*) FROM worldquant.active_alpha_list) as total_count,
-            MAX(date_submitted) as max_date,
-            IF(DATE(MAX(date_submitted)) = CURDATE(), 1, 0) as is_today
-        FROM worldquant.active_alpha_list
-        WHERE type = 'SUPER'
-    `).Scan(&result).Error
+	result.TotalCount = int(alphaCount)
 
+	maxSuperDate, ok, err := deps.Alphas.LatestSubmitted(ctx, "SUPER")
 	if err != nil {
-		log.Fatalf("查询失败: %v", err)
+		return nil, i18n.Errorf("factor.query_failed", err)
+	}
+	if ok && maxSuperDate.In(time.Local).Format("2006-01-02") == time.Now().Format("2006-01-02") {
+		result.IsToday = 1
 	}
 
 	// 获取当前日期
@@ -107,15 +104,14 @@ func SaveWeightValueFactor(config models.Config, token string) error {
 	}
 
 	// 1. 首先检查数据库中是否有该用户的任何数据
-	var totalCount int64
-	err = db.Model(&WeightValueFactor{}).Where("user_id = ?", resp.Leaderboard.User).Count(&totalCount).Error
+	totalCount, err := deps.Factors.Count(ctx, resp.Leaderboard.User)
 	if err != nil {
-		return fmt.Errorf("查询用户数据总数失败: %v", err)
+		return nil, i18n.Errorf("factor.count_failed", err)
 	}
 
 	// 如果是首次加入数据
 	if totalCount == 0 {
-		fmt.Printf("老辈子些，这是你第一次存Weight_factor和Value_factor数据哦。")
+		i18n.Printf("factor.first_record")
 
 		// 构建每日统计数据（首次添加，没有变化量）
 		dailyStat := WeightValueFactor{
@@ -143,14 +139,16 @@ func SaveWeightValueFactor(config models.Config, token string) error {
 		}
 
 		// 保存数据
-		return db.Create(&dailyStat).Error
+		if err := deps.Factors.Create(ctx, &dailyStat); err != nil {
+			return nil, err
+		}
+		return &dailyStat, nil
 	}
 
-	// 2. 获取数据库中该用户的最新数据（按CreateTime排序）
-	var latestStat WeightValueFactor
-	err = db.Where("user_id = ?", resp.Leaderboard.User).Order("create_time DESC").First(&latestStat).Error
+	// 2. 获取数据库中该用户的最新数据（按StatDate、CreateTime排序）
+	latestStat, err := deps.Factors.Latest(ctx, resp.Leaderboard.User)
 	if err != nil {
-		return fmt.Errorf("获取最新数据失败: %v", err)
+		return nil, i18n.Errorf("factor.latest_failed", err)
 	}
 
 	latestCreateTime := latestStat.CreateTime
@@ -166,7 +164,7 @@ func SaveWeightValueFactor(config models.Config, token string) error {
 
 	// 判断今天是否已经记录过
 	if latestDate.Equal(currentDateOnly) {
-		fmt.Printf("老辈子些，今天已经记录过数据了哦(记录时间%s)\n", latestCreateTime.Format("15:04:05"))
+		i18n.Printf("factor.already_recorded", latestCreateTime.Format("15:04:05"))
 		previousWeightFactor = latestStat.WeightFactor
 		previousValueFactor = latestStat.ValueFactor
 		hasPreviousData = true
@@ -197,13 +195,13 @@ func SaveWeightValueFactor(config models.Config, token string) error {
 		}
 
 		// 输出变化信息
-		fmt.Printf("与最近记录(日期: %s, 记录时间: %s)比较:\n",
+		i18n.Printf("factor.compare",
 			latestDate.Format("2006-01-02"),
 			latestCreateTime.Format("15:04:05"))
-		fmt.Printf("权重因子: %.4f -> %.4f (变化: %+.4f, 变化率: %+.2f%%)\n",
+		i18n.Printf("factor.weight_change",
 			previousWeightFactor, resp.Leaderboard.WeightFactor,
 			weightFactorChange, weightFactorChangeRate*100)
-		fmt.Printf("价值因子: %.4f -> %.4f (变化: %+.4f, 变化率: %+.2f%%)\n",
+		i18n.Printf("factor.value_change",
 			previousValueFactor, resp.Leaderboard.ValueFactor,
 			valueFactorChange, valueFactorChangeRate*100)
 
@@ -235,20 +233,10 @@ func SaveWeightValueFactor(config models.Config, token string) error {
 	}
 
 	// 8. 使用 Upsert（如果当天已存在数据则更新）
-	upsertErr := db.Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "user_id"}, {Name: "stat_date"}},
-		DoUpdates: clause.AssignmentColumns([]string{
-			"weight_factor", "value_factor", "weight_factor_change", "value_factor_change",
-			"weight_factor_change_rate", "value_factor_change_rate", "data_fields_used",
-			"submissions_count", "super_alpha_submissions_count", "mean_prod_correlation",
-			"mean_self_correlation", "super_alpha_mean_prod_correlation",
-			"super_alpha_mean_self_correlation", "university", "country", "update_time",
-		}),
-	}).Create(&dailyStat).Error
-
-	if upsertErr == nil {
-		fmt.Println("数据保存成功!")
+	if err := deps.Factors.Upsert(ctx, &dailyStat); err != nil {
+		return nil, err
 	}
 
-	return upsertErr
+	fmt.Println(i18n.T("factor.saved"))
+	return &dailyStat, nil
 }